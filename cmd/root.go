@@ -2,13 +2,27 @@ package cmd
 
 import (
 	"encoding/json"
+	"fmt"
+	"slices"
 
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/daniel1302/vega-assistant/utils"
+)
+
+const (
+	LogFormatConsole = "console"
+	LogFormatJSON    = "json"
+
+	DefaultLogLevel = "info"
 )
 
 type RootArgs struct {
 	Logger *zap.SugaredLogger
+
+	Quiet bool
 }
 
 var Args RootArgs
@@ -17,7 +31,35 @@ var RootCmd = &cobra.Command{
 	Use:   "vega-assistant",
 	Short: "Helps manage vega manual way",
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
-		rawJSON := []byte(`{
+		if err := InitLogger(DefaultLogLevel, LogFormatConsole); err != nil {
+			panic(err)
+		}
+	},
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		if Args.Logger != nil {
+			defer Args.Logger.Sync()
+		}
+	},
+}
+
+func init() {
+	RootCmd.PersistentFlags().BoolVar(
+		&Args.Quiet,
+		"quiet",
+		false,
+		"Do not report the download progress",
+	)
+}
+
+// AvailableLogLevels returns levels accepted by the InitLogger
+func AvailableLogLevels() []string {
+	return []string{"debug", "info", "warn", "error"}
+}
+
+// InitLogger builds the logger shared by all commands. Level is one of the AvailableLogLevels, format is
+// the LogFormatConsole or the LogFormatJSON.
+func InitLogger(level, format string) error {
+	rawJSON := []byte(`{
 		"level": "info",
 		"outputPaths": ["stdout"],
 		"errorOutputPaths": ["stderr"],
@@ -27,17 +69,38 @@ var RootCmd = &cobra.Command{
 			"levelEncoder": "lowercase"
 		}
 	}`)
-		var cfg zap.Config
-		if err := json.Unmarshal(rawJSON, &cfg); err != nil {
-			panic(err)
-		}
-		logger := zap.Must(cfg.Build())
+	var cfg zap.Config
+	if err := json.Unmarshal(rawJSON, &cfg); err != nil {
+		return fmt.Errorf("failed to parse default logger config: %w", err)
+	}
 
-		Args.Logger = logger.Sugar()
-	},
-	PersistentPostRun: func(cmd *cobra.Command, args []string) {
-		if Args.Logger != nil {
-			defer Args.Logger.Sync()
-		}
-	},
+	atomicLevel, err := zap.ParseAtomicLevel(level)
+	if err != nil || !slices.Contains(AvailableLogLevels(), level) {
+		return fmt.Errorf("invalid log level(%s): expected one of %v", level, AvailableLogLevels())
+	}
+	cfg.Level = atomicLevel
+
+	switch format {
+	case LogFormatConsole:
+	case LogFormatJSON:
+		// Orchestrators need the level and the time as separate fields
+		cfg.Encoding = LogFormatJSON
+		cfg.EncoderConfig.LevelKey = "level"
+		cfg.EncoderConfig.TimeKey = "time"
+		cfg.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	default:
+		return fmt.Errorf("invalid log format(%s): expected %s or %s", format, LogFormatConsole, LogFormatJSON)
+	}
+
+	logger, err := cfg.Build()
+	if err != nil {
+		return fmt.Errorf("failed to build logger: %w", err)
+	}
+
+	Args.Logger = logger.Sugar()
+	if !Args.Quiet {
+		utils.SetDownloadProgressLogger(Args.Logger)
+	}
+
+	return nil
 }
@@ -0,0 +1,91 @@
+package setup
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tcnksm/go-input"
+	"go.uber.org/zap"
+
+	service "github.com/daniel1302/vega-assistant/service/datanode"
+	"github.com/daniel1302/vega-assistant/uilib"
+)
+
+type CleanupArgs struct {
+	*SetupArgs
+
+	VisorHome string
+	Yes       bool
+}
+
+var cleanupArgs CleanupArgs
+
+var cleanupCmd = &cobra.Command{
+	Use:   "cleanup",
+	Short: "Remove files and directories created by the failed data-node setup",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withExitCode(cleanup(cleanupArgs.Logger, cleanupArgs))
+	},
+}
+
+func init() {
+	cleanupArgs.SetupArgs = &setupArgs
+
+	cleanupCmd.PersistentFlags().StringVar(
+		&cleanupArgs.VisorHome,
+		"visor-home",
+		"",
+		"The vegavisor home of the failed setup",
+	)
+	cleanupCmd.PersistentFlags().BoolVar(
+		&cleanupArgs.Yes,
+		"yes",
+		false,
+		"Do not ask for confirmation",
+	)
+
+	cleanupCmd.MarkPersistentFlagRequired("visor-home")
+}
+
+func cleanup(logger *zap.SugaredLogger, args CleanupArgs) error {
+	ui := &input.UI{
+		Writer: os.Stdout,
+		Reader: promptInput,
+	}
+
+	progress, err := service.LoadSetupProgress(args.VisorHome)
+	if err != nil {
+		return fmt.Errorf("failed to load setup progress: %w", err)
+	}
+
+	if progress == nil || len(progress.CreatedPaths) < 1 {
+		logger.Infof("No paths created by the assistant found in %s", args.VisorHome)
+
+		return nil
+	}
+
+	if !args.Yes {
+		answer, err := uilib.AskYesNo(
+			ui,
+			fmt.Sprintf("Do you want to remove the following paths created by the assistant: %v?", progress.CreatedPaths),
+			uilib.AnswerNo,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to ask for confirmation: %w", err)
+		}
+
+		if answer == uilib.AnswerNo {
+			logger.Info("Nothing removed")
+
+			return nil
+		}
+	}
+
+	if err := progress.Cleanup(logger); err != nil {
+		return fmt.Errorf("failed to cleanup: %w", err)
+	}
+	logger.Info("Cleanup finished")
+
+	return nil
+}
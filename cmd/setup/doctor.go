@@ -0,0 +1,118 @@
+package setup
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/daniel1302/vega-assistant/network"
+	service "github.com/daniel1302/vega-assistant/service/datanode"
+)
+
+type DoctorArgs struct {
+	*SetupArgs
+
+	VisorHome         string
+	VegaHome          string
+	TendermintHome    string
+	DataNodeHome      string
+	NoDataNode        bool
+	ChainID           string
+	Network           string
+	NetworkConfigFile string
+}
+
+var doctorArgs DoctorArgs
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose the existing node installation",
+	// Failed check is not an usage error
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return doctor(doctorArgs)
+	},
+}
+
+func init() {
+	doctorArgs.SetupArgs = &setupArgs
+
+	doctorCmd.PersistentFlags().StringVar(&doctorArgs.VisorHome, "visor-home", "", "The vegavisor home")
+	doctorCmd.PersistentFlags().StringVar(&doctorArgs.VegaHome, "vega-home", "", "The vega home")
+	doctorCmd.PersistentFlags().StringVar(&doctorArgs.TendermintHome, "tendermint-home", "", "The tendermint home")
+	doctorCmd.PersistentFlags().StringVar(
+		&doctorArgs.DataNodeHome,
+		"data-node-home",
+		"",
+		"The data-node home. Defaults to the vega home",
+	)
+	doctorCmd.PersistentFlags().BoolVar(
+		&doctorArgs.NoDataNode,
+		"no-data-node",
+		false,
+		"Skip the data-node and the database checks, e.g. for the validator node",
+	)
+	doctorCmd.PersistentFlags().StringVar(
+		&doctorArgs.ChainID,
+		"chain-id",
+		"",
+		"The expected chain id. Defaults to the chain id from the data-node config or the network config",
+	)
+	doctorCmd.PersistentFlags().StringVar(
+		&doctorArgs.Network,
+		"network",
+		string(network.Mainnet),
+		fmt.Sprintf("The network the node is set up for. Available networks: %v", network.AvailableNetworks()),
+	)
+	doctorCmd.PersistentFlags().StringVar(
+		&doctorArgs.NetworkConfigFile,
+		"network-config",
+		"",
+		"TOML or JSON file with custom network config. Takes precedence over the --network flag",
+	)
+
+	doctorCmd.MarkPersistentFlagRequired("visor-home")
+	doctorCmd.MarkPersistentFlagRequired("vega-home")
+	doctorCmd.MarkPersistentFlagRequired("tendermint-home")
+}
+
+func doctor(args DoctorArgs) error {
+	var (
+		networkConfig network.NetworkConfig
+		err           error
+	)
+	if args.NetworkConfigFile != "" {
+		networkConfig, err = network.LoadNetworkConfig(args.NetworkConfigFile)
+	} else {
+		networkConfig, err = network.ConfigForNetwork(network.Name(args.Network))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get network config: %w", err)
+	}
+
+	settings := service.DoctorSettings{
+		VisorHome:      args.VisorHome,
+		VegaHome:       args.VegaHome,
+		TendermintHome: args.TendermintHome,
+		DataNodeHome:   args.DataNodeHome,
+		ChainID:        args.ChainID,
+		NetworkChainID: networkConfig.ChainID,
+	}
+	if settings.DataNodeHome == "" {
+		settings.DataNodeHome = args.VegaHome
+	}
+	if args.NoDataNode {
+		settings.DataNodeHome = ""
+	}
+	for _, rpcServer := range networkConfig.TendermintRPCServers {
+		settings.RPCServers = append(settings.RPCServers, rpcServer.Endpoint)
+	}
+
+	if !service.PrintDoctorReport(service.RunDoctor(settings)) {
+		return fmt.Errorf("installation has failed checks")
+	}
+
+	fmt.Println("Installation looks healthy")
+
+	return nil
+}
@@ -0,0 +1,126 @@
+package setup
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/tcnksm/go-input"
+
+	service "github.com/daniel1302/vega-assistant/service/datanode"
+	"github.com/daniel1302/vega-assistant/types"
+)
+
+// Exit codes returned for the failed setup, so scripts can react to the failed stage
+const (
+	ExitCodeFailure   = 1
+	ExitCodeInput     = 2
+	ExitCodeDownload  = 3
+	ExitCodeDB        = 4
+	ExitCodeConfig    = 5
+	ExitCodeInit      = 6
+	ExitCodeGenesis   = 7
+	ExitCodeHook      = 8
+	ExitCodeTimeout   = 124
+	ExitCodeCancelled = 130
+)
+
+// ExitError carries the process exit code for the failed command
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+var errPromptClosed = errors.New("input closed")
+
+// errInputRequired explains the prompt that stopped on the closed input, e.g. in CI without the terminal
+var errInputRequired = errors.New(
+	"input required: the standard input is closed before all questions were answered: run the command in the terminal, or provide the answers with the flags, e.g. the --non-interactive mode of the data-node setup",
+)
+
+// promptReader stops the prompt on the closed input. The go-input reads the EOF as the empty answer,
+// so the required question is asked in the endless loop.
+type promptReader struct {
+	file   *os.File
+	closed bool
+}
+
+func (r *promptReader) Read(p []byte) (int, error) {
+	n, err := r.file.Read(p)
+	if errors.Is(err, io.EOF) {
+		r.closed = true
+
+		return n, errPromptClosed
+	}
+
+	return n, err
+}
+
+// File returns the terminal for the masked questions, see the uilib.AskPassword
+func (r *promptReader) File() *os.File {
+	return r.file
+}
+
+var promptInput = &promptReader{file: os.Stdin}
+
+// withExitCode maps the setup error to the exit code
+func withExitCode(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return newExitError(err)
+}
+
+func newExitError(err error) *ExitError {
+	// The go-input error chain does not tell the question was not answered
+	if promptInput.closed {
+		return &ExitError{Code: ExitCodeInput, Err: fmt.Errorf("%w: %w", errInputRequired, err)}
+	}
+
+	return &ExitError{Code: exitCode(err), Err: err}
+}
+
+func exitCode(err error) int {
+	var (
+		downloadErr *types.DownloadError
+		dbErr       *types.DBError
+		configErr   *types.ConfigError
+		initErr     *types.InitError
+		genesisErr  *types.GenesisError
+		hookErr     *types.HookError
+	)
+
+	switch {
+	case errors.Is(err, service.ErrSetupTimeout):
+		return ExitCodeTimeout
+	// Cancelled prompt is not a crash
+	case errors.Is(err, service.ErrCancelled), errors.Is(err, input.ErrInterrupted):
+		return ExitCodeCancelled
+	case errors.Is(err, types.InputError), errors.Is(err, errPromptClosed):
+		return ExitCodeInput
+	case errors.As(err, &downloadErr):
+		return ExitCodeDownload
+	case errors.As(err, &dbErr):
+		return ExitCodeDB
+	case errors.As(err, &configErr):
+		return ExitCodeConfig
+	case errors.As(err, &initErr):
+		return ExitCodeInit
+	case errors.As(err, &genesisErr):
+		return ExitCodeGenesis
+	case errors.As(err, &hookErr):
+		return ExitCodeHook
+	default:
+		return ExitCodeFailure
+	}
+}
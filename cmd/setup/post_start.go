@@ -22,8 +22,9 @@ var postStartCmd = &cobra.Command{
 	Short: "Put configuration adjustments required after node has been started",
 	Run: func(cmd *cobra.Command, args []string) {
 		if err := setupPostStart(postStartArgs.Logger); err != nil {
-			fmt.Println(err.Error())
-			os.Exit(1)
+			exitErr := newExitError(err)
+			fmt.Println(exitErr.Error())
+			os.Exit(exitErr.Code)
 		}
 	},
 }
@@ -35,7 +36,7 @@ func init() {
 func setupPostStart(logger *zap.SugaredLogger) error {
 	ui := &input.UI{
 		Writer: os.Stdout,
-		Reader: os.Stdin,
+		Reader: promptInput,
 	}
 	state := service.NewStateMachine()
 	err := state.Run(ui)
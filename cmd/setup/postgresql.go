@@ -21,7 +21,7 @@ var postgresqlDockerComposeCmd = &cobra.Command{
 	Use:   "postgresql",
 	Short: "Prepares docker-compose.yaml file to start the postgresql server with TimescaleDB extension enabled",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return setupPostgresqlDockerCompose(postgresqlDockerComposeArgs.Logger)
+		return withExitCode(setupPostgresqlDockerCompose(postgresqlDockerComposeArgs.Logger, postgresqlDockerComposeArgs.Force))
 	},
 }
 
@@ -29,12 +29,12 @@ func init() {
 	postgresqlDockerComposeArgs.SetupArgs = &setupArgs
 }
 
-func setupPostgresqlDockerCompose(logger *zap.SugaredLogger) error {
+func setupPostgresqlDockerCompose(logger *zap.SugaredLogger, force bool) error {
 	ui := &input.UI{
 		Writer: os.Stdout,
-		Reader: os.Stdin,
+		Reader: promptInput,
 	}
-	state := service.NewStateMachine()
+	state := service.NewStateMachine(logger, force)
 	err := state.Run(ui)
 	if err != nil {
 		return fmt.Errorf("failed to run state machine: %w", err)
@@ -0,0 +1,128 @@
+package setup
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/daniel1302/vega-assistant/network"
+	service "github.com/daniel1302/vega-assistant/service/datanode"
+	"github.com/daniel1302/vega-assistant/vegaapi"
+)
+
+type RefreshConfigArgs struct {
+	*SetupArgs
+
+	VisorHome            string
+	VegaHome             string
+	TendermintHome       string
+	DataNodeHome         string
+	Network              string
+	NetworkConfigFile    string
+	ExtraPersistentPeers []string
+	DryRun               bool
+}
+
+var refreshConfigArgs RefreshConfigArgs
+
+var refreshConfigCmd = &cobra.Command{
+	Use:   "refresh-config",
+	Short: "Re-apply the recommended config values to the existing data-node installation",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withExitCode(refreshConfig(refreshConfigArgs))
+	},
+}
+
+func init() {
+	refreshConfigArgs.SetupArgs = &setupArgs
+
+	refreshConfigCmd.PersistentFlags().StringVar(&refreshConfigArgs.VisorHome, "visor-home", "", "The vegavisor home")
+	refreshConfigCmd.PersistentFlags().StringVar(&refreshConfigArgs.VegaHome, "vega-home", "", "The vega home")
+	refreshConfigCmd.PersistentFlags().StringVar(&refreshConfigArgs.TendermintHome, "tendermint-home", "", "The tendermint home")
+	refreshConfigCmd.PersistentFlags().StringVar(
+		&refreshConfigArgs.DataNodeHome,
+		"data-node-home",
+		"",
+		"The data-node home. Defaults to the vega home",
+	)
+	refreshConfigCmd.PersistentFlags().StringVar(
+		&refreshConfigArgs.Network,
+		"network",
+		string(network.Mainnet),
+		fmt.Sprintf("The network the node is set up for. Available networks: %v", network.AvailableNetworks()),
+	)
+	refreshConfigCmd.PersistentFlags().StringVar(
+		&refreshConfigArgs.NetworkConfigFile,
+		"network-config",
+		"",
+		"TOML or JSON file with custom network config. Takes precedence over the --network flag",
+	)
+	refreshConfigCmd.PersistentFlags().StringSliceVar(
+		&refreshConfigArgs.ExtraPersistentPeers,
+		"extra-persistent-peers",
+		nil,
+		"Comma separated list of extra tendermint persistent peers(<node-id>@<host>:<port>) added to the network defaults",
+	)
+	refreshConfigCmd.PersistentFlags().BoolVar(
+		&refreshConfigArgs.DryRun,
+		"dry-run",
+		false,
+		"Print the refreshed config values without writing any files",
+	)
+
+	refreshConfigCmd.MarkPersistentFlagRequired("visor-home")
+	refreshConfigCmd.MarkPersistentFlagRequired("vega-home")
+	refreshConfigCmd.MarkPersistentFlagRequired("tendermint-home")
+}
+
+func refreshConfig(args RefreshConfigArgs) error {
+	logger := args.Logger
+
+	networkConfig, err := selectNetworkConfig(logger, args.Network, args.NetworkConfigFile, false)
+	if err != nil {
+		return fmt.Errorf("failed to get network config: %w", err)
+	}
+
+	apiClient, err := vegaapi.NewNetworkAPI(networkConfig.DataNodesRESTUrls, true, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create vega network api client: %w", err)
+	}
+
+	settings := service.DefaultGenerateSettings()
+	settings.NonInteractive = true
+	settings.VisorHome = args.VisorHome
+	settings.VegaHome = args.VegaHome
+	settings.TendermintHome = args.TendermintHome
+	settings.DataNodeHome = args.DataNodeHome
+	if settings.DataNodeHome == "" {
+		settings.DataNodeHome = args.VegaHome
+	}
+	settings.ExtraPersistentPeers = args.ExtraPersistentPeers
+	settings.DryRun = args.DryRun
+
+	svc, err := service.NewConfigRefresher(apiClient, *settings, networkConfig)
+	if err != nil {
+		return fmt.Errorf("failed to start config refresher: %w", err)
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	if err := svc.Run(ctx, logger); err != nil {
+		if restoreErr := svc.RestoreConfigBackups(logger); restoreErr != nil {
+			logger.Errorf("Failed to restore config backups: %s", restoreErr.Error())
+		}
+
+		return fmt.Errorf("failed to refresh config: %w", err)
+	}
+
+	if settings.DryRun {
+		logger.Info("Dry run completed. No changes have been applied")
+
+		return nil
+	}
+
+	logger.Info("Config refreshed. Restart the node to apply the changes")
+
+	return nil
+}
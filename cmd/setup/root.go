@@ -1,13 +1,23 @@
 package setup
 
 import (
+	"fmt"
+
 	"github.com/spf13/cobra"
 
 	"github.com/daniel1302/vega-assistant/cmd"
+	"github.com/daniel1302/vega-assistant/utils"
 )
 
 type SetupArgs struct {
 	*cmd.RootArgs
+
+	GithubToken     string
+	Proxy           string
+	MaxDownloadRate string
+	LogLevel        string
+	LogFormat       string
+	Force           bool
 }
 
 var setupArgs SetupArgs
@@ -16,13 +26,72 @@ var setupArgs SetupArgs
 var RootCmd = &cobra.Command{
 	Use:   "setup",
 	Short: "Setup a node commands",
+	PersistentPreRunE: func(_ *cobra.Command, _ []string) error {
+		if err := cmd.InitLogger(setupArgs.LogLevel, setupArgs.LogFormat); err != nil {
+			return err
+		}
+
+		if setupArgs.MaxDownloadRate != "" {
+			maxDownloadRate, err := utils.ParseSize(setupArgs.MaxDownloadRate)
+			if err != nil {
+				return fmt.Errorf("invalid max download rate: %w", err)
+			}
+			utils.SetMaxDownloadRate(maxDownloadRate)
+		}
+
+		return utils.SetProxy(setupArgs.Proxy)
+	},
 }
 
 func init() {
 	setupArgs.RootArgs = &cmd.Args
 
+	RootCmd.PersistentFlags().StringVar(
+		&setupArgs.GithubToken,
+		"github-token",
+		"",
+		"GitHub token used to authenticate GitHub requests. Defaults to the GITHUB_TOKEN environment variable",
+	)
+	RootCmd.PersistentFlags().StringVar(
+		&setupArgs.Proxy,
+		"proxy",
+		"",
+		"Proxy url(<http|https|socks5>://<host>:<port>) used for the genesis and the GitHub downloads. Defaults to the HTTP_PROXY and HTTPS_PROXY environment variables",
+	)
+	RootCmd.PersistentFlags().StringVar(
+		&setupArgs.MaxDownloadRate,
+		"max-download-rate",
+		"",
+		"Maximum download rate per second for the genesis and the binaries, e.g. 10MB. Defaults to no limit",
+	)
+	RootCmd.PersistentFlags().BoolVar(
+		&setupArgs.Force,
+		"force",
+		false,
+		"Use existing directories for the homes after removing everything inside them, instead of rejecting the directories. Asks for the confirmation unless --yes is set",
+	)
+	RootCmd.PersistentFlags().StringVar(
+		&setupArgs.LogLevel,
+		"log-level",
+		cmd.DefaultLogLevel,
+		fmt.Sprintf("Log level. Available levels: %v", cmd.AvailableLogLevels()),
+	)
+	RootCmd.PersistentFlags().StringVar(
+		&setupArgs.LogFormat,
+		"log-format",
+		cmd.LogFormatConsole,
+		fmt.Sprintf("Log format. Available formats: %s, %s", cmd.LogFormatConsole, cmd.LogFormatJSON),
+	)
+
 	RootCmd.AddCommand(dataNodeCmd)
 	RootCmd.AddCommand(postgresqlDockerComposeCmd)
 	RootCmd.AddCommand(systemdCmd)
 	RootCmd.AddCommand(postStartCmd)
+	RootCmd.AddCommand(checkDBCmd)
+	RootCmd.AddCommand(cleanupCmd)
+	RootCmd.AddCommand(visorConfigCmd)
+	RootCmd.AddCommand(versionsCmd)
+	RootCmd.AddCommand(validatorCmd)
+	RootCmd.AddCommand(doctorCmd)
+	RootCmd.AddCommand(refreshConfigCmd)
 }
@@ -0,0 +1,269 @@
+package setup
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+
+	service "github.com/daniel1302/vega-assistant/service/datanode"
+	"github.com/daniel1302/vega-assistant/vega"
+	"github.com/daniel1302/vega-assistant/vegacmd"
+)
+
+type settingsSetter func(args SetupDataNodeArgs, config *service.GenerateSettings)
+
+// settingsFlags maps the data-node flags to the settings they override. New flags overriding the settings
+// need only the entry here.
+var settingsFlags = []struct {
+	flag string
+	set  settingsSetter
+}{
+	{flag: "extra-persistent-peers", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.ExtraPersistentPeers = append(config.ExtraPersistentPeers, args.ExtraPersistentPeers...)
+	}},
+	{flag: "skip-checksum", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.SkipChecksum = args.SkipChecksum
+	}},
+	{flag: "no-cache", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.NoCache = args.NoCache
+	}},
+	{flag: "version", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.Version = args.Version
+	}},
+	{flag: "vega-version-constraint", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.VersionConstraint = args.VersionConstraint
+	}},
+	{flag: "allow-version-mismatch", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.AllowVersionMismatch = args.AllowVersionMismatch
+	}},
+	{flag: "vega-binary", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.VegaBinaryPath = args.VegaBinary
+	}},
+	{flag: "visor-binary", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.VisorBinaryPath = args.VisorBinary
+	}},
+	{flag: "genesis-file", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.GenesisFile = args.GenesisFile
+	}},
+	{flag: "genesis-sha256", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.GenesisSHA256 = args.GenesisSHA256
+	}},
+	{flag: "chain-id", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.ChainID = args.ChainID
+	}},
+	{flag: "skip-genesis-checksum", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.SkipGenesisChecksum = args.SkipGenesisChecksum
+	}},
+	{flag: "dry-run", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.DryRun = args.DryRun
+	}},
+	{flag: "resume", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.Resume = args.Resume
+	}},
+	{flag: "reuse-home", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.ReuseHome = args.ReuseHome
+	}},
+	{flag: "force", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.Force = args.Force
+	}},
+	{flag: "yes", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.AssumeYes = args.Yes
+	}},
+	{flag: "keep-downloads", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.KeepDownloads = args.KeepDownloads
+	}},
+	{flag: "download-dir", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.DownloadDir = args.DownloadDir
+	}},
+	{flag: "debug", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.Debug = args.Debug
+	}},
+	{flag: "network-history-timeout", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.NetworkHistoryTimeout = args.NetworkHistoryTimeout
+	}},
+	{flag: "network-history-from-height", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.NetworkHistoryFromHeight = args.NetworkHistoryFromHeight
+	}},
+	{flag: "broker-dial-timeout", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.BrokerDialTimeout = args.BrokerDialTimeout
+	}},
+	{flag: "visor-first-connection-retries", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.VisorFirstConnectionRetries = args.VisorFirstConnectionRetries
+	}},
+	{flag: "no-auto-upgrade", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.NoAutoUpgrade = args.NoAutoUpgrade
+	}},
+	{flag: "trust-period", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.TrustPeriod = args.TrustPeriod
+	}},
+	{flag: "derive-trust-period", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.DeriveTrustPeriod = args.DeriveTrustPeriod
+	}},
+	{flag: "trust-height", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.TrustHeight = args.TrustHeight
+	}},
+	{flag: "trust-hash", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.TrustHash = args.TrustHash
+	}},
+	{flag: "skip-rpc-check", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.SkipRPCCheck = args.SkipRPCCheck
+	}},
+	{flag: "target-os", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.TargetOS = args.TargetOS
+	}},
+	{flag: "target-arch", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.TargetArch = args.TargetArch
+	}},
+	{flag: "node-type", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.NodeType = vegacmd.VegaNodeMode(args.NodeType)
+	}},
+	{flag: "pruning", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.Pruning = service.PruningMode(args.Pruning)
+	}},
+	{flag: "pruning-keep-recent", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.PruningKeepRecent = args.PruningKeepRecent
+	}},
+	{flag: "pruning-interval", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.PruningInterval = args.PruningInterval
+	}},
+	{flag: "snapshot-interval", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.SnapshotInterval = args.SnapshotInterval
+	}},
+	{flag: "snapshot-keep-recent", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.SnapshotKeepRecent = args.SnapshotKeepRecent
+	}},
+	{flag: "wipe-on-startup", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.WipeOnStartup = args.WipeOnStartup
+	}},
+	{flag: "confirm-wipe", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.ConfirmWipe = args.ConfirmWipe
+	}},
+	{flag: "min-free-space", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.MinFreeSpace = args.MinFreeSpace
+	}},
+	{flag: "strict", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.StrictFreeSpace = args.StrictFreeSpace
+	}},
+	{flag: "skip-port-check", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.SkipPortCheck = args.SkipPortCheck
+	}},
+	{flag: "tm-p2p-port", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.TendermintP2PPort = args.TendermintP2PPort
+	}},
+	{flag: "tm-rpc-port", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.TendermintRPCPort = args.TendermintRPCPort
+	}},
+	{flag: "data-node-grpc-port", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.DataNodeGRPCPort = args.DataNodeGRPCPort
+	}},
+	{flag: "data-node-rest-port", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.DataNodeRESTPort = args.DataNodeRESTPort
+	}},
+	{flag: "post-setup-hook", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.PostSetupHook = args.PostSetupHook
+	}},
+	{flag: "ignore-hook-errors", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.IgnoreHookErrors = args.IgnoreHookErrors
+	}},
+	{flag: "config-overrides", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.ConfigOverridesFile = args.ConfigOverrides
+	}},
+	{flag: "owner", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.Owner = args.Owner
+	}},
+	{flag: "mode", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.Mode = service.StartupMode(args.Mode)
+	}},
+	{flag: "data-retention", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.DataRetention = dataRetentionPolicy(args.DataRetention)
+	}},
+	{flag: "visor-home", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.VisorHome = args.VisorHome
+	}},
+	{flag: "vega-home", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.VegaHome = args.VegaHome
+		config.DataNodeHome = args.VegaHome
+	}},
+	{flag: "tendermint-home", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.TendermintHome = args.TendermintHome
+	}},
+	{flag: "home-base", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.HomeBase = args.HomeBase
+	}},
+	{flag: "remove-existing-files", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.RemoveExistingFiles = args.RemoveExistingFiles
+	}},
+	{flag: "sql-host", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.SQLCredentials.Host = args.SQLHost
+	}},
+	{flag: "sql-port", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.SQLCredentials.Port = args.SQLPort
+	}},
+	{flag: "sql-user", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.SQLCredentials.User = args.SQLUser
+	}},
+	{flag: "sql-password", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.SQLCredentials.Pass = args.SQLPassword
+	}},
+	{flag: "sql-db-name", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.SQLCredentials.DatabaseName = args.SQLDatabaseName
+	}},
+	{flag: "sql-ssl-mode", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.SQLCredentials.SSLMode = args.SQLSSLMode
+	}},
+	{flag: "sql-ssl-root-cert", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.SQLCredentials.SSLRootCert = args.SQLSSLRootCert
+	}},
+	{flag: "db-url", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.DatabaseURL = args.DatabaseURL
+	}},
+	{flag: "db-password-env", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.DBPasswordEnv = args.DBPasswordEnv
+	}},
+	{flag: "create-db", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.CreateDatabase = args.CreateDatabase
+	}},
+	{flag: "sql-admin-user", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.SQLAdminUser = args.SQLAdminUser
+	}},
+	{flag: "sql-admin-password", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.SQLAdminPassword = args.SQLAdminPassword
+	}},
+	{flag: "install-timescaledb", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.InstallTimescaleDB = args.InstallTimescaleDB
+	}},
+	{flag: "timescaledb-version", set: func(args SetupDataNodeArgs, config *service.GenerateSettings) {
+		config.TimescaleDBVersion = args.TimescaleDBVersion
+	}},
+}
+
+// applySettingsFlags overrides the settings with the flags set explicitly. Settings of the flags left unset
+// come from the settings file or the last setup.
+func applySettingsFlags(args SetupDataNodeArgs, flags *pflag.FlagSet, config *service.GenerateSettings) error {
+	if flags.Changed("snapshot-interval") && args.SnapshotInterval < 1 {
+		return fmt.Errorf("--snapshot-interval(%d) must be a positive number of blocks", args.SnapshotInterval)
+	}
+	if flags.Changed("snapshot-keep-recent") && args.SnapshotKeepRecent < 1 {
+		return fmt.Errorf("--snapshot-keep-recent(%d) must be a positive integer", args.SnapshotKeepRecent)
+	}
+	if flags.Changed("data-retention") && !vega.IsRetentionPolicyValid(dataRetentionPolicy(args.DataRetention)) {
+		return fmt.Errorf("invalid data retention policy: %s", args.DataRetention)
+	}
+
+	for _, settingsFlag := range settingsFlags {
+		if flags.Changed(settingsFlag.flag) {
+			settingsFlag.set(args, config)
+		}
+	}
+
+	return nil
+}
+
+// dataRetentionPolicy returns the retention policy name expected by the data-node
+func dataRetentionPolicy(value string) string {
+	if value == "lite" {
+		return "1 day"
+	}
+
+	return value
+}
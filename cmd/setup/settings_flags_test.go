@@ -0,0 +1,79 @@
+package setup
+
+import (
+	"testing"
+
+	"github.com/spf13/pflag"
+
+	service "github.com/daniel1302/vega-assistant/service/datanode"
+	"github.com/daniel1302/vega-assistant/types"
+)
+
+func TestSettingsFlagsAreDefined(t *testing.T) {
+	for _, settingsFlag := range settingsFlags {
+		if dataNodeCmd.PersistentFlags().Lookup(settingsFlag.flag) == nil && RootCmd.PersistentFlags().Lookup(settingsFlag.flag) == nil {
+			t.Errorf("flag --%s is not defined for the data-node command", settingsFlag.flag)
+		}
+	}
+}
+
+func TestApplySettingsFlags(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected service.GenerateSettings
+		wantErr  bool
+	}{
+		{
+			name:     "unset flags keep the settings",
+			expected: service.GenerateSettings{Version: "v0.73.4", SkipChecksum: true, DataRetention: "forever", SQLCredentials: types.SQLCredentials{Port: 5433}},
+		},
+		{
+			name:     "set flags override the settings",
+			args:     []string{"--version=v0.74.0", "--skip-checksum=false", "--data-retention=lite", "--sql-port=5432"},
+			expected: service.GenerateSettings{Version: "v0.74.0", DataRetention: "1 day", SQLCredentials: types.SQLCredentials{Port: 5432}},
+		},
+		{name: "invalid data retention", args: []string{"--data-retention=weekly"}, wantErr: true},
+		{name: "zero snapshot interval", args: []string{"--snapshot-interval=0"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := SetupDataNodeArgs{}
+			flags := pflag.NewFlagSet("data-node", pflag.ContinueOnError)
+			flags.StringVar(&args.Version, "version", "", "")
+			flags.BoolVar(&args.SkipChecksum, "skip-checksum", false, "")
+			flags.StringVar(&args.DataRetention, "data-retention", "", "")
+			flags.IntVar(&args.SQLPort, "sql-port", 5432, "")
+			flags.IntVar(&args.SnapshotInterval, "snapshot-interval", 0, "")
+			if err := flags.Parse(tt.args); err != nil {
+				t.Fatal(err)
+			}
+
+			config := &service.GenerateSettings{
+				Version:        "v0.73.4",
+				SkipChecksum:   true,
+				DataRetention:  "forever",
+				SQLCredentials: types.SQLCredentials{Port: 5433},
+			}
+			err := applySettingsFlags(args, flags, config)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if config.Version != tt.expected.Version ||
+				config.SkipChecksum != tt.expected.SkipChecksum ||
+				config.DataRetention != tt.expected.DataRetention ||
+				config.SQLCredentials.Port != tt.expected.SQLCredentials.Port {
+				t.Errorf("expected settings %+v, got %+v", tt.expected, *config)
+			}
+		})
+	}
+}
@@ -0,0 +1,386 @@
+package setup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"github.com/daniel1302/vega-assistant/github"
+	"github.com/daniel1302/vega-assistant/network"
+	service "github.com/daniel1302/vega-assistant/service/datanode"
+	"github.com/daniel1302/vega-assistant/types"
+	"github.com/daniel1302/vega-assistant/vegaapi"
+)
+
+type SetupValidatorArgs struct {
+	*SetupArgs
+	service.ValidatorSettings
+
+	Network              string
+	NetworkConfigFile    string
+	DiscoverPeers        bool
+	VisorHome            string
+	VegaHome             string
+	TendermintHome       string
+	ExtraPersistentPeers []string
+	GenesisFile          string
+	GenesisSHA256        string
+	ChainID              string
+	SkipGenesisChecksum  bool
+	VegaBinary           string
+	VisorBinary          string
+	SkipChecksum         bool
+	NoCache              bool
+	DryRun               bool
+	Pruning              string
+	PruningKeepRecent    int
+	PruningInterval      time.Duration
+	SnapshotInterval     int
+	SnapshotKeepRecent   int
+	NoAutoUpgrade        bool
+	ConfigOverrides      string
+	Owner                string
+	Timeout              time.Duration
+	SkipPortCheck        bool
+	TendermintP2PPort    int
+	TendermintRPCPort    int
+	PostSetupHook        string
+	IgnoreHookErrors     bool
+}
+
+var setupValidatorArgs SetupValidatorArgs
+
+var validatorCmd = &cobra.Command{
+	Use:   "validator",
+	Short: "Prepare validator node on your computer",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withExitCode(validatorSetup(setupValidatorArgs.Logger, setupValidatorArgs))
+	},
+}
+
+func init() {
+	setupValidatorArgs.SetupArgs = &setupArgs
+	defaultSettings := service.DefaultGenerateSettings()
+
+	validatorCmd.PersistentFlags().StringVar(
+		&setupValidatorArgs.Network,
+		"network",
+		string(network.Mainnet),
+		fmt.Sprintf("The network to setup validator for. Available networks: %v", network.AvailableNetworks()),
+	)
+	validatorCmd.PersistentFlags().StringVar(
+		&setupValidatorArgs.NetworkConfigFile,
+		"network-config",
+		"",
+		"TOML or JSON file with custom network config. Takes precedence over the --network flag",
+	)
+	validatorCmd.PersistentFlags().BoolVar(
+		&setupValidatorArgs.DiscoverPeers,
+		"discover-peers",
+		false,
+		"Discover tendermint seeds and rpc servers from the running data-nodes",
+	)
+	validatorCmd.PersistentFlags().StringVar(&setupValidatorArgs.VisorHome, "visor-home", defaultSettings.VisorHome, "The vegavisor home")
+	validatorCmd.PersistentFlags().StringVar(&setupValidatorArgs.VegaHome, "vega-home", defaultSettings.VegaHome, "The vega home")
+	validatorCmd.PersistentFlags().StringVar(
+		&setupValidatorArgs.TendermintHome,
+		"tendermint-home",
+		defaultSettings.TendermintHome,
+		"The tendermint home",
+	)
+	validatorCmd.PersistentFlags().StringSliceVar(
+		&setupValidatorArgs.ExtraPersistentPeers,
+		"extra-persistent-peers",
+		nil,
+		"Comma separated list of extra tendermint persistent peers(<node-id>@<host>:<port>) added to the network defaults",
+	)
+	validatorCmd.PersistentFlags().StringVar(
+		&setupValidatorArgs.GenesisFile,
+		"genesis-file",
+		"",
+		"Local genesis.json file used instead of downloading it from the network",
+	)
+	validatorCmd.PersistentFlags().StringVar(
+		&setupValidatorArgs.GenesisSHA256,
+		"genesis-sha256",
+		"",
+		"Expected sha256 checksum of the genesis file. Overrides the checksum from the network config",
+	)
+	validatorCmd.PersistentFlags().StringVar(
+		&setupValidatorArgs.ChainID,
+		"chain-id",
+		"",
+		"Vega chain id, e.g. vega-mainnet-0011. Defaults to the chain id of the selected network",
+	)
+	validatorCmd.PersistentFlags().BoolVar(
+		&setupValidatorArgs.SkipGenesisChecksum,
+		"skip-genesis-checksum",
+		false,
+		"Skip checksum verification for the genesis file",
+	)
+	validatorCmd.PersistentFlags().StringVar(&setupValidatorArgs.VegaBinary, "vega-binary", "", "Local vega binary used instead of downloading it")
+	validatorCmd.PersistentFlags().StringVar(&setupValidatorArgs.VisorBinary, "visor-binary", "", "Local visor binary used instead of downloading it")
+	validatorCmd.PersistentFlags().BoolVar(
+		&setupValidatorArgs.SkipChecksum,
+		"skip-checksum",
+		false,
+		"Skip checksum verification for downloaded binaries. Use it for networks that do not publish checksums",
+	)
+	validatorCmd.PersistentFlags().BoolVar(&setupValidatorArgs.NoCache, "no-cache", false, "Do not use the downloaded binaries cache")
+	validatorCmd.PersistentFlags().BoolVar(
+		&setupValidatorArgs.DryRun,
+		"dry-run",
+		false,
+		"Print all actions and config changes without writing any files or downloading binaries",
+	)
+
+	validatorCmd.PersistentFlags().StringVar(
+		&setupValidatorArgs.Pruning,
+		"pruning",
+		string(service.PruningDefault),
+		fmt.Sprintf("Pruning of the tendermint and the vega state. Available modes: %v", service.AvailablePruningModes()),
+	)
+	validatorCmd.PersistentFlags().IntVar(
+		&setupValidatorArgs.PruningKeepRecent,
+		"pruning-keep-recent",
+		0,
+		"Number of recent vega snapshots kept. Required in the custom pruning mode",
+	)
+	validatorCmd.PersistentFlags().DurationVar(
+		&setupValidatorArgs.PruningInterval,
+		"pruning-interval",
+		0,
+		"How often tendermint prunes the state in the custom pruning mode. Defaults to the node default",
+	)
+	validatorCmd.PersistentFlags().IntVar(
+		&setupValidatorArgs.SnapshotInterval,
+		"snapshot-interval",
+		0,
+		"Number of blocks between the vega snapshots. Defaults to the node default",
+	)
+	validatorCmd.PersistentFlags().IntVar(
+		&setupValidatorArgs.SnapshotKeepRecent,
+		"snapshot-keep-recent",
+		0,
+		"Number of recent vega snapshots kept for the statesync serving. Defaults to the node default",
+	)
+
+	validatorCmd.PersistentFlags().BoolVar(
+		&setupValidatorArgs.NoAutoUpgrade,
+		"no-auto-upgrade",
+		false,
+		"Do not let visor download the new vega releases on the protocol upgrade. Upgrade folders must be prepared manually",
+	)
+	validatorCmd.PersistentFlags().StringVar(
+		&setupValidatorArgs.ConfigOverrides,
+		"config-overrides",
+		"",
+		"TOML file with the extra config keys applied after the assistant updates the configs. Sections: [vega], [tendermint], [visor]",
+	)
+	validatorCmd.PersistentFlags().DurationVar(
+		&setupValidatorArgs.Timeout,
+		"timeout",
+		0,
+		"Maximum duration of the setup, e.g. 30m. The setup is cancelled and cleaned up when it is exceeded. Defaults to no timeout",
+	)
+	validatorCmd.PersistentFlags().StringVar(
+		&setupValidatorArgs.Owner,
+		"owner",
+		"",
+		"Owner(<user>[:<group>]) of the homes and their contents after the setup, e.g. the service user when the setup runs as root",
+	)
+	validatorCmd.PersistentFlags().BoolVar(
+		&setupValidatorArgs.SkipPortCheck,
+		"skip-port-check",
+		false,
+		"Skip checking the ports bound by the node are not used by other processes",
+	)
+	validatorCmd.PersistentFlags().IntVar(&setupValidatorArgs.TendermintP2PPort, "tm-p2p-port", service.DefaultTendermintP2PPort, "Tendermint p2p listen port")
+	validatorCmd.PersistentFlags().IntVar(&setupValidatorArgs.TendermintRPCPort, "tm-rpc-port", service.DefaultTendermintRPCPort, "Tendermint rpc listen port")
+	validatorCmd.PersistentFlags().StringVar(
+		&setupValidatorArgs.PostSetupHook,
+		"post-setup-hook",
+		"",
+		"Executable run after the successful setup. The homes are passed in the VEGA_ASSISTANT_* environment variables",
+	)
+	validatorCmd.PersistentFlags().BoolVar(
+		&setupValidatorArgs.IgnoreHookErrors,
+		"ignore-hook-errors",
+		false,
+		"Print a warning instead of failing the setup when the post-setup hook fails",
+	)
+
+	validatorCmd.PersistentFlags().StringVar(
+		&setupValidatorArgs.NodeWalletPassphraseFile,
+		"nodewallet-passphrase-file",
+		"",
+		"File with the passphrase for the node wallets",
+	)
+	validatorCmd.PersistentFlags().StringVar(
+		&setupValidatorArgs.WalletPassphraseFile,
+		"wallet-passphrase-file",
+		"",
+		"File with the passphrase for the generated vega and ethereum wallets. Defaults to the --nodewallet-passphrase-file",
+	)
+	validatorCmd.PersistentFlags().StringVar(
+		&setupValidatorArgs.VegaWalletPath,
+		"vega-wallet",
+		"",
+		"Existing vega wallet imported into the node wallets. The new wallet is generated when not set",
+	)
+	validatorCmd.PersistentFlags().StringVar(
+		&setupValidatorArgs.VegaWalletPassphraseFile,
+		"vega-wallet-passphrase-file",
+		"",
+		"File with the passphrase for the imported vega wallet",
+	)
+	validatorCmd.PersistentFlags().StringVar(
+		&setupValidatorArgs.EthereumClefAddress,
+		"ethereum-clef-address",
+		"",
+		"Address of the clef signer keeping the ethereum key. Mutually exclusive with the --ethereum-wallet",
+	)
+	validatorCmd.PersistentFlags().StringVar(
+		&setupValidatorArgs.EthereumWalletPath,
+		"ethereum-wallet",
+		"",
+		"Existing ethereum keystore file imported into the node wallets. The new wallet is generated when neither this flag nor the --ethereum-clef-address is set",
+	)
+	validatorCmd.PersistentFlags().StringVar(
+		&setupValidatorArgs.EthereumWalletPassphraseFile,
+		"ethereum-wallet-passphrase-file",
+		"",
+		"File with the passphrase for the imported ethereum wallet",
+	)
+	validatorCmd.PersistentFlags().StringVar(
+		&setupValidatorArgs.EthereumRPCEndpoint,
+		"ethereum-rpc-endpoint",
+		"",
+		"The ethereum node rpc endpoint used by the validator",
+	)
+	validatorCmd.PersistentFlags().StringVar(
+		&setupValidatorArgs.TendermintPrivValidatorKey,
+		"tendermint-priv-validator-key",
+		"",
+		"Existing tendermint priv_validator_key.json file. The new key is generated by the tendermint init when not set",
+	)
+
+	validatorCmd.MarkPersistentFlagRequired("nodewallet-passphrase-file")
+	validatorCmd.MarkPersistentFlagRequired("ethereum-rpc-endpoint")
+}
+
+func validatorSetup(logger *zap.SugaredLogger, args SetupValidatorArgs) error {
+	if args.GithubToken != "" {
+		github.SetToken(args.GithubToken)
+	}
+
+	networkConfig, err := selectNetworkConfig(logger, args.Network, args.NetworkConfigFile, args.DiscoverPeers)
+	if err != nil {
+		return fmt.Errorf("failed to get network config: %w", err)
+	}
+
+	// Validator replays the chain from block 0, so it starts with the genesis binary and visor upgrades it
+	if networkConfig.GenesisVersion == "" || networkConfig.LowestVisorVersion == "" {
+		return fmt.Errorf("network config must define genesis-version and lowest-visor-version to setup validator")
+	}
+
+	apiClient, err := vegaapi.NewNetworkAPI(networkConfig.DataNodesRESTUrls, true, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create vega network api client: %w", err)
+	}
+
+	statisticsResponse, err := apiClient.Statistics(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to get response for the /statistics endpoint from the network servers: %w", err)
+	}
+
+	settings := service.DefaultGenerateSettings()
+	settings.NonInteractive = true
+	settings.Mode = service.StartFromBlock0
+	settings.VisorHome = args.VisorHome
+	settings.VegaHome = args.VegaHome
+	settings.TendermintHome = args.TendermintHome
+	settings.ExtraPersistentPeers = args.ExtraPersistentPeers
+	settings.GenesisFile = args.GenesisFile
+	settings.GenesisSHA256 = args.GenesisSHA256
+	settings.SkipGenesisChecksum = args.SkipGenesisChecksum
+	settings.VegaBinaryPath = args.VegaBinary
+	settings.VisorBinaryPath = args.VisorBinary
+	settings.SkipChecksum = args.SkipChecksum
+	settings.NoCache = args.NoCache
+	settings.DryRun = args.DryRun
+	settings.Pruning = service.PruningMode(args.Pruning)
+	settings.PruningKeepRecent = args.PruningKeepRecent
+	settings.PruningInterval = args.PruningInterval
+	settings.SnapshotInterval = args.SnapshotInterval
+	settings.SnapshotKeepRecent = args.SnapshotKeepRecent
+	settings.NoAutoUpgrade = args.NoAutoUpgrade
+	settings.ConfigOverridesFile = args.ConfigOverrides
+	settings.Owner = args.Owner
+	settings.SkipPortCheck = args.SkipPortCheck
+	settings.TendermintP2PPort = args.TendermintP2PPort
+	settings.TendermintRPCPort = args.TendermintRPCPort
+	settings.PostSetupHook = args.PostSetupHook
+	settings.IgnoreHookErrors = args.IgnoreHookErrors
+	settings.VegaBinaryVersion = networkConfig.GenesisVersion
+	settings.VisorBinaryVersion = networkConfig.LowestVisorVersion
+	settings.VegaChainId, err = service.ResolveChainID(args.ChainID, networkConfig, statisticsResponse.ChainID)
+	if err != nil {
+		return err
+	}
+	// Validator does not run the data-node
+	settings.DataNodeHome = ""
+
+	logger.Infof(
+		"Setting up validator for the %s chain with vega %s and visor %s",
+		settings.VegaChainId,
+		settings.VegaBinaryVersion,
+		settings.VisorBinaryVersion,
+	)
+
+	svc, err := service.NewValidatorGenerator(apiClient, *settings, args.ValidatorSettings, networkConfig)
+	if err != nil {
+		return fmt.Errorf("failed to start generator service: %w", err)
+	}
+	ctx, stop := interruptContext()
+	defer stop()
+	ctx, cancel := setupTimeoutContext(ctx, args.Timeout)
+	defer cancel()
+
+	err = svc.Run(ctx, logger)
+	// The second interrupt kills the assistant during the cleanup
+	stop()
+	if err != nil {
+		if errors.Is(err, service.ErrCancelled) {
+			cleanupCancelledSetup(logger, svc)
+
+			return fmt.Errorf("failed to setup validator: %w", err)
+		}
+
+		// The node is set up before the hook runs, so nothing is rolled back
+		hookErr := &types.HookError{}
+		if errors.As(err, &hookErr) {
+			return fmt.Errorf("setup completed, but %w", err)
+		}
+
+		if restoreErr := svc.RestoreConfigBackups(logger); restoreErr != nil {
+			logger.Errorf("Failed to restore config backups: %s", restoreErr.Error())
+		}
+
+		return fmt.Errorf("failed to setup validator: %w", err)
+	}
+
+	if settings.DryRun {
+		logger.Info("Dry run completed. No changes have been applied")
+
+		return nil
+	}
+
+	service.PrintValidatorInstructions(settings.VisorHome)
+
+	return nil
+}
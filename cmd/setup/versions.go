@@ -0,0 +1,117 @@
+package setup
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/rodaine/table"
+	"github.com/spf13/cobra"
+
+	"github.com/daniel1302/vega-assistant/github"
+	"github.com/daniel1302/vega-assistant/network"
+)
+
+type VersionsArgs struct {
+	*SetupArgs
+
+	Network            string
+	NetworkConfigFile  string
+	Limit              int
+	IncludePrereleases bool
+}
+
+var versionsArgs VersionsArgs
+
+var versionsCmd = &cobra.Command{
+	Use:   "versions",
+	Short: "List recent vega releases available for the --version flag",
+	// Failed GitHub request is not an usage error
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return listVersions(versionsArgs)
+	},
+}
+
+func init() {
+	versionsArgs.SetupArgs = &setupArgs
+
+	versionsCmd.PersistentFlags().StringVar(
+		&versionsArgs.Network,
+		"network",
+		string(network.Mainnet),
+		fmt.Sprintf("The network to list releases for. Available networks: %v", network.AvailableNetworks()),
+	)
+	versionsCmd.PersistentFlags().StringVar(
+		&versionsArgs.NetworkConfigFile,
+		"network-config",
+		"",
+		"TOML or JSON file with custom network config. Takes precedence over the --network flag",
+	)
+	versionsCmd.PersistentFlags().IntVar(&versionsArgs.Limit, "limit", 10, "Maximum number of releases to list")
+	versionsCmd.PersistentFlags().BoolVar(
+		&versionsArgs.IncludePrereleases,
+		"include-prereleases",
+		false,
+		"List prereleases too",
+	)
+}
+
+func listVersions(args VersionsArgs) error {
+	if args.GithubToken != "" {
+		github.SetToken(args.GithubToken)
+	}
+
+	var (
+		networkConfig network.NetworkConfig
+		err           error
+	)
+	if args.NetworkConfigFile != "" {
+		networkConfig, err = network.LoadNetworkConfig(args.NetworkConfigFile)
+	} else {
+		networkConfig, err = network.ConfigForNetwork(network.Name(args.Network))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get network config: %w", err)
+	}
+
+	releaseSource := networkConfig.ReleaseSource()
+	if err := releaseSource.Validate(); err != nil {
+		return err
+	}
+
+	listFunc := github.ListStableReleases
+	if args.IncludePrereleases {
+		listFunc = github.ListReleases
+	}
+	releases, err := listFunc(networkConfig.Repository, args.Limit)
+	if err != nil {
+		return fmt.Errorf("failed to list vega releases: %w", err)
+	}
+
+	platform := github.CurrentPlatform()
+	headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
+	columnFmt := color.New(color.FgYellow).SprintfFunc()
+
+	fmt.Printf("\n Releases of %s:\n\n", networkConfig.Repository)
+	tbl := table.New("Version", "Published", "Prerelease", fmt.Sprintf("Binaries for %s", platform))
+	tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+	for _, release := range releases {
+		binaries := color.GreenString("yes")
+		if !release.HasAsset(releaseSource.ArtifactName(github.ArtifactVega, platform, release.TagName)) ||
+			!release.HasAsset(releaseSource.ArtifactName(github.ArtifactVisor, platform, release.TagName)) {
+			binaries = color.RedString("no")
+		}
+
+		prerelease := "no"
+		if release.Prerelease {
+			prerelease = "yes"
+		}
+
+		tbl.AddRow(release.TagName, release.PublishedAt.Format("2006-01-02"), prerelease, binaries)
+	}
+
+	tbl.Print()
+	fmt.Println("")
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package setup
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	service "github.com/daniel1302/vega-assistant/service/datanode"
+)
+
+type VisorConfigArgs struct {
+	*SetupArgs
+
+	Version        string
+	VisorHome      string
+	VegaHome       string
+	TendermintHome string
+	DataNodeHome   string
+	NoDataNode     bool
+}
+
+var visorConfigArgs VisorConfigArgs
+
+var visorConfigCmd = &cobra.Command{
+	Use:   "visor-config",
+	Short: "Regenerate the vegavisor run-config.toml file for the given version",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dataNodeHome := visorConfigArgs.DataNodeHome
+		if dataNodeHome == "" {
+			dataNodeHome = visorConfigArgs.VegaHome
+		}
+		if visorConfigArgs.NoDataNode {
+			dataNodeHome = ""
+		}
+
+		if err := service.RegenerateVisorRunConfig(
+			visorConfigArgs.Logger,
+			visorConfigArgs.VisorHome,
+			visorConfigArgs.Version,
+			visorConfigArgs.VegaHome,
+			visorConfigArgs.TendermintHome,
+			dataNodeHome,
+		); err != nil {
+			return fmt.Errorf("failed to regenerate visor run config: %w", err)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	visorConfigArgs.SetupArgs = &setupArgs
+
+	visorConfigCmd.PersistentFlags().StringVar(
+		&visorConfigArgs.Version,
+		"version",
+		"",
+		"The vega version the run-config.toml is generated for, e.g. v0.73.4, or genesis",
+	)
+	visorConfigCmd.PersistentFlags().StringVar(
+		&visorConfigArgs.VisorHome,
+		"visor-home",
+		"",
+		"The vegavisor home",
+	)
+	visorConfigCmd.PersistentFlags().StringVar(
+		&visorConfigArgs.VegaHome,
+		"vega-home",
+		"",
+		"The vega home",
+	)
+	visorConfigCmd.PersistentFlags().StringVar(
+		&visorConfigArgs.TendermintHome,
+		"tendermint-home",
+		"",
+		"The tendermint home",
+	)
+	visorConfigCmd.PersistentFlags().StringVar(
+		&visorConfigArgs.DataNodeHome,
+		"data-node-home",
+		"",
+		"The data-node home. Defaults to the vega home",
+	)
+	visorConfigCmd.PersistentFlags().BoolVar(
+		&visorConfigArgs.NoDataNode,
+		"no-data-node",
+		false,
+		"Do not run the data-node process, e.g. for the validator node",
+	)
+
+	visorConfigCmd.MarkPersistentFlagRequired("version")
+	visorConfigCmd.MarkPersistentFlagRequired("visor-home")
+	visorConfigCmd.MarkPersistentFlagRequired("vega-home")
+	visorConfigCmd.MarkPersistentFlagRequired("tendermint-home")
+}
@@ -1,9 +1,8 @@
 package github
 
 import (
+	"context"
 	"fmt"
-	"io"
-	"net/http"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -18,57 +17,101 @@ const (
 	ArtifactVisor ArtifactType = "visor"
 )
 
+// Platform is the os and architecture the release assets are built for
+type Platform struct {
+	OS   string
+	Arch string
+}
+
+// CurrentPlatform returns the platform the assistant is running on
+func CurrentPlatform() Platform {
+	return Platform{OS: runtime.GOOS, Arch: runtime.GOARCH}
+}
+
+func (p Platform) String() string {
+	return fmt.Sprintf("%s-%s", p.OS, p.Arch)
+}
+
+// DownloadArtifact downloads the artifact for the platform from the source and extracts its binary into
+// the outputDir. Binary is taken from the cache when cache is not nil and binary is already cached. The
+// binary mirror of the source is tried before the GitHub release.
 func DownloadArtifact(
-	repository, version, outputDir string,
+	ctx context.Context,
+	source ReleaseSource,
+	version, outputDir string,
 	artifactType ArtifactType,
+	platform Platform,
+	cache *ArtifactCache,
 ) (string, error) {
-	operatingSystem := runtime.GOOS
-	architecture := runtime.GOARCH
-
-	artifactName := fmt.Sprintf("%s-%s-%s.zip", artifactType, operatingSystem, architecture)
-
-	artifactURL := fmt.Sprintf(
-		"https://github.com/%s/releases/download/%s/%s",
-		repository,
-		version,
-		artifactName,
-	)
+	if cache != nil {
+		if cachedBinaryPath, cached := cache.Get(source.Repository, version, artifactType, platform); cached {
+			binaryPath := filepath.Join(outputDir, source.ArtifactBinaryName(artifactType))
+			if err := utils.CopyFile(cachedBinaryPath, binaryPath); err != nil {
+				return "", fmt.Errorf("failed to copy cached binary %s: %w", cachedBinaryPath, err)
+			}
 
-	filePath := filepath.Join(outputDir, artifactName)
-	out, err := os.Create(filePath)
-	if err != nil {
-		return "", fmt.Errorf("failed to create local artifact file: %w", err)
+			return binaryPath, nil
+		}
 	}
-	defer out.Close()
 
-	resp, err := http.Get(artifactURL)
-	if err != nil {
-		return "", fmt.Errorf("failed to get file from '%s': %w", artifactURL, err)
+	var mirrorErr error
+	if source.Mirror != "" {
+		binaryPath, err := downloadArtifact(ctx, source, version, outputDir, artifactType, platform)
+		if err == nil || ctx.Err() != nil {
+			return binaryPath, err
+		}
+		mirrorErr = err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("bad http status: %s", resp.Status)
+	// GitHub is the fallback when the mirror fails
+	binaryPath, err := downloadArtifact(ctx, source.withoutMirror(), version, outputDir, artifactType, platform)
+	if err != nil && mirrorErr != nil {
+		return "", fmt.Errorf("%w, mirror download also failed: %w", err, mirrorErr)
 	}
 
-	_, err = io.Copy(out, resp.Body)
-	if err != nil {
-		return "", fmt.Errorf(
-			"failed to copy bytes from downloaded file(%s) to the local destination(%s): %w",
-			artifactURL,
-			filePath,
-			err,
-		)
+	return binaryPath, err
+}
+
+// downloadArtifact downloads the artifact from the mirror of the source, or from the GitHub release when
+// the source has no mirror, and extracts its binary into the outputDir
+func downloadArtifact(
+	ctx context.Context,
+	source ReleaseSource,
+	version, outputDir string,
+	artifactType ArtifactType,
+	platform Platform,
+) (string, error) {
+	artifactName := source.ArtifactName(artifactType, platform, version)
+	filePath := filepath.Join(outputDir, artifactName)
+	if source.Mirror != "" {
+		if err := downloadFromMirror(ctx, source.Mirror, artifactType, platform, version, artifactName, filePath); err != nil {
+			return "", err
+		}
+	} else {
+		artifactURL := releaseAssetURL(source.Repository, version, artifactName)
+		if err := utils.DownloadFileContext(ctx, artifactURL, filePath, authHeaders()); err != nil {
+			return "", fmt.Errorf("failed to download file from '%s': %w", artifactURL, handleDownloadError(err))
+		}
 	}
 
-	if err := utils.Unzip(filePath, outputDir); err != nil {
-		return "", fmt.Errorf("failed to unzip downloaded artifact(%s): %w", filePath, err)
+	binaryName := source.ArtifactBinaryName(artifactType)
+	binaryPath := filepath.Join(outputDir, binaryName)
+	if err := utils.ExtractArchiveEntry(filePath, binaryName, binaryPath); err != nil {
+		return "", fmt.Errorf("failed to extract binary from the downloaded artifact(%s): %w", artifactName, err)
 	}
 
-	binaryPath := filepath.Join(outputDir, string(artifactType))
-	if err := os.Chmod(binaryPath, os.ModePerm); err != nil {
+	if err := os.Chmod(binaryPath, utils.ExecutableFileMode); err != nil {
 		return "", fmt.Errorf("failed to change permissions mod for binary %s: %w", binaryPath, err)
 	}
 
 	return binaryPath, nil
 }
+
+func releaseAssetURL(repository, version, assetName string) string {
+	return fmt.Sprintf(
+		"https://github.com/%s/releases/download/%s/%s",
+		repository,
+		version,
+		assetName,
+	)
+}
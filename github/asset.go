@@ -0,0 +1,77 @@
+package github
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/daniel1302/vega-assistant/utils"
+)
+
+// DefaultAssetNameTemplate is the name of the official vega and visor release assets
+const DefaultAssetNameTemplate = "{{.Artifact}}-{{.OS}}-{{.Arch}}.zip"
+
+// AssetLayout describes the release asset of the artifact. The NameTemplate supports the {{.Artifact}},
+// {{.OS}}, {{.Arch}} and {{.Version}} placeholders. The asset is the .zip or the .tar.gz archive. The
+// BinaryName is the binary extracted from the archive.
+type AssetLayout struct {
+	NameTemplate string
+	BinaryName   string
+}
+
+type assetNameValues struct {
+	Artifact string
+	OS       string
+	Arch     string
+	Version  string
+}
+
+// DefaultAssetLayout returns the layout of the official release assets
+func DefaultAssetLayout(artifactType ArtifactType) AssetLayout {
+	return AssetLayout{
+		NameTemplate: DefaultAssetNameTemplate,
+		BinaryName:   string(artifactType),
+	}
+}
+
+// Validate checks the name template renders the supported archive name and the binary name is a plain
+// file name
+func (l AssetLayout) Validate() error {
+	assetName, err := l.assetName(ArtifactVega, CurrentPlatform(), "v0.0.0")
+	if err != nil {
+		return err
+	}
+	if !utils.IsSupportedArchive(assetName) {
+		return fmt.Errorf("invalid asset name template(%q): expected .zip, .tar.gz or .tgz asset", l.NameTemplate)
+	}
+
+	if l.BinaryName == "" || strings.ContainsAny(l.BinaryName, `/\`) {
+		return fmt.Errorf("invalid binary name(%q): expected file name without directories", l.BinaryName)
+	}
+
+	return nil
+}
+
+func (l AssetLayout) assetName(artifactType ArtifactType, platform Platform, version string) (string, error) {
+	tmpl, err := template.New("asset-name").Option("missingkey=error").Parse(l.NameTemplate)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse asset name template(%s): %w", l.NameTemplate, err)
+	}
+
+	var buff bytes.Buffer
+	if err := tmpl.Execute(&buff, assetNameValues{
+		Artifact: string(artifactType),
+		OS:       platform.OS,
+		Arch:     platform.Arch,
+		Version:  version,
+	}); err != nil {
+		return "", fmt.Errorf("failed to render asset name template(%s): %w", l.NameTemplate, err)
+	}
+
+	if buff.Len() == 0 {
+		return "", fmt.Errorf("asset name template(%s) renders empty name", l.NameTemplate)
+	}
+
+	return buff.String(), nil
+}
@@ -0,0 +1,85 @@
+package github
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/daniel1302/vega-assistant/utils"
+)
+
+const tokenEnvName = "GITHUB_TOKEN"
+
+var (
+	authTokenMu sync.Mutex
+	authToken   = os.Getenv(tokenEnvName)
+)
+
+// SetToken sets the token used to authenticate all GitHub requests. By default,
+// token is read from the GITHUB_TOKEN environment variable.
+func SetToken(token string) {
+	authTokenMu.Lock()
+	defer authTokenMu.Unlock()
+
+	authToken = token
+}
+
+func authHeaders() map[string]string {
+	authTokenMu.Lock()
+	token := authToken
+	authTokenMu.Unlock()
+
+	if token == "" {
+		return nil
+	}
+
+	return map[string]string{
+		"Authorization": fmt.Sprintf("Bearer %s", token),
+	}
+}
+
+func setAuthHeaders(req *http.Request) {
+	for name, value := range authHeaders() {
+		req.Header.Set(name, value)
+	}
+}
+
+// rateLimitError returns descriptive error when response has been rejected by
+// the GitHub rate limiter, otherwise nil is returned.
+func rateLimitError(statusCode int, header http.Header) error {
+	if statusCode != http.StatusForbidden && statusCode != http.StatusTooManyRequests {
+		return nil
+	}
+
+	if header.Get("X-RateLimit-Remaining") != "0" {
+		return nil
+	}
+
+	resetTime := "unknown"
+	if resetTimestamp, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		resetTime = time.Unix(resetTimestamp, 0).Format(time.RFC1123)
+	}
+
+	return fmt.Errorf(
+		"github api rate limit exceeded, limit resets at %s: provide token with the --github-token flag or the %s environment variable",
+		resetTime,
+		tokenEnvName,
+	)
+}
+
+func handleDownloadError(err error) error {
+	var statusErr utils.HTTPStatusError
+	if !errors.As(err, &statusErr) {
+		return err
+	}
+
+	if rateLimitErr := rateLimitError(statusErr.StatusCode, statusErr.Header); rateLimitErr != nil {
+		return rateLimitErr
+	}
+
+	return err
+}
@@ -0,0 +1,62 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/daniel1302/vega-assistant/utils"
+)
+
+func TestAuthorizationHeader(t *testing.T) {
+	tests := []struct {
+		name     string
+		token    string
+		expected string
+	}{
+		{name: "token is set", token: "secret-token", expected: "Bearer secret-token"},
+		{name: "token is empty", token: "", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authorization := map[string]string{}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				authorization[r.URL.Path] = r.Header.Get("Authorization")
+				w.Write([]byte(`{}`))
+			}))
+			defer server.Close()
+
+			SetToken(tt.token)
+			t.Cleanup(func() { SetToken("") })
+
+			req, err := http.NewRequest(http.MethodGet, server.URL+"/api", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			setAuthHeaders(req)
+			resp, err := utils.DoHTTPRequest(req)
+			if err != nil {
+				t.Fatalf("failed to send api request: %s", err)
+			}
+			resp.Body.Close()
+
+			dst := filepath.Join(t.TempDir(), "asset.zip")
+			if err := utils.DownloadFileContext(context.Background(), server.URL+"/asset", dst, authHeaders()); err != nil {
+				t.Fatalf("failed to download asset: %s", err)
+			}
+
+			for _, path := range []string{"/api", "/asset"} {
+				got, requested := authorization[path]
+				if !requested {
+					t.Fatalf("expected request to %s", path)
+				}
+				if got != tt.expected {
+					t.Errorf("expected Authorization header %q for %s, got %q", tt.expected, path, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package github
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/daniel1302/vega-assistant/utils"
+)
+
+// ArtifactCache keeps downloaded binaries between runs. Binaries are stored by
+// the repository, version, os and architecture.
+type ArtifactCache struct {
+	dir string
+}
+
+func NewArtifactCache(dir string) *ArtifactCache {
+	return &ArtifactCache{dir: dir}
+}
+
+// DefaultCacheDir returns the `vega-assistant` directory in the user cache dir,
+// e.g. $XDG_CACHE_HOME or ~/.cache on Linux.
+func DefaultCacheDir() string {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		cacheDir = filepath.Join(utils.CurrentUserHomePath(), ".cache")
+	}
+
+	return filepath.Join(cacheDir, "vega-assistant")
+}
+
+func (c *ArtifactCache) binaryPath(repository, version string, artifactType ArtifactType, platform Platform) string {
+	return filepath.Join(
+		c.dir,
+		strings.ReplaceAll(repository, "/", "_"),
+		version,
+		platform.String(),
+		string(artifactType),
+	)
+}
+
+// Get returns path to the cached binary and true if the binary is in the cache
+func (c *ArtifactCache) Get(repository, version string, artifactType ArtifactType, platform Platform) (string, bool) {
+	cachedBinaryPath := c.binaryPath(repository, version, artifactType, platform)
+	if !utils.FileExists(cachedBinaryPath) || utils.IsDir(cachedBinaryPath) {
+		return "", false
+	}
+
+	return cachedBinaryPath, true
+}
+
+func (c *ArtifactCache) Put(repository, version string, artifactType ArtifactType, platform Platform, binaryPath string) error {
+	cachedBinaryPath := c.binaryPath(repository, version, artifactType, platform)
+	if err := os.MkdirAll(filepath.Dir(cachedBinaryPath), utils.DirMode); err != nil {
+		return fmt.Errorf("failed to create cache directory for %s: %w", cachedBinaryPath, err)
+	}
+
+	if err := utils.CopyFile(binaryPath, cachedBinaryPath); err != nil {
+		return fmt.Errorf("failed to copy binary to cache: %w", err)
+	}
+
+	return nil
+}
+
+func (c *ArtifactCache) Invalidate(repository, version string, artifactType ArtifactType, platform Platform) error {
+	cachedBinaryPath := c.binaryPath(repository, version, artifactType, platform)
+	if err := os.RemoveAll(cachedBinaryPath); err != nil {
+		return fmt.Errorf("failed to remove cached binary %s: %w", cachedBinaryPath, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,247 @@
+package github
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/daniel1302/vega-assistant/utils"
+)
+
+const checksumsAssetName = "checksums.txt"
+
+// DownloadArtifactVerified downloads artifact and verifies its SHA-256 checksum
+// against the checksums published in the release. The `checksums.txt` asset is
+// checked first, then the `<artifact>.sha256` sidecar asset. Checksums of the
+// artifact downloaded from the binary mirror are downloaded from the mirror too,
+// and the GitHub release is the fallback when the mirror artifact cannot be
+// verified. Only verified binaries are stored in the cache.
+func DownloadArtifactVerified(
+	ctx context.Context,
+	source ReleaseSource,
+	version, outputDir string,
+	artifactType ArtifactType,
+	platform Platform,
+	cache *ArtifactCache,
+) (string, error) {
+	if cache != nil {
+		if _, cached := cache.Get(source.Repository, version, artifactType, platform); cached {
+			return DownloadArtifact(ctx, source, version, outputDir, artifactType, platform, cache)
+		}
+	}
+
+	var mirrorErr error
+	if source.Mirror != "" {
+		binaryPath, err := downloadArtifactVerified(ctx, source, version, outputDir, artifactType, platform)
+		if err != nil && ctx.Err() != nil {
+			return "", err
+		}
+		mirrorErr = err
+		if err == nil {
+			return binaryPath, putVerifiedBinary(cache, source.Repository, version, artifactType, platform, binaryPath)
+		}
+	}
+
+	// GitHub is the fallback when the mirror fails or its artifact does not match the mirror checksum
+	binaryPath, err := downloadArtifactVerified(ctx, source.withoutMirror(), version, outputDir, artifactType, platform)
+	if err != nil {
+		if mirrorErr != nil {
+			return "", fmt.Errorf("%w, mirror download also failed: %w", err, mirrorErr)
+		}
+
+		return "", err
+	}
+
+	return binaryPath, putVerifiedBinary(cache, source.Repository, version, artifactType, platform, binaryPath)
+}
+
+func putVerifiedBinary(
+	cache *ArtifactCache,
+	repository, version string,
+	artifactType ArtifactType,
+	platform Platform,
+	binaryPath string,
+) error {
+	if cache == nil {
+		return nil
+	}
+
+	if err := cache.Put(repository, version, artifactType, platform, binaryPath); err != nil {
+		return fmt.Errorf("failed to store verified binary in cache: %w", err)
+	}
+
+	return nil
+}
+
+// downloadArtifactVerified downloads the artifact and its checksums from the same place: the mirror of the
+// source, or the GitHub release when the source has no mirror
+func downloadArtifactVerified(
+	ctx context.Context,
+	source ReleaseSource,
+	version, outputDir string,
+	artifactType ArtifactType,
+	platform Platform,
+) (string, error) {
+	binaryPath, err := downloadArtifact(ctx, source, version, outputDir, artifactType, platform)
+	if err != nil {
+		return "", err
+	}
+
+	artifactName := source.ArtifactName(artifactType, platform, version)
+	downloadAsset := func(assetName string) (string, error) {
+		return downloadReleaseAsset(source.Repository, version, assetName)
+	}
+	if source.Mirror != "" {
+		downloadAsset = func(assetName string) (string, error) {
+			return downloadMirrorAsset(ctx, source.Mirror, artifactType, platform, version, assetName)
+		}
+	}
+
+	checksums, err := releaseChecksums(downloadAsset, artifactName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get checksums for %s: %w", artifactName, err)
+	}
+
+	// Checksum may be published either for the zip archive or for the binary itself
+	filesToVerify := []struct {
+		name string
+		path string
+	}{
+		{name: artifactName, path: filepath.Join(outputDir, artifactName)},
+		{name: source.ArtifactBinaryName(artifactType), path: binaryPath},
+	}
+
+	for _, file := range filesToVerify {
+		expectedChecksum, found := checksums[file.name]
+		if !found {
+			continue
+		}
+
+		actualChecksum, err := utils.FileSHA256(file.path)
+		if err != nil {
+			return "", fmt.Errorf("failed to compute checksum for %s: %w", file.path, err)
+		}
+
+		if !strings.EqualFold(expectedChecksum, actualChecksum) {
+			return "", fmt.Errorf(
+				"checksum mismatch for %s: expected %s, got %s",
+				file.name,
+				expectedChecksum,
+				actualChecksum,
+			)
+		}
+
+		return binaryPath, nil
+	}
+
+	return "", fmt.Errorf("no checksum published for %s in the %s release", artifactName, version)
+}
+
+// releaseChecksums returns map of file name to the SHA-256 checksum. The checksum assets are downloaded
+// with the downloadAsset.
+func releaseChecksums(downloadAsset func(assetName string) (string, error), artifactName string) (map[string]string, error) {
+	checksumsContent, err := downloadAsset(checksumsAssetName)
+	if err == nil {
+		return parseChecksums(checksumsContent), nil
+	}
+
+	sidecarContent, sidecarErr := downloadAsset(artifactName + ".sha256")
+	if sidecarErr != nil {
+		return nil, fmt.Errorf(
+			"failed to download %s: %s, failed to download %s.sha256: %w",
+			checksumsAssetName,
+			err.Error(),
+			artifactName,
+			sidecarErr,
+		)
+	}
+
+	checksums := parseChecksums(sidecarContent)
+	// sidecar file may contain only the checksum without file name
+	fields := strings.Fields(sidecarContent)
+	if len(fields) == 1 {
+		checksums[artifactName] = fields[0]
+	}
+
+	return checksums, nil
+}
+
+// parseChecksums parses content in the sha256sum output format: `<checksum>  <file name>`
+func parseChecksums(content string) map[string]string {
+	result := map[string]string{}
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+
+		// binary mode in the sha256sum output is marked with '*' before file name
+		fileName := filepath.Base(strings.TrimPrefix(fields[1], "*"))
+		result[fileName] = fields[0]
+	}
+
+	return result
+}
+
+func downloadReleaseAsset(repository, version, assetName string) (string, error) {
+	assetURL := releaseAssetURL(repository, version, assetName)
+
+	req, err := http.NewRequest(http.MethodGet, assetURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request for '%s': %w", assetURL, err)
+	}
+	setAuthHeaders(req)
+
+	return readAsset(req)
+}
+
+// downloadMirrorAsset returns content of the small release asset, e.g. the checksums, from the binary mirror
+func downloadMirrorAsset(
+	ctx context.Context,
+	urlTemplate MirrorURLTemplate,
+	artifactType ArtifactType,
+	platform Platform,
+	version, assetName string,
+) (string, error) {
+	assetURL, err := urlTemplate.assetURL(artifactType, platform, version, assetName)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, assetURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request for '%s': %w", assetURL, err)
+	}
+
+	return readAsset(req)
+}
+
+func readAsset(req *http.Request) (string, error) {
+	assetURL := req.URL.String()
+	resp, err := utils.DoHTTPRequest(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to get file from '%s': %w", assetURL, err)
+	}
+	defer resp.Body.Close()
+
+	if err := rateLimitError(resp.StatusCode, resp.Header); err != nil {
+		return "", err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("bad http status: %s", resp.Status)
+	}
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response body from '%s': %w", assetURL, err)
+	}
+
+	return string(content), nil
+}
@@ -0,0 +1,109 @@
+package github
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestParseChecksums(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected map[string]string
+	}{
+		{name: "text mode", content: "abc  vega-linux-amd64.zip\n", expected: map[string]string{"vega-linux-amd64.zip": "abc"}},
+		{name: "binary mode", content: "abc *vega-linux-amd64.zip\n", expected: map[string]string{"vega-linux-amd64.zip": "abc"}},
+		{name: "path", content: "abc  dist/vega\n", expected: map[string]string{"vega": "abc"}},
+		{name: "invalid lines", content: "abc\n\nabc def ghi\n", expected: map[string]string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseChecksums(tt.content)
+			if fmt.Sprint(got) != fmt.Sprint(tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestDownloadArtifactVerifiedUsesMirrorChecksums(t *testing.T) {
+	const version = "v1.0.0"
+	platform := Platform{OS: "linux", Arch: "amd64"}
+	source := ReleaseSource{Repository: "vegaprotocol/vega"}
+	artifactName := source.ArtifactName(ArtifactVega, platform, version)
+	archive := testZipArchive(t, source.ArtifactBinaryName(ArtifactVega), "vega binary")
+	checksum := sha256.Sum256(archive)
+
+	tests := []struct {
+		name      string
+		checksums string
+		wantErr   string
+	}{
+		{name: "matching checksum", checksums: fmt.Sprintf("%s  %s\n", hex.EncodeToString(checksum[:]), artifactName)},
+		{name: "checksum mismatch", checksums: fmt.Sprintf("%s  %s\n", strings.Repeat("0", 64), artifactName), wantErr: "checksum mismatch"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/" + version + "/" + artifactName:
+					w.Write(archive)
+				case "/" + version + "/" + checksumsAssetName:
+					w.Write([]byte(tt.checksums))
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			defer server.Close()
+
+			source := source
+			source.Mirror = MirrorURLTemplate(server.URL + "/{{.Version}}/{{.Asset}}")
+			binaryPath, err := downloadArtifactVerified(context.Background(), source, version, t.TempDir(), ArtifactVega, platform)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected %q error, got %v", tt.wantErr, err)
+				}
+
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			content, err := os.ReadFile(binaryPath)
+			if err != nil || string(content) != "vega binary" {
+				t.Errorf("expected extracted binary, got %q(%v)", content, err)
+			}
+		})
+	}
+}
+
+func testZipArchive(t *testing.T, name, content string) []byte {
+	t.Helper()
+
+	var buff bytes.Buffer
+	writer := zip.NewWriter(&buff)
+	file, err := writer.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := file.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buff.Bytes()
+}
@@ -0,0 +1,89 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"text/template"
+
+	"github.com/daniel1302/vega-assistant/utils"
+)
+
+// MirrorURLTemplate is the url of the release asset on the mirror. It supports the {{.Artifact}},
+// {{.OS}}, {{.Arch}}, {{.Version}} and {{.Asset}} placeholders, where the {{.Asset}} is the asset name
+// rendered from the asset layout.
+type MirrorURLTemplate string
+
+type mirrorURLValues struct {
+	Artifact string
+	OS       string
+	Arch     string
+	Version  string
+	Asset    string
+}
+
+// Validate checks the template renders the absolute http or https url
+func (t MirrorURLTemplate) Validate() error {
+	assetURL, err := t.assetURL(ArtifactVega, CurrentPlatform(), "v0.0.0", "vega.zip")
+	if err != nil {
+		return err
+	}
+
+	parsedURL, err := url.Parse(assetURL)
+	if err != nil {
+		return fmt.Errorf("invalid url rendered from the template(%s): %w", t, err)
+	}
+	if (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") || parsedURL.Host == "" {
+		return fmt.Errorf("invalid url rendered from the template(%s): expected http or https url", t)
+	}
+
+	return nil
+}
+
+func (t MirrorURLTemplate) assetURL(artifactType ArtifactType, platform Platform, version, assetName string) (string, error) {
+	tmpl, err := template.New("mirror-url").Option("missingkey=error").Parse(string(t))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse mirror url template(%s): %w", t, err)
+	}
+
+	var buff bytes.Buffer
+	if err := tmpl.Execute(&buff, mirrorURLValues{
+		Artifact: string(artifactType),
+		OS:       platform.OS,
+		Arch:     platform.Arch,
+		Version:  version,
+		Asset:    assetName,
+	}); err != nil {
+		return "", fmt.Errorf("failed to render mirror url template(%s): %w", t, err)
+	}
+
+	return buff.String(), nil
+}
+
+// downloadFromMirror downloads the release asset from the binary mirror. The partially downloaded file is
+// removed on failure, so the fallback download does not resume it. It is kept when ctx is done, like for
+// the GitHub downloads.
+func downloadFromMirror(
+	ctx context.Context,
+	urlTemplate MirrorURLTemplate,
+	artifactType ArtifactType,
+	platform Platform,
+	version, assetName, filePath string,
+) error {
+	assetURL, err := urlTemplate.assetURL(artifactType, platform, version, assetName)
+	if err != nil {
+		return err
+	}
+
+	if err := utils.DownloadFileContext(ctx, assetURL, filePath, nil); err != nil {
+		if ctx.Err() == nil {
+			os.Remove(fmt.Sprintf("%s.part", filePath))
+		}
+
+		return fmt.Errorf("failed to download file from '%s': %w", assetURL, err)
+	}
+
+	return nil
+}
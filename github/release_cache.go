@@ -0,0 +1,73 @@
+package github
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ReleaseFetcher returns the release with the tag from the GitHub API
+type ReleaseFetcher func(repository, tag string) (Release, error)
+
+// ReleaseCache keeps the release metadata in memory, so the vega and the visor artifacts of the same
+// release are resolved with a single GitHub API request. Failed requests are not cached.
+type ReleaseCache struct {
+	mu       sync.Mutex
+	fetch    ReleaseFetcher
+	releases map[string]Release
+}
+
+func NewReleaseCache(fetch ReleaseFetcher) *ReleaseCache {
+	return &ReleaseCache{
+		fetch:    fetch,
+		releases: map[string]Release{},
+	}
+}
+
+// Release returns the cached release, or fetches it on the first call for the repository and the tag
+func (c *ReleaseCache) Release(repository, tag string) (Release, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := fmt.Sprintf("%s@%s", repository, tag)
+	if release, cached := c.releases[key]; cached {
+		return release, nil
+	}
+
+	release, err := c.fetch(repository, tag)
+	if err != nil {
+		return Release{}, err
+	}
+	c.releases[key] = release
+
+	return release, nil
+}
+
+var (
+	releaseCacheMu sync.Mutex
+	releaseCache   = NewReleaseCache(fetchRelease)
+)
+
+// SetReleaseCache replaces the cache used to resolve the releases, e.g. with the cache counting the
+// requests
+func SetReleaseCache(cache *ReleaseCache) {
+	releaseCacheMu.Lock()
+	defer releaseCacheMu.Unlock()
+
+	releaseCache = cache
+}
+
+func currentReleaseCache() *ReleaseCache {
+	releaseCacheMu.Lock()
+	defer releaseCacheMu.Unlock()
+
+	return releaseCache
+}
+
+func fetchRelease(repository, tag string) (Release, error) {
+	release := Release{}
+	if err := apiGet(fmt.Sprintf("%s/repos/%s/releases/tags/%s", apiURL, repository, tag), &release); err != nil {
+		return Release{}, err
+	}
+
+	return release, nil
+}
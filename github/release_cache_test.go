@@ -0,0 +1,96 @@
+package github
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func testRelease(t *testing.T, tag string, assetNames ...string) Release {
+	t.Helper()
+
+	assets := []map[string]string{}
+	for _, name := range assetNames {
+		assets = append(assets, map[string]string{"name": name})
+	}
+	content, err := json.Marshal(map[string]any{"tag_name": tag, "assets": assets})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	release := Release{}
+	if err := json.Unmarshal(content, &release); err != nil {
+		t.Fatal(err)
+	}
+
+	return release
+}
+
+func TestReleaseCacheFetchesReleaseOnce(t *testing.T) {
+	fetches := map[string]int{}
+	failures := 1
+	cache := NewReleaseCache(func(repository, tag string) (Release, error) {
+		fetches[repository+"@"+tag]++
+		if tag == "v0.0.1" && failures > 0 {
+			failures--
+
+			return Release{}, errors.New("rate limit exceeded")
+		}
+
+		return testRelease(t, tag), nil
+	})
+
+	tests := []struct {
+		name    string
+		tag     string
+		wantErr bool
+		fetches int
+	}{
+		{name: "first request is fetched", tag: "v1.0.0", fetches: 1},
+		{name: "second request is cached", tag: "v1.0.0", fetches: 1},
+		{name: "other tag is fetched", tag: "v1.0.1", fetches: 1},
+		{name: "failed request", tag: "v0.0.1", wantErr: true, fetches: 1},
+		{name: "failed request is not cached", tag: "v0.0.1", fetches: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			release, err := cache.Release("vegaprotocol/vega", tt.tag)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error: %t, got %v", tt.wantErr, err)
+			}
+			if err == nil && release.TagName != tt.tag {
+				t.Errorf("expected release %s, got %s", tt.tag, release.TagName)
+			}
+			if got := fetches["vegaprotocol/vega@"+tt.tag]; got != tt.fetches {
+				t.Errorf("expected %d fetches, got %d", tt.fetches, got)
+			}
+		})
+	}
+}
+
+func TestValidatePlatformUsesReleaseCache(t *testing.T) {
+	const version = "v1.0.0"
+	platform := Platform{OS: "linux", Arch: "amd64"}
+	source := ReleaseSource{Repository: "vegaprotocol/vega"}
+
+	fetches := 0
+	SetReleaseCache(NewReleaseCache(func(repository, tag string) (Release, error) {
+		fetches++
+
+		return testRelease(t, tag, source.ArtifactName(ArtifactVega, platform, version), "vega-darwin-arm64.zip"), nil
+	}))
+	t.Cleanup(func() { SetReleaseCache(NewReleaseCache(fetchRelease)) })
+
+	if err := ValidatePlatform(source, version, ArtifactVega, platform); err != nil {
+		t.Errorf("unexpected error for the vega asset: %s", err)
+	}
+	err := ValidatePlatform(source, version, ArtifactVisor, platform)
+	if !errors.Is(err, ErrAssetNotPublished) {
+		t.Errorf("expected ErrAssetNotPublished for the visor asset, got %v", err)
+	}
+
+	if fetches != 1 {
+		t.Errorf("expected single release request for both artifacts, got %d", fetches)
+	}
+}
@@ -0,0 +1,224 @@
+package github
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+
+	"github.com/daniel1302/vega-assistant/utils"
+)
+
+const apiURL = "https://api.github.com"
+
+// LatestVersion is the version alias resolved to the latest release tag
+const LatestVersion = "latest"
+
+type Release struct {
+	TagName     string    `json:"tag_name"`
+	Name        string    `json:"name"`
+	Draft       bool      `json:"draft"`
+	Prerelease  bool      `json:"prerelease"`
+	PublishedAt time.Time `json:"published_at"`
+	Assets      []struct {
+		Name string `json:"name"`
+	} `json:"assets"`
+}
+
+// LatestReleaseTag returns tag of the newest release that is not a draft nor a prerelease
+func LatestReleaseTag(repository string) (string, error) {
+	release := Release{}
+	if err := apiGet(fmt.Sprintf("%s/repos/%s/releases/latest", apiURL, repository), &release); err != nil {
+		return "", fmt.Errorf("failed to get latest release for %s: %w", repository, err)
+	}
+
+	if release.Draft || release.Prerelease {
+		return "", fmt.Errorf("latest release %s for %s is a draft or a prerelease", release.TagName, repository)
+	}
+
+	if release.TagName == "" {
+		return "", fmt.Errorf("latest release for %s has no tag", repository)
+	}
+
+	return release.TagName, nil
+}
+
+// releasesPageSize is the maximum number of releases GitHub returns per page
+const releasesPageSize = 100
+
+// ListReleases returns up to limit recent releases, newest first. Drafts are skipped.
+func ListReleases(repository string, limit int) ([]Release, error) {
+	return listReleases(repository, limit, func(release Release) bool { return true })
+}
+
+// ListStableReleases returns up to limit recent releases, newest first. Drafts and prereleases
+// are skipped.
+func ListStableReleases(repository string, limit int) ([]Release, error) {
+	return listReleases(repository, limit, func(release Release) bool { return !release.Prerelease })
+}
+
+func listReleases(repository string, limit int, include func(Release) bool) ([]Release, error) {
+	if limit < 1 {
+		return nil, fmt.Errorf("invalid releases limit %d: must be at least 1", limit)
+	}
+
+	result := []Release{}
+	for page := 1; len(result) < limit; page++ {
+		releases := []Release{}
+		endpoint := fmt.Sprintf("%s/repos/%s/releases?per_page=%d&page=%d", apiURL, repository, releasesPageSize, page)
+		if err := apiGet(endpoint, &releases); err != nil {
+			return nil, fmt.Errorf("failed to list releases for %s: %w", repository, err)
+		}
+
+		for _, release := range releases {
+			if release.Draft || !include(release) {
+				continue
+			}
+
+			result = append(result, release)
+			if len(result) == limit {
+				break
+			}
+		}
+
+		if len(releases) < releasesPageSize {
+			break
+		}
+	}
+
+	return result, nil
+}
+
+// HasAsset returns true when the release publishes the asset, see the ReleaseSource.ArtifactName
+func (r Release) HasAsset(assetName string) bool {
+	for _, asset := range r.Assets {
+		if asset.Name == assetName {
+			return true
+		}
+	}
+
+	return false
+}
+
+func apiGet(endpoint string, result any) error {
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request for %s: %w", endpoint, err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	setAuthHeaders(req)
+
+	resp, err := utils.DoHTTPRequest(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request to %s: %w", endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	if err := rateLimitError(resp.StatusCode, resp.Header); err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("bad http status for %s: %s", endpoint, resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body for %s: %w", endpoint, err)
+	}
+
+	if err := json.Unmarshal(body, result); err != nil {
+		return fmt.Errorf("failed to unmarshal response for %s: %w", endpoint, err)
+	}
+
+	return nil
+}
+
+// ErrAssetNotPublished is returned when the release exists, but it does not contain the asset
+var ErrAssetNotPublished = errors.New("asset not published")
+
+// ValidatePlatform checks the release publishes the artifact asset for the platform. The error lists
+// archive assets published in the release, so user can pick the available platform.
+func ValidatePlatform(source ReleaseSource, version string, artifactType ArtifactType, platform Platform) error {
+	release, err := currentReleaseCache().Release(source.Repository, version)
+	if err != nil {
+		return fmt.Errorf("failed to get release %s for %s: %w", version, source.Repository, err)
+	}
+
+	artifactName := source.ArtifactName(artifactType, platform, version)
+	availableAssets := []string{}
+	for _, asset := range release.Assets {
+		if asset.Name == artifactName {
+			return nil
+		}
+
+		if utils.IsSupportedArchive(asset.Name) {
+			availableAssets = append(availableAssets, asset.Name)
+		}
+	}
+
+	return fmt.Errorf(
+		"%w: release %s does not publish the %s asset: available assets are %v",
+		ErrAssetNotPublished,
+		version,
+		artifactName,
+		availableAssets,
+	)
+}
+
+// constraintReleasesLimit is the number of recent stable releases checked against the version constraint
+const constraintReleasesLimit = 300
+
+// ValidateVersionConstraint checks the semver constraint, e.g. `>=0.74, <0.75`
+func ValidateVersionConstraint(constraint string) error {
+	if _, err := semver.NewConstraint(constraint); err != nil {
+		return fmt.Errorf("invalid version constraint %q: %w", constraint, err)
+	}
+
+	return nil
+}
+
+// ResolveVersionConstraint returns tag of the highest stable release satisfying the semver constraint,
+// e.g. `>=0.74, <0.75`. Tags that are not semantic versions are skipped.
+func ResolveVersionConstraint(repository, constraint string) (string, error) {
+	versionConstraint, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return "", fmt.Errorf("invalid version constraint %q: %w", constraint, err)
+	}
+
+	releases, err := ListStableReleases(repository, constraintReleasesLimit)
+	if err != nil {
+		return "", err
+	}
+
+	var (
+		highestVersion *semver.Version
+		highestTag     string
+	)
+	for _, release := range releases {
+		version, err := semver.NewVersion(release.TagName)
+		if err != nil || version.Prerelease() != "" || !versionConstraint.Check(version) {
+			continue
+		}
+
+		if highestVersion == nil || version.GreaterThan(highestVersion) {
+			highestVersion = version
+			highestTag = release.TagName
+		}
+	}
+
+	if highestVersion == nil {
+		return "", fmt.Errorf(
+			"no stable release of %s satisfies the version constraint %q in the last %d releases",
+			repository,
+			constraint,
+			constraintReleasesLimit,
+		)
+	}
+
+	return highestTag, nil
+}
@@ -0,0 +1,35 @@
+package github
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidatePlatformListsAvailableAssets(t *testing.T) {
+	const version = "v0.70.0"
+	source := ReleaseSource{Repository: "vegaprotocol/vega"}
+	assetNames := []string{"vega-linux-amd64.zip", "vega-darwin-amd64.zip", "visor-linux-amd64.zip"}
+
+	SetReleaseCache(NewReleaseCache(func(repository, tag string) (Release, error) {
+		return testRelease(t, tag, append([]string{"checksums.txt"}, assetNames...)...), nil
+	}))
+	t.Cleanup(func() { SetReleaseCache(NewReleaseCache(fetchRelease)) })
+
+	err := ValidatePlatform(source, version, ArtifactVega, Platform{OS: "linux", Arch: "arm64"})
+	if !errors.Is(err, ErrAssetNotPublished) {
+		t.Fatalf("expected ErrAssetNotPublished for the arm64 asset, got %v", err)
+	}
+
+	for _, assetName := range assetNames {
+		if !strings.Contains(err.Error(), assetName) {
+			t.Errorf("expected error to list the %s asset, got %s", assetName, err)
+		}
+	}
+	if strings.Contains(err.Error(), "checksums.txt") {
+		t.Errorf("expected error to list only archive assets, got %s", err)
+	}
+	if !strings.Contains(err.Error(), "vega-linux-arm64.zip") {
+		t.Errorf("expected error to name the missing asset, got %s", err)
+	}
+}
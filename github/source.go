@@ -0,0 +1,67 @@
+package github
+
+import "fmt"
+
+// ReleaseSource describes where the artifacts are published: the GitHub repository, the optional binary
+// mirror tried before the GitHub release, e.g. for networks publishing the binaries on their own CDN, and
+// the asset layouts overriding the official ones, e.g. for forks publishing differently named assets.
+type ReleaseSource struct {
+	Repository string
+	Mirror     MirrorURLTemplate
+	Layouts    map[ArtifactType]AssetLayout
+}
+
+// Validate checks the binary mirror and the asset layouts
+func (s ReleaseSource) Validate() error {
+	if s.Mirror != "" {
+		if err := s.Mirror.Validate(); err != nil {
+			return fmt.Errorf("invalid binary mirror url: %w", err)
+		}
+	}
+
+	for _, artifactType := range []ArtifactType{ArtifactVega, ArtifactVisor} {
+		layout, ok := s.Layouts[artifactType]
+		if !ok {
+			continue
+		}
+
+		if err := layout.Validate(); err != nil {
+			return fmt.Errorf("invalid %s asset layout: %w", artifactType, err)
+		}
+	}
+
+	return nil
+}
+
+// Layout returns the asset layout of the artifact. The official layout is used when the source does
+// not override it.
+func (s ReleaseSource) Layout(artifactType ArtifactType) AssetLayout {
+	if layout, ok := s.Layouts[artifactType]; ok {
+		return layout
+	}
+
+	return DefaultAssetLayout(artifactType)
+}
+
+// ArtifactName returns name of the release asset for the platform rendered from the asset layout
+func (s ReleaseSource) ArtifactName(artifactType ArtifactType, platform Platform, version string) string {
+	name, err := s.Layout(artifactType).assetName(artifactType, platform, version)
+	if err != nil {
+		// Layout is validated with the source, so only the default layout is left
+		name, _ = DefaultAssetLayout(artifactType).assetName(artifactType, platform, version)
+	}
+
+	return name
+}
+
+// ArtifactBinaryName returns name of the binary extracted from the artifact asset
+func (s ReleaseSource) ArtifactBinaryName(artifactType ArtifactType) string {
+	return s.Layout(artifactType).BinaryName
+}
+
+// withoutMirror returns the source downloading the artifacts from the GitHub release only
+func (s ReleaseSource) withoutMirror() ReleaseSource {
+	s.Mirror = ""
+
+	return s
+}
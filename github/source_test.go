@@ -0,0 +1,73 @@
+package github
+
+import "testing"
+
+func TestReleaseSourceArtifactName(t *testing.T) {
+	platform := Platform{OS: "linux", Arch: "arm64"}
+	tests := []struct {
+		name       string
+		source     ReleaseSource
+		artifact   ArtifactType
+		expected   string
+		binaryName string
+	}{
+		{name: "official layout", source: ReleaseSource{}, artifact: ArtifactVega, expected: "vega-linux-arm64.zip", binaryName: "vega"},
+		{
+			name: "overridden layout",
+			source: ReleaseSource{Layouts: map[ArtifactType]AssetLayout{
+				ArtifactVega: {NameTemplate: "fork-{{.Version}}-{{.OS}}-{{.Arch}}.tar.gz", BinaryName: "fork"},
+			}},
+			artifact:   ArtifactVega,
+			expected:   "fork-v1.0.0-linux-arm64.tar.gz",
+			binaryName: "fork",
+		},
+		{
+			name: "layout of other artifact",
+			source: ReleaseSource{Layouts: map[ArtifactType]AssetLayout{
+				ArtifactVega: {NameTemplate: "fork-{{.OS}}-{{.Arch}}.zip", BinaryName: "fork"},
+			}},
+			artifact:   ArtifactVisor,
+			expected:   "visor-linux-arm64.zip",
+			binaryName: "visor",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.source.ArtifactName(tt.artifact, platform, "v1.0.0"); got != tt.expected {
+				t.Errorf("expected asset name %s, got %s", tt.expected, got)
+			}
+			if got := tt.source.ArtifactBinaryName(tt.artifact); got != tt.binaryName {
+				t.Errorf("expected binary name %s, got %s", tt.binaryName, got)
+			}
+		})
+	}
+}
+
+func TestReleaseSourceValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  ReleaseSource
+		wantErr bool
+	}{
+		{name: "github only", source: ReleaseSource{Repository: "vegaprotocol/vega"}},
+		{name: "mirror", source: ReleaseSource{Mirror: "https://cdn.example.com/{{.Version}}/{{.Asset}}"}},
+		{name: "invalid mirror", source: ReleaseSource{Mirror: "ftp://cdn.example.com/{{.Asset}}"}, wantErr: true},
+		{
+			name: "invalid layout",
+			source: ReleaseSource{Layouts: map[ArtifactType]AssetLayout{
+				ArtifactVega: {NameTemplate: "vega.exe", BinaryName: "vega"},
+			}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.source.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected error: %t, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
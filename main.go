@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
@@ -15,6 +16,11 @@ func init() {
 func main() {
 	if err := cmd.RootCmd.Execute(); err != nil {
 		fmt.Println(err.Error())
+
+		exitErr := &setup.ExitError{}
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.Code)
+		}
 		os.Exit(1)
 	}
 }
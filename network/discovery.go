@@ -0,0 +1,265 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+
+	"github.com/daniel1302/vega-assistant/types"
+	"github.com/daniel1302/vega-assistant/utils"
+)
+
+const (
+	discoveryTimeout         = 5 * time.Second
+	defaultTendermintRPCPort = "26657"
+	defaultTendermintP2PPort = "26656"
+)
+
+var (
+	discoveryCacheMu sync.Mutex
+	discoveryCache   = map[string]NetworkConfig{}
+)
+
+type tendermintStatus struct {
+	Result struct {
+		NodeInfo struct {
+			ID         string `json:"id"`
+			ListenAddr string `json:"listen_addr"`
+			Network    string `json:"network"`
+		} `json:"node_info"`
+		SyncInfo struct {
+			LatestBlockHeight string `json:"latest_block_height"`
+		} `json:"sync_info"`
+	} `json:"result"`
+}
+
+type networkHistoryBootstrapPeers struct {
+	BootstrapPeers []string `json:"bootstrapPeers"`
+}
+
+// FetchNetworkConfigFromREST builds the network peers list based on the running
+// data-nodes. For every REST endpoint, tendermint RPC is expected on the same
+// host with the default port. Endpoints are tried in order until one of them
+// succeeds. Nodes serving a chain other than chainID are skipped. When chainID
+// is empty, the chain run by the majority of the nodes is used. Only peers
+// related fields and the chain id are populated in the returned config. The
+// successful response is cached for given list of endpoints and the chain id.
+func FetchNetworkConfigFromREST(restURLs []string, chainID string) (NetworkConfig, error) {
+	if len(restURLs) < 1 {
+		return NetworkConfig{}, fmt.Errorf("at least one rest url is required to discover network config")
+	}
+
+	cacheKey := fmt.Sprintf("%s/%s", strings.Join(restURLs, ","), chainID)
+	discoveryCacheMu.Lock()
+	cachedConfig, cached := discoveryCache[cacheKey]
+	discoveryCacheMu.Unlock()
+	if cached {
+		return cachedConfig, nil
+	}
+
+	var resErr error
+	if chainID == "" {
+		majorityChainID, err := discoverMajorityChainID(restURLs)
+		if err != nil {
+			return NetworkConfig{}, fmt.Errorf("failed to discover chain id of the network: %w", err)
+		}
+		chainID = majorityChainID
+	}
+
+	for _, restURL := range restURLs {
+		ctx, cancel := context.WithTimeout(context.Background(), discoveryTimeout)
+		result, err := discoverNode(ctx, restURL, chainID)
+		cancel()
+
+		if err != nil {
+			resErr = multierror.Append(resErr, fmt.Errorf("failed to discover node for %s: %w", restURL, err))
+			continue
+		}
+
+		discoveryCacheMu.Lock()
+		discoveryCache[cacheKey] = result
+		discoveryCacheMu.Unlock()
+
+		return result, nil
+	}
+
+	return NetworkConfig{}, fmt.Errorf("failed to discover network config from any rest url: %w", resErr)
+}
+
+// discoverMajorityChainID returns the chain id served by most of the tendermint nodes running next to the
+// data-nodes, so a single node still running the chain from before the network reset does not win.
+func discoverMajorityChainID(restURLs []string) (string, error) {
+	votes := map[string]int{}
+	majorityChainID := ""
+
+	var resErr error
+	for _, restURL := range restURLs {
+		ctx, cancel := context.WithTimeout(context.Background(), discoveryTimeout)
+		status, err := fetchTendermintStatus(ctx, restURL)
+		cancel()
+
+		if err != nil {
+			resErr = multierror.Append(resErr, fmt.Errorf("failed to get chain id for %s: %w", restURL, err))
+			continue
+		}
+
+		chainID := status.Result.NodeInfo.Network
+		votes[chainID]++
+		if votes[chainID] > votes[majorityChainID] {
+			majorityChainID = chainID
+		}
+	}
+
+	if majorityChainID == "" {
+		return "", fmt.Errorf("no node reported the chain id: %w", resErr)
+	}
+
+	return majorityChainID, nil
+}
+
+func fetchTendermintStatus(ctx context.Context, restURL string) (tendermintStatus, error) {
+	host, err := restURLHost(restURL)
+	if err != nil {
+		return tendermintStatus{}, err
+	}
+
+	status := tendermintStatus{}
+	rpcEndpoint := fmt.Sprintf("%s:%s", host, defaultTendermintRPCPort)
+	if err := getJSON(ctx, fmt.Sprintf("http://%s/status", rpcEndpoint), &status); err != nil {
+		return tendermintStatus{}, fmt.Errorf("failed to get tendermint status: %w", err)
+	}
+
+	return status, nil
+}
+
+func restURLHost(restURL string) (string, error) {
+	parsedURL, err := url.Parse(strings.TrimRight(restURL, "/"))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse rest url: %w", err)
+	}
+	host := parsedURL.Hostname()
+	if host == "" {
+		return "", fmt.Errorf("rest url must contain host")
+	}
+
+	return host, nil
+}
+
+func discoverNode(ctx context.Context, restURL, chainID string) (NetworkConfig, error) {
+	restURL = strings.TrimRight(restURL, "/")
+	host, err := restURLHost(restURL)
+	if err != nil {
+		return NetworkConfig{}, err
+	}
+
+	// Make sure the given url serves the data-node API
+	networkParameters := map[string]interface{}{}
+	if err := getJSON(ctx, fmt.Sprintf("%s/api/v2/network/parameters", restURL), &networkParameters); err != nil {
+		return NetworkConfig{}, fmt.Errorf("failed to get network parameters: %w", err)
+	}
+
+	bootstrapPeers := networkHistoryBootstrapPeers{}
+	if err := getJSON(ctx, fmt.Sprintf("%s/api/v2/networkhistory/bootstrap", restURL), &bootstrapPeers); err != nil {
+		return NetworkConfig{}, fmt.Errorf("failed to get network history bootstrap peers: %w", err)
+	}
+
+	status, err := fetchTendermintStatus(ctx, restURL)
+	if err != nil {
+		return NetworkConfig{}, err
+	}
+	if status.Result.NodeInfo.ID == "" {
+		return NetworkConfig{}, fmt.Errorf("tendermint status does not contain node id")
+	}
+	// Nodes of the reset network may still run the old chain
+	if status.Result.NodeInfo.Network != chainID {
+		return NetworkConfig{}, fmt.Errorf(
+			"%w: node runs chain %s, expected chain %s",
+			ErrChainIDMismatch,
+			status.Result.NodeInfo.Network,
+			chainID,
+		)
+	}
+
+	p2pPort := defaultTendermintP2PPort
+	if listenAddrIdx := strings.LastIndex(status.Result.NodeInfo.ListenAddr, ":"); listenAddrIdx > -1 {
+		p2pPort = status.Result.NodeInfo.ListenAddr[listenAddrIdx+1:]
+	}
+
+	config := NetworkConfig{
+		ChainID:           chainID,
+		DataNodesRESTUrls: []string{restURL},
+		TendermintSeeds:   []string{fmt.Sprintf("%s@%s:%s", status.Result.NodeInfo.ID, host, p2pPort)},
+		TendermintRPCServers: []types.EndpointWithVegaREST{
+			{REST: restURL, Endpoint: fmt.Sprintf("%s:%s", host, defaultTendermintRPCPort)},
+		},
+		BootstrapPeers: []types.EndpointWithVegaREST{},
+	}
+	for _, peer := range bootstrapPeers.BootstrapPeers {
+		config.BootstrapPeers = append(
+			config.BootstrapPeers,
+			types.EndpointWithVegaREST{REST: restURL, Endpoint: peer},
+		)
+	}
+
+	return config, nil
+}
+
+func getJSON(ctx context.Context, endpoint string, result any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request for %s: %w", endpoint, err)
+	}
+
+	resp, err := utils.DoHTTPRequest(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request to %s: %w", endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("invalid response code for %s: expected %d, got %d", endpoint, http.StatusOK, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body for %s: %w", endpoint, err)
+	}
+
+	if err := json.Unmarshal(body, result); err != nil {
+		return fmt.Errorf("failed to unmarshal response for %s: %w", endpoint, err)
+	}
+
+	return nil
+}
+
+// WithDiscoveredPeers replaces the tendermint seeds and the bootstrap peers with
+// the discovered ones. Discovered RPC servers are added to the configured ones,
+// because only one node is discovered. Fields not discovered are left untouched.
+// The discovered chain id is used only when the config does not define it.
+func (config NetworkConfig) WithDiscoveredPeers(discovered NetworkConfig) NetworkConfig {
+	if config.ChainID == "" {
+		config.ChainID = discovered.ChainID
+	}
+	if len(discovered.TendermintSeeds) > 0 {
+		config.TendermintSeeds = discovered.TendermintSeeds
+	}
+	for _, rpcServer := range discovered.TendermintRPCServers {
+		if !slices.Contains(config.TendermintRPCServers, rpcServer) {
+			config.TendermintRPCServers = append(slices.Clone(config.TendermintRPCServers), rpcServer)
+		}
+	}
+	if len(discovered.BootstrapPeers) > 0 {
+		config.BootstrapPeers = discovered.BootstrapPeers
+	}
+
+	return config
+}
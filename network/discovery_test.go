@@ -0,0 +1,182 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/daniel1302/vega-assistant/utils"
+)
+
+type fakeNode struct {
+	nodeID  string
+	chainID string
+	broken  bool
+}
+
+// startFakeNetwork serves the data-node REST API and the tendermint RPC of the nodes through the proxy, so
+// the nodes can run on the hosts and the ports expected by the discovery. It returns requested urls.
+func startFakeNetwork(t *testing.T, nodes map[string]fakeNode) func() []string {
+	t.Helper()
+
+	var (
+		mu        sync.Mutex
+		requested []string
+	)
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requested = append(requested, r.URL.String())
+		mu.Unlock()
+
+		node, ok := nodes[r.URL.Hostname()]
+		if !ok || node.broken {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/api/v2/network/parameters":
+			w.Write([]byte(`{"networkParameters": {}}`))
+		case "/api/v2/networkhistory/bootstrap":
+			fmt.Fprintf(w, `{"bootstrapPeers": ["/dns/%s/tcp/4001/ipfs/%s"]}`, r.URL.Hostname(), node.nodeID)
+		case "/status":
+			fmt.Fprintf(
+				w,
+				`{"result": {"node_info": {"id": %q, "listen_addr": "tcp://0.0.0.0:26656", "network": %q}}}`,
+				node.nodeID,
+				node.chainID,
+			)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(proxy.Close)
+
+	if err := utils.SetProxy(proxy.URL); err != nil {
+		t.Fatalf("failed to set proxy: %s", err)
+	}
+	t.Cleanup(func() { utils.SetProxy("") })
+
+	return func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return append([]string{}, requested...)
+	}
+}
+
+func TestFetchNetworkConfigFromRESTFallback(t *testing.T) {
+	requested := startFakeNetwork(t, map[string]fakeNode{
+		"n01.fallback.example": {broken: true},
+		"n02.fallback.example": {nodeID: "node2", chainID: "testnet-1"},
+		"n03.fallback.example": {nodeID: "node3", chainID: "testnet-1"},
+	})
+
+	config, err := FetchNetworkConfigFromREST([]string{
+		"http://n01.fallback.example",
+		"http://n02.fallback.example",
+		"http://n03.fallback.example",
+	}, "testnet-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if strings.Join(config.TendermintSeeds, ",") != "node2@n02.fallback.example:26656" {
+		t.Errorf("expected seed of the second node, got %v", config.TendermintSeeds)
+	}
+	if len(config.BootstrapPeers) != 1 || config.BootstrapPeers[0].Endpoint != "/dns/n02.fallback.example/tcp/4001/ipfs/node2" {
+		t.Errorf("expected bootstrap peer of the second node, got %v", config.BootstrapPeers)
+	}
+	for _, url := range requested() {
+		if strings.Contains(url, "n03.fallback.example") {
+			t.Errorf("expected the third node not to be queried after the second one succeeded, got %s", url)
+		}
+	}
+}
+
+func TestFetchNetworkConfigFromRESTChainMismatch(t *testing.T) {
+	startFakeNetwork(t, map[string]fakeNode{
+		"n01.reset.example": {nodeID: "node1", chainID: "testnet-old"},
+		"n02.reset.example": {nodeID: "node2", chainID: "testnet-new"},
+		"n03.reset.example": {nodeID: "node3", chainID: "testnet-new"},
+	})
+	restURLs := []string{"http://n01.reset.example", "http://n02.reset.example", "http://n03.reset.example"}
+
+	tests := []struct {
+		name         string
+		restURLs     []string
+		chainID      string
+		expectedSeed string
+		wantErr      error
+	}{
+		{
+			name:         "stale node is skipped for the configured chain id",
+			restURLs:     restURLs,
+			chainID:      "testnet-new",
+			expectedSeed: "node2@n02.reset.example:26656",
+		},
+		{
+			name:         "majority chain id is used when chain id is not configured",
+			restURLs:     restURLs,
+			expectedSeed: "node2@n02.reset.example:26656",
+		},
+		{
+			name:     "no node serves the configured chain id",
+			restURLs: restURLs,
+			chainID:  "mainnet-1",
+			wantErr:  ErrChainIDMismatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := FetchNetworkConfigFromREST(tt.restURLs, tt.chainID)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v error, got %v", tt.wantErr, err)
+				}
+
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if config.ChainID != "testnet-new" {
+				t.Errorf("expected chain id testnet-new, got %s", config.ChainID)
+			}
+			if strings.Join(config.TendermintSeeds, ",") != tt.expectedSeed {
+				t.Errorf("expected seed %s, got %v", tt.expectedSeed, config.TendermintSeeds)
+			}
+		})
+	}
+}
+
+func TestFetchNetworkConfigFromRESTCache(t *testing.T) {
+	requested := startFakeNetwork(t, map[string]fakeNode{
+		"n01.cache.example": {nodeID: "node1", chainID: "testnet-1"},
+	})
+	restURLs := []string{"http://n01.cache.example"}
+
+	first, err := FetchNetworkConfigFromREST(restURLs, "testnet-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	requestsCount := len(requested())
+
+	second, err := FetchNetworkConfigFromREST(restURLs, "testnet-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(requested()) != requestsCount {
+		t.Errorf("expected cached config to be returned without requests, got %v", requested()[requestsCount:])
+	}
+	if strings.Join(first.TendermintSeeds, ",") != strings.Join(second.TendermintSeeds, ",") {
+		t.Errorf("expected cached seeds %v, got %v", first.TendermintSeeds, second.TendermintSeeds)
+	}
+}
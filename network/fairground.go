@@ -0,0 +1,32 @@
+package network
+
+import "github.com/daniel1302/vega-assistant/types"
+
+// FairgroundConfig returns config for the public Vega testnet.
+//
+// The fairground network is reset from time to time and node identities and the
+// chain id change with every reset, therefore tendermint seeds, network-history
+// bootstrap peers and the chain id are not hardcoded here. They are discovered
+// from the data-nodes during the setup.
+func FairgroundConfig() NetworkConfig {
+	return NetworkConfig{
+		GenesisVersion:     "v0.76.8",
+		LowestVisorVersion: "v0.76.8",
+		Repository:         "vegaprotocol/vega",
+		GenesisURLs:        []string{"https://raw.githubusercontent.com/vegaprotocol/networks-internal/main/fairground/genesis.json"},
+		DataNodesRESTUrls: []string{
+			"https://api.n07.testnet.vega.rocks",
+			"https://api.n08.testnet.vega.rocks",
+			"https://api.n09.testnet.vega.rocks",
+		},
+		TendermintSeeds: []string{},
+		TendermintRPCServers: []types.EndpointWithVegaREST{
+			{REST: "https://api.n07.testnet.vega.rocks", Endpoint: "n07.testnet.vega.rocks:26657"},
+			{REST: "https://api.n08.testnet.vega.rocks", Endpoint: "n08.testnet.vega.rocks:26657"},
+			{REST: "https://api.n09.testnet.vega.rocks", Endpoint: "n09.testnet.vega.rocks:26657"},
+		},
+		BootstrapPeers:            []types.EndpointWithVegaREST{},
+		TendermintPersistentPeers: []string{},
+		BinariesOverride:          []BinaryOverride{},
+	}
+}
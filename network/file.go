@@ -0,0 +1,182 @@
+package network
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/pelletier/go-toml"
+	"golang.org/x/mod/semver"
+
+	"github.com/daniel1302/vega-assistant/github"
+	"github.com/daniel1302/vega-assistant/utils"
+)
+
+var (
+	repositoryRegexp    = regexp.MustCompile(`^[A-Za-z0-9_.-]+/[A-Za-z0-9_.-]+$`)
+	bootstrapPeerRegexp = regexp.MustCompile(`^/(dns|dns4|dns6|ip4|ip6)/[^/]+/tcp/[0-9]+/(ipfs|p2p)/[A-Za-z0-9]+$`)
+)
+
+// LoadNetworkConfig reads the network config from the TOML or JSON file. Format
+// is selected based on the file extension, TOML is used when extension is unknown.
+func LoadNetworkConfig(filePath string) (NetworkConfig, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return NetworkConfig{}, fmt.Errorf("failed to read network config file %s: %w", filePath, err)
+	}
+
+	result := NetworkConfig{}
+	if strings.ToLower(filepath.Ext(filePath)) == ".json" {
+		if err := json.Unmarshal(content, &result); err != nil {
+			return NetworkConfig{}, fmt.Errorf("failed to unmarshal json network config: %w", err)
+		}
+	} else {
+		if err := toml.Unmarshal(content, &result); err != nil {
+			return NetworkConfig{}, fmt.Errorf("failed to unmarshal toml network config: %w", err)
+		}
+	}
+
+	if err := result.Validate(); err != nil {
+		return NetworkConfig{}, fmt.Errorf("invalid network config in %s: %w", filePath, err)
+	}
+
+	return result, nil
+}
+
+// Validate checks the network config loaded from the file. All problems are reported together, so the
+// hand-written config can be fixed in one go.
+func (config NetworkConfig) Validate() error {
+	missingFields := []string{}
+
+	if len(config.GenesisSources()) < 1 {
+		missingFields = append(missingFields, "genesis-urls")
+	}
+	if config.Repository == "" {
+		missingFields = append(missingFields, "repository")
+	}
+	if len(config.DataNodesRESTUrls) < 1 {
+		missingFields = append(missingFields, "data-nodes-rest-urls")
+	}
+	// The tendermint seeds and the bootstrap peers are discovered from the data-nodes when missing, see
+	// RequiresPeersDiscovery. Without the data-nodes there is nothing to discover them from.
+	if len(config.DataNodesRESTUrls) < 1 && len(config.TendermintSeeds) < 1 {
+		missingFields = append(missingFields, "tendermint-seeds")
+	}
+	if len(config.TendermintRPCServers) < 1 {
+		missingFields = append(missingFields, "tendermint-rpc-servers")
+	}
+	if len(config.DataNodesRESTUrls) < 1 && len(config.BootstrapPeers) < 1 {
+		missingFields = append(missingFields, "bootstrap-peers")
+	}
+
+	var resErr error
+	if len(missingFields) > 0 {
+		resErr = multierror.Append(resErr, fmt.Errorf("missing required fields: %s", strings.Join(missingFields, ", ")))
+	}
+
+	for _, genesisURL := range config.GenesisSources() {
+		if err := validateHTTPURL(genesisURL); err != nil {
+			resErr = multierror.Append(resErr, fmt.Errorf("invalid genesis-urls entry: %w", err))
+		}
+	}
+
+	if config.Repository != "" && !repositoryRegexp.MatchString(config.Repository) {
+		resErr = multierror.Append(resErr, fmt.Errorf("invalid repository(%s): expected <owner>/<repo>", config.Repository))
+	}
+
+	for _, restURL := range config.DataNodesRESTUrls {
+		if err := validateHTTPURL(restURL); err != nil {
+			resErr = multierror.Append(resErr, fmt.Errorf("invalid data-nodes-rest-urls entry: %w", err))
+		}
+	}
+
+	if err := config.ValidatePeers(); err != nil {
+		resErr = multierror.Append(resErr, err)
+	}
+
+	for _, peer := range config.BootstrapPeers {
+		if err := validateBootstrapPeer(peer.Endpoint); err != nil {
+			resErr = multierror.Append(resErr, fmt.Errorf("invalid bootstrap-peers entry: %w", err))
+		}
+	}
+
+	for _, endpoint := range append(slices.Clone(config.TendermintRPCServers), config.BootstrapPeers...) {
+		if endpoint.REST == "" {
+			continue
+		}
+
+		if err := validateHTTPURL(endpoint.REST); err != nil {
+			resErr = multierror.Append(resErr, fmt.Errorf("invalid rest url for the %s endpoint: %w", endpoint.Endpoint, err))
+		}
+	}
+
+	versions := map[string]string{
+		"genesis-version":      config.GenesisVersion,
+		"lowest-visor-version": config.LowestVisorVersion,
+	}
+	for _, field := range []string{"genesis-version", "lowest-visor-version"} {
+		if version := versions[field]; version != "" && !semver.IsValid(version) {
+			resErr = multierror.Append(resErr, fmt.Errorf("invalid %s(%s): expected semantic version, e.g. v0.73.4", field, version))
+		}
+	}
+
+	for _, override := range config.BinariesOverride {
+		if !semver.IsValid(override.OldVersion) || !semver.IsValid(override.NewVersion) {
+			resErr = multierror.Append(resErr, fmt.Errorf(
+				"invalid binaries-override entry(%s -> %s): expected semantic versions",
+				override.OldVersion,
+				override.NewVersion,
+			))
+		}
+	}
+
+	if config.GenesisSHA256 != "" && !utils.IsSHA256(config.GenesisSHA256) {
+		resErr = multierror.Append(resErr, fmt.Errorf("invalid genesis-sha256(%s): expected hex encoded sha256 checksum", config.GenesisSHA256))
+	}
+
+	if err := config.VegaAssetLayout().Validate(); err != nil {
+		resErr = multierror.Append(resErr, fmt.Errorf("invalid asset-name-template or binary-name: %w", err))
+	}
+
+	if config.BinaryMirrorURL != "" {
+		if err := github.MirrorURLTemplate(config.BinaryMirrorURL).Validate(); err != nil {
+			resErr = multierror.Append(resErr, fmt.Errorf("invalid binary-mirror-url: %w", err))
+		}
+	}
+
+	return resErr
+}
+
+// validateHTTPURL checks the url is absolute and uses the http or https scheme
+func validateHTTPURL(rawURL string) error {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid url %q: %w", rawURL, err)
+	}
+
+	if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
+		return fmt.Errorf("invalid url %q: expected http or https scheme", rawURL)
+	}
+
+	if parsedURL.Host == "" {
+		return fmt.Errorf("invalid url %q: missing host", rawURL)
+	}
+
+	return nil
+}
+
+// validateBootstrapPeer checks the network history peer is the multiaddr with the peer id, e.g.
+// `/dns/<host>/tcp/<port>/ipfs/<peer-id>`
+func validateBootstrapPeer(addr string) error {
+	if !bootstrapPeerRegexp.MatchString(addr) {
+		return fmt.Errorf("invalid peer address %q: expected /<dns|ip4|ip6>/<host>/tcp/<port>/<ipfs|p2p>/<peer-id>", addr)
+	}
+
+	return nil
+}
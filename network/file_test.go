@@ -0,0 +1,133 @@
+package network
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pelletier/go-toml"
+
+	"github.com/daniel1302/vega-assistant/types"
+)
+
+func TestLoadNetworkConfigRoundTrip(t *testing.T) {
+	expected := NetworkConfig{
+		GenesisVersion:    "v0.73.4",
+		ChainID:           "vega-private-0001",
+		Repository:        "example/vega-fork",
+		AssetNameTemplate: "{{.Artifact}}-{{.OS}}-{{.Arch}}.tar.gz",
+		BinaryName:        "vega-fork",
+		GenesisURLs: []string{
+			"https://example.com/genesis.json",
+			"https://mirror.example.com/genesis.json",
+		},
+		GenesisURL:         "https://old.example.com/genesis.json",
+		GenesisSHA256:      "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+		LowestVisorVersion: "v0.73.6",
+		DataNodesRESTUrls:  []string{"https://api0.example.com", "https://api1.example.com"},
+		TendermintSeeds:    []string{"b0db58f5651c85385f588bd5238b42bedbe57073@seed0.example.com:26656"},
+		BootstrapPeers: []types.EndpointWithVegaREST{
+			{REST: "https://api0.example.com", Endpoint: "/dns/api0.example.com/tcp/4001/ipfs/12D3KooWAHkKJfX7rt1pAuGebP9g2BGTT5w7peFGyWd2QbpyZwaw"},
+		},
+		TendermintRPCServers: []types.EndpointWithVegaREST{
+			{REST: "https://api0.example.com", Endpoint: "api0.example.com:26657"},
+			{REST: "https://api1.example.com", Endpoint: "api1.example.com:26657"},
+		},
+		TendermintPersistentPeers: []string{"abe207dae9367995526812d42207aeab73fd6418@peer0.example.com:26656"},
+		BinariesOverride: []BinaryOverride{
+			{OldVersion: "v0.75.8", NewVersion: "v0.75.8-fix.2", Block: 47865000},
+		},
+		BinaryMirrorURL: "https://mirror.example.com/{{.Version}}/{{.Asset}}",
+	}
+
+	tests := []struct {
+		fileName string
+		marshal  func(any) ([]byte, error)
+	}{
+		{fileName: "network.toml", marshal: toml.Marshal},
+		{fileName: "network.json", marshal: json.Marshal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fileName, func(t *testing.T) {
+			content, err := tt.marshal(expected)
+			if err != nil {
+				t.Fatalf("failed to marshal network config: %s", err)
+			}
+
+			filePath := filepath.Join(t.TempDir(), tt.fileName)
+			if err := os.WriteFile(filePath, content, 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			config, err := LoadNetworkConfig(filePath)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !reflect.DeepEqual(config, expected) {
+				t.Errorf("expected config %+v, got %+v", expected, config)
+			}
+		})
+	}
+}
+
+func TestLoadNetworkConfigWithoutDiscoverablePeers(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name: "seeds and bootstrap peers are discovered",
+			content: `genesis-urls = ["https://example.com/genesis.json"]
+repository = "vegaprotocol/vega"
+data-nodes-rest-urls = ["https://api.example.com"]
+tendermint-rpc-servers = [{endpoint = "tm.example.com:26657"}]
+`,
+		},
+		{
+			name: "rpc servers are required",
+			content: `genesis-urls = ["https://example.com/genesis.json"]
+repository = "vegaprotocol/vega"
+data-nodes-rest-urls = ["https://api.example.com"]
+`,
+			wantErr: "missing required fields: tendermint-rpc-servers",
+		},
+		{
+			name: "seeds and bootstrap peers are required without rest urls",
+			content: `genesis-urls = ["https://example.com/genesis.json"]
+repository = "vegaprotocol/vega"
+tendermint-rpc-servers = [{endpoint = "tm.example.com:26657"}]
+`,
+			wantErr: "missing required fields: data-nodes-rest-urls, tendermint-seeds, bootstrap-peers",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filePath := filepath.Join(t.TempDir(), "network.toml")
+			if err := os.WriteFile(filePath, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			config, err := LoadNetworkConfig(filePath)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected %q error, got %v", tt.wantErr, err)
+				}
+
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !config.RequiresPeersDiscovery() {
+				t.Error("expected the config to require the peers discovery")
+			}
+		})
+	}
+}
@@ -2,31 +2,23 @@ package network
 
 import "github.com/daniel1302/vega-assistant/types"
 
-type BinaryOverride struct {
-	OldVersion string
-	NewVersion string
-	Block      uint64
-}
-
-type NetworkConfig struct {
-	GenesisVersion            string
-	Repository                string
-	GenesisURL                string
-	LowestVisorVersion        string
-	DataNodesRESTUrls         []string
-	TendermintSeeds           []string
-	BootstrapPeers            []types.EndpointWithVegaREST
-	TendermintRPCServers      []types.EndpointWithVegaREST
-	TendermintPersistentPeers []string
-	BinariesOverride          []BinaryOverride
-}
+// mainnetGenesisSHA256 is the checksum of the mainnet1 genesis.json. The genesis never changes after the
+// chain starts, so the checksum can be pinned. It is empty until the checksum of the published genesis is
+// confirmed: only the chain id of the genesis is verified, use the --genesis-sha256 flag to verify its content.
+const mainnetGenesisSHA256 = ""
 
 func MainnetConfig() NetworkConfig {
 	return NetworkConfig{
 		GenesisVersion:     "v0.71.4",
+		ChainID:            "vega-mainnet-0011",
 		LowestVisorVersion: "v0.73.6",
 		Repository:         "vegaprotocol/vega",
-		GenesisURL:         "https://raw.githubusercontent.com/vegaprotocol/networks/master/mainnet1/genesis.json",
+		GenesisSHA256:      mainnetGenesisSHA256,
+		GenesisURLs: []string{
+			"https://raw.githubusercontent.com/vegaprotocol/networks/master/mainnet1/genesis.json",
+			// jsDelivr mirrors the same file from the GitHub repository
+			"https://cdn.jsdelivr.net/gh/vegaprotocol/networks@master/mainnet1/genesis.json",
+		},
 		DataNodesRESTUrls: []string{
 			// "https://api0.vega.community",
 			"https://api1.vega.community",
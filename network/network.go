@@ -0,0 +1,102 @@
+package network
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/daniel1302/vega-assistant/github"
+	"github.com/daniel1302/vega-assistant/types"
+)
+
+type Name string
+
+const (
+	Mainnet    Name = "mainnet"
+	Fairground Name = "fairground"
+)
+
+type BinaryOverride struct {
+	OldVersion string `toml:"old-version" json:"old-version"`
+	NewVersion string `toml:"new-version" json:"new-version"`
+	Block      uint64 `toml:"block" json:"block"`
+}
+
+type NetworkConfig struct {
+	GenesisVersion            string                       `toml:"genesis-version" json:"genesis-version"`
+	ChainID                   string                       `toml:"chain-id" json:"chain-id"`
+	Repository                string                       `toml:"repository" json:"repository"`
+	AssetNameTemplate         string                       `toml:"asset-name-template" json:"asset-name-template"`
+	BinaryName                string                       `toml:"binary-name" json:"binary-name"`
+	GenesisURLs               []string                     `toml:"genesis-urls" json:"genesis-urls"`
+	GenesisURL                string                       `toml:"genesis-url" json:"genesis-url"` // Deprecated: use the GenesisURLs
+	GenesisSHA256             string                       `toml:"genesis-sha256" json:"genesis-sha256"`
+	LowestVisorVersion        string                       `toml:"lowest-visor-version" json:"lowest-visor-version"`
+	DataNodesRESTUrls         []string                     `toml:"data-nodes-rest-urls" json:"data-nodes-rest-urls"`
+	TendermintSeeds           []string                     `toml:"tendermint-seeds" json:"tendermint-seeds"`
+	BootstrapPeers            []types.EndpointWithVegaREST `toml:"bootstrap-peers" json:"bootstrap-peers"`
+	TendermintRPCServers      []types.EndpointWithVegaREST `toml:"tendermint-rpc-servers" json:"tendermint-rpc-servers"`
+	TendermintPersistentPeers []string                     `toml:"tendermint-persistent-peers" json:"tendermint-persistent-peers"`
+	BinariesOverride          []BinaryOverride             `toml:"binaries-override" json:"binaries-override"`
+	BinaryMirrorURL           string                       `toml:"binary-mirror-url" json:"binary-mirror-url"`
+}
+
+// GenesisSources returns the genesis mirrors in the order they should be tried
+func (config NetworkConfig) GenesisSources() []string {
+	sources := slices.Clone(config.GenesisURLs)
+	if config.GenesisURL != "" && !slices.Contains(sources, config.GenesisURL) {
+		sources = append(sources, config.GenesisURL)
+	}
+
+	return sources
+}
+
+// VegaAssetLayout returns the layout of the vega release asset. The official layout is used for the
+// empty AssetNameTemplate and BinaryName.
+func (config NetworkConfig) VegaAssetLayout() github.AssetLayout {
+	layout := github.DefaultAssetLayout(github.ArtifactVega)
+	if config.AssetNameTemplate != "" {
+		layout.NameTemplate = config.AssetNameTemplate
+	}
+	if config.BinaryName != "" {
+		layout.BinaryName = config.BinaryName
+	}
+
+	return layout
+}
+
+// ReleaseSource returns where the network binaries are published: the repository, the binary mirror and
+// the vega asset layout
+func (config NetworkConfig) ReleaseSource() github.ReleaseSource {
+	return github.ReleaseSource{
+		Repository: config.Repository,
+		Mirror:     github.MirrorURLTemplate(config.BinaryMirrorURL),
+		Layouts: map[github.ArtifactType]github.AssetLayout{
+			github.ArtifactVega: config.VegaAssetLayout(),
+		},
+	}
+}
+
+// RequiresPeersDiscovery returns true when the config does not define the tendermint seeds or the network
+// history bootstrap peers, e.g. for networks reset from time to time. They must be discovered from the data-nodes.
+func (config NetworkConfig) RequiresPeersDiscovery() bool {
+	return len(config.TendermintSeeds) < 1 || len(config.BootstrapPeers) < 1
+}
+
+func AvailableNetworks() []Name {
+	return []Name{Mainnet, Fairground}
+}
+
+func ConfigForNetwork(name Name) (NetworkConfig, error) {
+	switch name {
+	case Mainnet:
+		return MainnetConfig(), nil
+	case Fairground:
+		return FairgroundConfig(), nil
+	}
+
+	return NetworkConfig{}, fmt.Errorf(
+		"unknown network %s: available networks are %v",
+		name,
+		AvailableNetworks(),
+	)
+}
@@ -0,0 +1,47 @@
+package network
+
+import "testing"
+
+func TestPresetsRequirePeersDiscovery(t *testing.T) {
+	tests := []struct {
+		name     Name
+		expected bool
+	}{
+		{name: Mainnet, expected: false},
+		{name: Fairground, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.name), func(t *testing.T) {
+			config, err := ConfigForNetwork(tt.name)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if got := config.RequiresPeersDiscovery(); got != tt.expected {
+				t.Errorf("expected RequiresPeersDiscovery() = %t, got %t", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestWithDiscoveredPeersChainID(t *testing.T) {
+	tests := []struct {
+		name       string
+		chainID    string
+		discovered string
+		expected   string
+	}{
+		{name: "discovered chain id is used", chainID: "", discovered: "testnet-1", expected: "testnet-1"},
+		{name: "pinned chain id is kept", chainID: "mainnet-1", discovered: "testnet-1", expected: "mainnet-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := NetworkConfig{ChainID: tt.chainID}.WithDiscoveredPeers(NetworkConfig{ChainID: tt.discovered})
+			if config.ChainID != tt.expected {
+				t.Errorf("expected chain id %s, got %s", tt.expected, config.ChainID)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package network
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+var (
+	nodeIDRegexp   = regexp.MustCompile(`^[0-9a-fA-F]{40}$`)
+	hostnameRegexp = regexp.MustCompile(`^([a-zA-Z0-9]([a-zA-Z0-9-]*[a-zA-Z0-9])?)(\.[a-zA-Z0-9]([a-zA-Z0-9-]*[a-zA-Z0-9])?)*$`)
+)
+
+// ValidatePeerAddress checks the tendermint peer has the `<node-id>@<host>:<port>` format, where
+// node ID is 40 hex characters
+func ValidatePeerAddress(addr string) error {
+	nodeID, hostPort, found := strings.Cut(addr, "@")
+	if !found {
+		return fmt.Errorf("invalid peer address %q: expected <node-id>@<host>:<port>", addr)
+	}
+
+	if !nodeIDRegexp.MatchString(nodeID) {
+		return fmt.Errorf("invalid peer address %q: node id must be 40 hex characters", addr)
+	}
+
+	if err := validateHostPort(hostPort); err != nil {
+		return fmt.Errorf("invalid peer address %q: %w", addr, err)
+	}
+
+	return nil
+}
+
+// validateHostPort checks the `<host>:<port>` address, e.g. the tendermint rpc server
+func validateHostPort(addr string) error {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("expected <host>:<port>: %w", err)
+	}
+
+	if net.ParseIP(host) == nil && !hostnameRegexp.MatchString(host) {
+		return fmt.Errorf("invalid host %q", host)
+	}
+
+	portNumber, err := strconv.Atoi(port)
+	if err != nil || portNumber < 1 || portNumber > 65535 {
+		return fmt.Errorf("invalid port %q", port)
+	}
+
+	return nil
+}
+
+// ValidatePeers checks format of the tendermint seeds, persistent peers and rpc servers. All invalid
+// entries are reported.
+func (config NetworkConfig) ValidatePeers() error {
+	var resErr error
+	for _, seed := range config.TendermintSeeds {
+		if err := ValidatePeerAddress(strings.TrimSpace(seed)); err != nil {
+			resErr = multierror.Append(resErr, fmt.Errorf("invalid tendermint-seeds entry: %w", err))
+		}
+	}
+
+	for _, peer := range config.TendermintPersistentPeers {
+		if err := ValidatePeerAddress(strings.TrimSpace(peer)); err != nil {
+			resErr = multierror.Append(resErr, fmt.Errorf("invalid tendermint-persistent-peers entry: %w", err))
+		}
+	}
+
+	for _, rpcServer := range config.TendermintRPCServers {
+		endpoint := strings.TrimPrefix(strings.TrimPrefix(rpcServer.Endpoint, "http://"), "https://")
+		if err := validateHostPort(strings.TrimRight(endpoint, "/")); err != nil {
+			resErr = multierror.Append(resErr, fmt.Errorf("invalid tendermint-rpc-servers entry %q: %w", rpcServer.Endpoint, err))
+		}
+	}
+
+	return resErr
+}
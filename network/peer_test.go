@@ -0,0 +1,61 @@
+package network
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/go-multierror"
+
+	"github.com/daniel1302/vega-assistant/types"
+)
+
+const testNodeID = "b0db58f5651c85385f588bd5238b42bedbe57073"
+
+func TestValidatePeerAddress(t *testing.T) {
+	tests := []struct {
+		name    string
+		addr    string
+		wantErr bool
+	}{
+		{name: "hostname", addr: testNodeID + "@api0.vega.community:26656"},
+		{name: "ip", addr: testNodeID + "@13.125.55.240:26656"},
+		{name: "missing node id", addr: "13.125.55.240:26656", wantErr: true},
+		{name: "short node id", addr: "b0db58f5@13.125.55.240:26656", wantErr: true},
+		{name: "missing port", addr: testNodeID + "@13.125.55.240", wantErr: true},
+		{name: "invalid port", addr: testNodeID + "@13.125.55.240:70000", wantErr: true},
+		{name: "invalid host", addr: testNodeID + "@-host:26656", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePeerAddress(tt.addr)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected error: %t, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidatePeersReportsAllInvalidPeers(t *testing.T) {
+	config := NetworkConfig{
+		TendermintSeeds:           []string{testNodeID + "@seed.vega.community:26656", "invalid-seed"},
+		TendermintPersistentPeers: []string{"invalid-peer"},
+		TendermintRPCServers: []types.EndpointWithVegaREST{
+			{Endpoint: "api0.vega.community:26657"},
+			{Endpoint: "https://api1.vega.community"},
+		},
+	}
+
+	err := config.ValidatePeers()
+	merr := &multierror.Error{}
+	if !errors.As(err, &merr) {
+		t.Fatalf("expected multierror, got %v", err)
+	}
+	if len(merr.Errors) != 3 {
+		t.Errorf("expected 3 errors, got %d: %v", len(merr.Errors), err)
+	}
+
+	if err := (NetworkConfig{TendermintSeeds: []string{testNodeID + "@seed.vega.community:26656"}}).ValidatePeers(); err != nil {
+		t.Errorf("unexpected error for valid peers: %s", err)
+	}
+}
@@ -0,0 +1,89 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrChainIDMismatch is returned for the tendermint RPC server that serves a different chain
+var ErrChainIDMismatch = errors.New("chain id mismatch")
+
+func tendermintRPCURL(rpcServer string) string {
+	rpcServer = strings.TrimRight(strings.TrimSpace(rpcServer), "/")
+	if strings.HasPrefix(rpcServer, "http://") || strings.HasPrefix(rpcServer, "https://") {
+		return rpcServer
+	}
+
+	return fmt.Sprintf("http://%s", rpcServer)
+}
+
+// FilterReachableRPCServers calls the `/health` and the `/status` endpoints on every tendermint RPC
+// server. It returns reachable servers that serve the chainID and errors for the dropped ones. Servers
+// serving different chain are dropped with the ErrChainIDMismatch error. Chain is not checked when
+// chainID is empty.
+func FilterReachableRPCServers(ctx context.Context, rpcServers []string, chainID string) ([]string, map[string]error) {
+	reachable := []string{}
+	unreachable := map[string]error{}
+	for _, rpcServer := range rpcServers {
+		if err := checkRPCServer(ctx, rpcServer, chainID); err != nil {
+			unreachable[rpcServer] = err
+			continue
+		}
+		reachable = append(reachable, rpcServer)
+	}
+
+	return reachable, unreachable
+}
+
+func checkRPCServer(ctx context.Context, rpcServer, chainID string) error {
+	ctx, cancel := context.WithTimeout(ctx, discoveryTimeout)
+	defer cancel()
+
+	rpcURL := tendermintRPCURL(rpcServer)
+	health := map[string]interface{}{}
+	if err := getJSON(ctx, fmt.Sprintf("%s/health", rpcURL), &health); err != nil {
+		return err
+	}
+
+	if chainID == "" {
+		return nil
+	}
+
+	status := tendermintStatus{}
+	if err := getJSON(ctx, fmt.Sprintf("%s/status", rpcURL), &status); err != nil {
+		return fmt.Errorf("failed to get tendermint status: %w", err)
+	}
+
+	if status.Result.NodeInfo.Network != chainID {
+		return fmt.Errorf(
+			"%w: expected %s, got %q",
+			ErrChainIDMismatch,
+			chainID,
+			status.Result.NodeInfo.Network,
+		)
+	}
+
+	return nil
+}
+
+// LatestBlockHeight returns the latest block height reported by the `/status` endpoint of the tendermint
+// RPC server
+func LatestBlockHeight(ctx context.Context, rpcServer string) (uint64, error) {
+	ctx, cancel := context.WithTimeout(ctx, discoveryTimeout)
+	defer cancel()
+
+	status := tendermintStatus{}
+	if err := getJSON(ctx, fmt.Sprintf("%s/status", tendermintRPCURL(rpcServer)), &status); err != nil {
+		return 0, fmt.Errorf("failed to get tendermint status: %w", err)
+	}
+
+	height, err := strconv.ParseUint(status.Result.SyncInfo.LatestBlockHeight, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse latest block height(%q): %w", status.Result.SyncInfo.LatestBlockHeight, err)
+	}
+
+	return height, nil
+}
@@ -0,0 +1,100 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/daniel1302/vega-assistant/utils"
+)
+
+func TestLatestBlockHeightUsesProxy(t *testing.T) {
+	proxied := []string{}
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.String())
+		w.Write([]byte(`{"result": {"sync_info": {"latest_block_height": "12345"}}}`))
+	}))
+	defer proxy.Close()
+
+	if err := utils.SetProxy(proxy.URL); err != nil {
+		t.Fatalf("failed to set proxy: %s", err)
+	}
+	t.Cleanup(func() { utils.SetProxy("") })
+
+	height, err := LatestBlockHeight(context.Background(), "rpc.vega.example:26657")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if height != 12345 {
+		t.Errorf("expected height 12345, got %d", height)
+	}
+	if len(proxied) != 1 || proxied[0] != "http://rpc.vega.example:26657/status" {
+		t.Errorf("expected request to be sent via proxy, got %v", proxied)
+	}
+}
+
+func TestFilterReachableRPCServers(t *testing.T) {
+	newRPCServer := func(healthStatus int, chainID string) *httptest.Server {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/health":
+				w.WriteHeader(healthStatus)
+				w.Write([]byte(`{}`))
+			case "/status":
+				fmt.Fprintf(w, `{"result": {"node_info": {"id": "node", "network": %q}}}`, chainID)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		t.Cleanup(server.Close)
+
+		return server
+	}
+
+	healthy := newRPCServer(http.StatusOK, "mainnet-1")
+	unhealthy := newRPCServer(http.StatusInternalServerError, "mainnet-1")
+	otherChain := newRPCServer(http.StatusOK, "testnet-1")
+	hanging := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	t.Cleanup(hanging.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	reachable, unreachable := FilterReachableRPCServers(
+		ctx,
+		[]string{healthy.URL, unhealthy.URL, otherChain.URL, hanging.URL},
+		"mainnet-1",
+	)
+
+	if len(reachable) != 1 || reachable[0] != healthy.URL {
+		t.Errorf("expected only the healthy server to be reachable, got %v", reachable)
+	}
+
+	tests := []struct {
+		name      string
+		rpcServer string
+		check     func(err error) bool
+	}{
+		{name: "unhealthy", rpcServer: unhealthy.URL, check: func(err error) bool { return err != nil }},
+		{name: "chain id mismatch", rpcServer: otherChain.URL, check: func(err error) bool { return errors.Is(err, ErrChainIDMismatch) }},
+		{name: "timeout", rpcServer: hanging.URL, check: func(err error) bool { return errors.Is(err, context.DeadlineExceeded) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err, dropped := unreachable[tt.rpcServer]
+			if !dropped {
+				t.Fatalf("expected %s server to be dropped", tt.name)
+			}
+			if !tt.check(err) {
+				t.Errorf("unexpected error for the %s server: %v", tt.name, err)
+			}
+		})
+	}
+}
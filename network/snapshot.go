@@ -0,0 +1,120 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+// trustedBlockOffset is the number of blocks below the network head for the statesync trusted block.
+// Latest blocks may not be available on all of the RPC servers yet.
+const trustedBlockOffset = 1000
+
+// Snapshot is the trusted block used for the tendermint statesync
+type Snapshot struct {
+	Height uint64
+	Hash   string
+}
+
+type tendermintCommit struct {
+	Result struct {
+		SignedHeader struct {
+			Commit struct {
+				BlockID struct {
+					Hash string `json:"hash"`
+				} `json:"block_id"`
+			} `json:"commit"`
+		} `json:"signed_header"`
+	} `json:"result"`
+}
+
+// FetchLatestSnapshot returns recent trusted block from the tendermint RPC servers. The block is
+// selected below the lowest head reported by servers, so every server has it. The block hash must be
+// confirmed by the majority of servers that responded.
+func FetchLatestSnapshot(ctx context.Context, rpcServers []string) (Snapshot, error) {
+	rpcURLs := []string{}
+	for _, rpcServer := range rpcServers {
+		rpcURL := tendermintRPCURL(rpcServer)
+		if !slices.Contains(rpcURLs, rpcURL) {
+			rpcURLs = append(rpcURLs, rpcURL)
+		}
+	}
+	if len(rpcURLs) < 1 {
+		return Snapshot{}, fmt.Errorf("at least one tendermint rpc server is required to fetch the trusted block")
+	}
+
+	var (
+		resErr       error
+		lowestHeight uint64
+		liveURLs     = []string{}
+	)
+	for _, rpcURL := range rpcURLs {
+		height, err := LatestBlockHeight(ctx, rpcURL)
+		if err != nil {
+			resErr = multierror.Append(resErr, fmt.Errorf("failed to get latest block height from %s: %w", rpcURL, err))
+			continue
+		}
+
+		if len(liveURLs) == 0 || height < lowestHeight {
+			lowestHeight = height
+		}
+		liveURLs = append(liveURLs, rpcURL)
+	}
+	if len(liveURLs) < 1 {
+		return Snapshot{}, fmt.Errorf("failed to get latest block height from any rpc server: %w", resErr)
+	}
+
+	if lowestHeight <= trustedBlockOffset {
+		return Snapshot{}, fmt.Errorf(
+			"the chain is too short for the trusted block: height %d, required more than %d",
+			lowestHeight,
+			trustedBlockOffset,
+		)
+	}
+	trustedHeight := lowestHeight - trustedBlockOffset
+
+	votes := map[string][]string{}
+	for _, rpcURL := range liveURLs {
+		hash, err := fetchBlockHash(ctx, rpcURL, trustedHeight)
+		if err != nil {
+			resErr = multierror.Append(resErr, err)
+			continue
+		}
+		votes[hash] = append(votes[hash], rpcURL)
+	}
+
+	responses := 0
+	for _, servers := range votes {
+		responses += len(servers)
+	}
+	if responses < 1 {
+		return Snapshot{}, fmt.Errorf("failed to get hash of block %d from any rpc server: %w", trustedHeight, resErr)
+	}
+
+	for hash, servers := range votes {
+		if len(servers)*2 > responses {
+			return Snapshot{Height: trustedHeight, Hash: hash}, nil
+		}
+	}
+
+	return Snapshot{}, fmt.Errorf("rpc servers disagree on hash of block %d: %v", trustedHeight, votes)
+}
+
+func fetchBlockHash(ctx context.Context, rpcURL string, height uint64) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, discoveryTimeout)
+	defer cancel()
+
+	commit := tendermintCommit{}
+	if err := getJSON(ctx, fmt.Sprintf("%s/commit?height=%d", rpcURL, height), &commit); err != nil {
+		return "", fmt.Errorf("failed to get tendermint commit: %w", err)
+	}
+
+	hash := commit.Result.SignedHeader.Commit.BlockID.Hash
+	if hash == "" {
+		return "", fmt.Errorf("commit for block %d from %s does not contain block hash", height, rpcURL)
+	}
+
+	return hash, nil
+}
@@ -0,0 +1,83 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func testRPCServer(t *testing.T, height uint64, hash string) string {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/status":
+			fmt.Fprintf(w, `{"result": {"sync_info": {"latest_block_height": "%d"}}}`, height)
+		case "/commit":
+			fmt.Fprintf(w, `{"result": {"signed_header": {"commit": {"block_id": {"hash": "%s"}}}}}`, hash)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server.URL
+}
+
+func TestFetchLatestSnapshot(t *testing.T) {
+	tests := []struct {
+		name     string
+		servers  func(t *testing.T) []string
+		expected Snapshot
+		wantErr  string
+	}{
+		{
+			name: "majority hash below lowest head",
+			servers: func(t *testing.T) []string {
+				return []string{
+					testRPCServer(t, 5000, "AAA"),
+					testRPCServer(t, 4000, "AAA"),
+					testRPCServer(t, 6000, "BBB"),
+				}
+			},
+			expected: Snapshot{Height: 3000, Hash: "AAA"},
+		},
+		{
+			name: "servers disagree",
+			servers: func(t *testing.T) []string {
+				return []string{testRPCServer(t, 5000, "AAA"), testRPCServer(t, 5000, "BBB")}
+			},
+			wantErr: "rpc servers disagree",
+		},
+		{
+			name: "chain too short",
+			servers: func(t *testing.T) []string {
+				return []string{testRPCServer(t, 1000, "AAA")}
+			},
+			wantErr: "the chain is too short",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snapshot, err := FetchLatestSnapshot(context.Background(), tt.servers(t))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected %q error, got %v", tt.wantErr, err)
+				}
+
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if snapshot != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, snapshot)
+			}
+		})
+	}
+}
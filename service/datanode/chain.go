@@ -0,0 +1,35 @@
+package datanode
+
+import (
+	"fmt"
+
+	"github.com/daniel1302/vega-assistant/network"
+	"github.com/daniel1302/vega-assistant/vega"
+)
+
+// ResolveChainID returns the chain id the node is set up for. The chain id set by user takes precedence over
+// the known chain id from the network config. The chain id reported by the data-nodes is used only when
+// none of them is set. The resolved chain id must match the reported one, otherwise the node never syncs.
+func ResolveChainID(userChainID string, networkConfig network.NetworkConfig, reportedChainID string) (string, error) {
+	chainID := userChainID
+	if chainID == "" {
+		chainID = networkConfig.ChainID
+	}
+	if chainID == "" {
+		chainID = reportedChainID
+	}
+
+	if err := vega.ValidateChainID(chainID, networkConfig.ChainID); err != nil {
+		return "", err
+	}
+
+	if reportedChainID != "" && reportedChainID != chainID {
+		return "", fmt.Errorf(
+			"chain id mismatch: expected %s, but the network data-nodes report %s: check the selected network or the chain id",
+			chainID,
+			reportedChainID,
+		)
+	}
+
+	return chainID, nil
+}
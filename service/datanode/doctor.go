@@ -0,0 +1,244 @@
+package datanode
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pelletier/go-toml"
+	"golang.org/x/mod/semver"
+
+	"github.com/daniel1302/vega-assistant/network"
+	"github.com/daniel1302/vega-assistant/types"
+	"github.com/daniel1302/vega-assistant/utils"
+	"github.com/daniel1302/vega-assistant/vega"
+	"github.com/daniel1302/vega-assistant/vegacmd"
+)
+
+type DoctorStatus string
+
+const (
+	DoctorPass DoctorStatus = "PASS"
+	DoctorWarn DoctorStatus = "WARN"
+	DoctorFail DoctorStatus = "FAIL"
+)
+
+type DoctorCheck struct {
+	Name    string
+	Status  DoctorStatus
+	Details string
+}
+
+// DoctorSettings describes the installation checked by the RunDoctor. DataNodeHome is empty for the node
+// without the data-node, e.g. the validator.
+type DoctorSettings struct {
+	VisorHome      string
+	VegaHome       string
+	TendermintHome string
+	DataNodeHome   string
+	// ChainID is the expected chain id. It defaults to the chain id from the data-node config, then to the
+	// NetworkChainID
+	ChainID        string
+	NetworkChainID string
+	// RPCServers are checked when the tendermint config does not define the statesync rpc servers
+	RPCServers []string
+}
+
+// RunDoctor diagnoses the existing installation. Checks depending on the failed check are skipped.
+func RunDoctor(settings DoctorSettings) []DoctorCheck {
+	checks := []DoctorCheck{}
+
+	checks = append(checks, binaryCheck("vegavisor binary", filepath.Join(settings.VisorHome, "visor")))
+
+	currentPath := filepath.Join(settings.VisorHome, "current")
+	currentDir, err := filepath.EvalSymlinks(currentPath)
+	if err == nil && !utils.IsDir(currentDir) {
+		err = fmt.Errorf("%s is not a directory", currentDir)
+	}
+	if err != nil {
+		checks = append(checks, failedCheck("current symlink", fmt.Errorf("%s does not resolve: %w", currentPath, err)))
+	} else {
+		checks = append(checks, passedCheck("current symlink", currentDir))
+
+		vegaCheck := binaryCheck("vega binary", filepath.Join(currentDir, "vega"))
+		// The current directory is named after the vega version, except the genesis one
+		if currentVersion := filepath.Base(currentDir); vegaCheck.Status == DoctorPass && semver.IsValid(currentVersion) &&
+			!vegacmd.VersionsMatch(vegaCheck.Details, currentVersion) {
+			vegaCheck.Status = DoctorWarn
+			vegaCheck.Details = fmt.Sprintf("version %s does not match the %s directory", vegaCheck.Details, currentVersion)
+		}
+		checks = append(checks, vegaCheck)
+
+		runConfigCheck, _ := configCheck("run-config.toml", filepath.Join(currentDir, "run-config.toml"), "vega.binary.args")
+		checks = append(checks, runConfigCheck)
+	}
+
+	visorConfigCheck, _ := configCheck(
+		"vegavisor config",
+		filepath.Join(settings.VisorHome, vegacmd.VegavisorConfigPath),
+		"autoInstall.enabled",
+	)
+	vegaConfigCheck, _ := configCheck(
+		"vega config",
+		filepath.Join(settings.VegaHome, vegacmd.CoreConfigPath),
+		"Snapshot.StartHeight",
+	)
+	tendermintConfigCheck, tendermintConfig := configCheck(
+		"tendermint config",
+		filepath.Join(settings.TendermintHome, vegacmd.TenderminConfigPath),
+		"p2p.persistent_peers",
+	)
+	checks = append(checks, visorConfigCheck, vegaConfigCheck, tendermintConfigCheck)
+
+	var dataNodeConfig *toml.Tree
+	if settings.DataNodeHome != "" {
+		var dataNodeConfigCheck DoctorCheck
+		dataNodeConfigCheck, dataNodeConfig = configCheck(
+			"data-node config",
+			filepath.Join(settings.DataNodeHome, vegacmd.DataNodeConfigPath),
+			"SQLStore.ConnectionConfig.Host",
+			"SQLStore.ConnectionConfig.Database",
+		)
+		checks = append(checks, dataNodeConfigCheck)
+	}
+
+	chainID := settings.ChainID
+	if chainID == "" && dataNodeConfig != nil {
+		chainID, _ = dataNodeConfig.Get("ChainID").(string)
+	}
+	if chainID == "" {
+		chainID = settings.NetworkChainID
+	}
+	checks = append(checks, genesisCheck(filepath.Join(settings.TendermintHome, vegacmd.GenesisPath), chainID))
+
+	if dataNodeConfig != nil {
+		for _, result := range CheckSQLDatabase(sqlCredentialsFromConfig(dataNodeConfig)) {
+			if result.Err != nil && result.Warning {
+				checks = append(checks, DoctorCheck{Name: "SQL: " + result.Name, Status: DoctorWarn, Details: result.Err.Error()})
+			} else if result.Err != nil {
+				checks = append(checks, failedCheck("SQL: "+result.Name, result.Err))
+			} else {
+				checks = append(checks, passedCheck("SQL: "+result.Name, ""))
+			}
+		}
+	}
+
+	rpcServers := settings.RPCServers
+	if tendermintConfig != nil {
+		if configuredServers, _ := tendermintConfig.Get("statesync.rpc_servers").(string); configuredServers != "" {
+			rpcServers = strings.Split(configuredServers, ",")
+		}
+	}
+	checks = append(checks, rpcServersCheck(rpcServers, chainID))
+
+	return checks
+}
+
+func passedCheck(name, details string) DoctorCheck {
+	return DoctorCheck{Name: name, Status: DoctorPass, Details: details}
+}
+
+func failedCheck(name string, err error) DoctorCheck {
+	return DoctorCheck{Name: name, Status: DoctorFail, Details: err.Error()}
+}
+
+func binaryCheck(name, binaryPath string) DoctorCheck {
+	if !utils.FileExists(binaryPath) {
+		return failedCheck(name, fmt.Errorf("%s does not exist", binaryPath))
+	}
+
+	version, err := vegacmd.BinaryVersion(binaryPath)
+	if err != nil {
+		return failedCheck(name, err)
+	}
+
+	return passedCheck(name, version)
+}
+
+// configCheck loads the TOML config and checks it contains the required keys. Loaded config is returned
+// when it can be parsed.
+func configCheck(name, configPath string, requiredKeys ...string) (DoctorCheck, *toml.Tree) {
+	config, err := toml.LoadFile(configPath)
+	if err != nil {
+		return failedCheck(name, fmt.Errorf("failed to load %s: %w", configPath, err)), nil
+	}
+
+	missingKeys := []string{}
+	for _, key := range requiredKeys {
+		if !config.Has(key) {
+			missingKeys = append(missingKeys, key)
+		}
+	}
+	if len(missingKeys) > 0 {
+		return DoctorCheck{
+			Name:    name,
+			Status:  DoctorWarn,
+			Details: fmt.Sprintf("%s does not contain %s: it was not updated by the setup", configPath, strings.Join(missingKeys, ", ")),
+		}, config
+	}
+
+	return passedCheck(name, configPath), config
+}
+
+func genesisCheck(genesisPath, chainID string) DoctorCheck {
+	if chainID == "" {
+		if _, err := os.Stat(genesisPath); err != nil {
+			return failedCheck("genesis", err)
+		}
+
+		return DoctorCheck{Name: "genesis", Status: DoctorWarn, Details: "expected chain id is unknown, chain_id is not verified"}
+	}
+
+	if err := vega.ValidateGenesis(genesisPath, chainID); err != nil {
+		return failedCheck("genesis", err)
+	}
+
+	return passedCheck("genesis", fmt.Sprintf("chain_id %s", chainID))
+}
+
+func sqlCredentialsFromConfig(config *toml.Tree) types.SQLCredentials {
+	creds := types.SQLCredentials{}
+	creds.Host, _ = config.Get("SQLStore.ConnectionConfig.Host").(string)
+	creds.User, _ = config.Get("SQLStore.ConnectionConfig.Username").(string)
+	creds.Pass, _ = config.Get("SQLStore.ConnectionConfig.Password").(string)
+	// Password may reference the environment variable, see the DBPasswordEnv
+	if strings.HasPrefix(creds.Pass, "${") && strings.HasSuffix(creds.Pass, "}") {
+		creds.Pass = os.Getenv(strings.TrimSuffix(strings.TrimPrefix(creds.Pass, "${"), "}"))
+	}
+	creds.DatabaseName, _ = config.Get("SQLStore.ConnectionConfig.Database").(string)
+	creds.SSLMode, _ = config.Get("SQLStore.ConnectionConfig.SSLMode").(string)
+	creds.SSLRootCert, _ = config.Get("SQLStore.ConnectionConfig.SSLRootCert").(string)
+	if port, ok := config.Get("SQLStore.ConnectionConfig.Port").(int64); ok {
+		creds.Port = int(port)
+	}
+
+	return creds
+}
+
+func rpcServersCheck(rpcServers []string, chainID string) DoctorCheck {
+	if len(rpcServers) == 0 {
+		return DoctorCheck{Name: "tendermint rpc servers", Status: DoctorWarn, Details: "no rpc servers to check"}
+	}
+
+	reachable, unreachable := network.FilterReachableRPCServers(context.Background(), rpcServers, chainID)
+	if len(reachable) == 0 {
+		return failedCheck("tendermint rpc servers", fmt.Errorf("none of %d rpc servers is reachable", len(rpcServers)))
+	}
+
+	if len(unreachable) > 0 {
+		details := []string{}
+		for rpcServer, err := range unreachable {
+			details = append(details, fmt.Sprintf("%s: %s", rpcServer, err.Error()))
+		}
+
+		return DoctorCheck{
+			Name:    "tendermint rpc servers",
+			Status:  DoctorWarn,
+			Details: fmt.Sprintf("%d of %d reachable, unreachable: %s", len(reachable), len(rpcServers), strings.Join(details, "; ")),
+		}
+	}
+
+	return passedCheck("tendermint rpc servers", fmt.Sprintf("%d reachable", len(reachable)))
+}
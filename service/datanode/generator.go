@@ -2,17 +2,16 @@ package datanode
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"os"
 	"path/filepath"
-	"runtime"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"go.uber.org/zap"
 
-	"github.com/daniel1302/vega-assistant/github"
 	"github.com/daniel1302/vega-assistant/network"
 	"github.com/daniel1302/vega-assistant/types"
 	"github.com/daniel1302/vega-assistant/utils"
@@ -21,9 +20,7 @@ import (
 )
 
 type DataNodeGenerator struct {
-	vegaApi       *vegaapi.NetworkAPI
-	userSettings  GenerateSettings
-	networkConfig network.NetworkConfig
+	*nodeGenerator
 }
 
 func NewDataNodeGenerator(
@@ -32,170 +29,37 @@ func NewDataNodeGenerator(
 	networkConfig network.NetworkConfig,
 ) (*DataNodeGenerator, error) {
 	return &DataNodeGenerator{
-		vegaApi:       vegaApi,
-		userSettings:  settings,
-		networkConfig: networkConfig,
+		nodeGenerator: &nodeGenerator{
+			vegaApi:       vegaApi,
+			userSettings:  settings,
+			networkConfig: networkConfig,
+			withDataNode:  true,
+		},
 	}, nil
 }
 
-func (gen *DataNodeGenerator) Run(logger *zap.SugaredLogger) error {
-	outputDir, err := os.MkdirTemp("", "vega-assistant")
-	if err != nil {
-		return fmt.Errorf("failed to create temp dir: %w", err)
-	}
-	//	defer os.RemoveAll(outputDir)
-
-	logger.Info("Downloading vega binary")
-	vegaBinaryPath, err := github.DownloadArtifact(
-		gen.networkConfig.Repository,
-		gen.userSettings.VegaBinaryVersion,
-		outputDir,
-		github.ArtifactVega,
-	)
-	if err != nil {
-		return fmt.Errorf("failed to download vega binary: %w", err)
-	}
-	logger.Infof("Vega downloaded to %s", vegaBinaryPath)
-
-	logger.Info("Downloading visor binary")
-	visorBinaryPath, err := github.DownloadArtifact(
-		gen.networkConfig.Repository,
-		gen.userSettings.VisorBinaryVersion,
-		outputDir,
-		github.ArtifactVisor,
-	)
-	if err != nil {
-		return fmt.Errorf("failed to download visor binary: %w", err)
-	}
-	logger.Infof("Visor downloaded to %s", visorBinaryPath)
-
-	logger.Info("Checking binaries versions")
-	vegaVersion, err := utils.ExecuteBinary(vegaBinaryPath, []string{"version"}, nil)
-	if err != nil {
-		return fmt.Errorf("failed to check vega version: %w", err)
-	}
-	logger.Infof("Vega version is %s", vegaVersion)
-	VisorBinaryVersion, err := utils.ExecuteBinary(visorBinaryPath, []string{"version"}, nil)
-	if err != nil {
-		return fmt.Errorf("failed to check visor version: %w", err)
-	}
-	logger.Infof("Visor version is %s", VisorBinaryVersion)
-
-	if err := gen.initNode(logger, visorBinaryPath, vegaBinaryPath); err != nil {
-		return fmt.Errorf("failed to init vega node: %w", err)
-	}
-
-	if err := gen.prepareVisorHome(logger); err != nil {
-		return fmt.Errorf("failed to prepare visor home: %w", err)
-	}
-
-	if err := gen.copyBinaries(logger, vegaBinaryPath, visorBinaryPath); err != nil {
-		return fmt.Errorf("failed to copy binaries to visor home: %w", err)
-	}
-
-	restartSnapshot, err := gen.selectSnapshotForRestart(context.Background(), logger)
-	if err != nil {
-		return fmt.Errorf("failed to select snapshot for restart: %w", err)
-	}
-
-	if err := gen.updateConfigs(logger, restartSnapshot); err != nil {
-		return fmt.Errorf("failed to update config files for the node: %w", err)
-	}
-
-	if err := gen.downloadGenesis(logger); err != nil {
-		return fmt.Errorf("failed to download genesis: %w", err)
-	}
-	return nil
-}
-
-func (gen *DataNodeGenerator) downloadGenesis(logger *zap.SugaredLogger) error {
-	genesisDestination := filepath.Join(gen.userSettings.TendermintHome, vegacmd.GenesisPath)
-	logger.Infof("Downloading genesis.json file from %s", gen.networkConfig.GenesisURL)
-	if err := utils.DownloadFile(gen.networkConfig.GenesisURL, genesisDestination); err != nil {
-		return fmt.Errorf("failed to download genesis: %w", err)
-	}
-	logger.Infof("Genesis downloaded to %s", genesisDestination)
-
-	return nil
-}
-
-func (gen *DataNodeGenerator) copyBinaries(
-	logger *zap.SugaredLogger,
-	vegaBinaryPath, visorBinaryPath string,
-) error {
-	vegavisorDstFilePath := filepath.Join(gen.userSettings.VisorHome, "visor")
-	logger.Infof("Copying vegavisor from %s to %s", visorBinaryPath, vegavisorDstFilePath)
-	if err := utils.CopyFile(visorBinaryPath, vegavisorDstFilePath); err != nil {
-		return fmt.Errorf("failed to copy visor binary: %w", err)
-	}
-	logger.Info("Visor binary copied")
-
-	version := gen.userSettings.VegaBinaryVersion
-	if gen.userSettings.Mode == StartFromBlock0 {
-		version = "genesis"
-	}
-
-	vegaDstFilePath := filepath.Join(gen.userSettings.VisorHome, version, "vega")
-	logger.Infof("Copying vega from %s to %s", vegaBinaryPath, vegaDstFilePath)
-	if err := utils.CopyFile(vegaBinaryPath, vegaDstFilePath); err != nil {
-		return fmt.Errorf("failed to copy vega binary: %w", err)
-	}
-	logger.Info("Vega binary copied")
-
-	versionDirectory := filepath.Join(gen.userSettings.VisorHome, version)
-	currentDirectory := filepath.Join(gen.userSettings.VisorHome, "current")
-	logger.Infof("Creating symlink from %s to %s", versionDirectory, currentDirectory)
-	if err := os.Symlink(versionDirectory, currentDirectory); err != nil {
-		return fmt.Errorf(
-			"failed to create symlink from %s to %s: %w",
-			versionDirectory,
-			currentDirectory,
-			err,
-		)
-	}
-	logger.Info("Symlink created")
-
-	return nil
+func (gen *DataNodeGenerator) Run(ctx context.Context, logger *zap.SugaredLogger) error {
+	return gen.run(ctx, logger, nodeSetup{
+		initNode:      gen.initNode,
+		updateConfigs: gen.updateConfigsForRestart,
+	})
 }
 
-func (gen *DataNodeGenerator) prepareVisorHome(logger *zap.SugaredLogger) error {
-	runConfigDirPath := filepath.Join(gen.userSettings.VisorHome, gen.userSettings.VegaBinaryVersion)
-	version := gen.userSettings.VegaBinaryVersion
-
-	if gen.userSettings.Mode == StartFromBlock0 {
-		runConfigDirPath = filepath.Join(gen.userSettings.VisorHome, "genesis")
-		version = "genesis"
-	}
-
-	logger.Infof("Preparing %s folder for vega", runConfigDirPath)
-	if err := os.MkdirAll(runConfigDirPath, os.ModePerm); err != nil {
-		return fmt.Errorf("failed to make directory: %w", err)
-	}
-	logger.Infof("Folder %s created", runConfigDirPath)
-
-	runConfigPath := filepath.Join(runConfigDirPath, "run-config.toml")
-	logger.Infof("Preparing run-config toml file in %s", runConfigPath)
-	runConfigContent, err := vegacmd.TemplateVisorRunConfig(
-		version,
-		gen.userSettings.VegaHome,
-		gen.userSettings.TendermintHome,
-	)
+func (gen *DataNodeGenerator) updateConfigsForRestart(ctx context.Context, logger *zap.SugaredLogger) error {
+	restartSnapshot, err := gen.selectSnapshotForRestart(ctx, logger)
 	if err != nil {
-		return fmt.Errorf("failed to generate run-config.toml from template: %w", err)
-	}
-	if err := os.WriteFile(runConfigPath, []byte(runConfigContent), os.ModePerm); err != nil {
-		return fmt.Errorf("failed to write run-config.toml in %s: %w", runConfigContent, err)
+		return fmt.Errorf("failed to select snapshot for restart: %w", err)
 	}
-	logger.Infof("The run-config.toml file saved in %s", runConfigPath)
 
-	return nil
+	return gen.updateConfigs(ctx, logger, restartSnapshot)
 }
 
 func (gen *DataNodeGenerator) updateConfigs(
+	ctx context.Context,
 	logger *zap.SugaredLogger,
 	restartSnapshot *types.CoreSnapshot,
 ) error {
-	healthyTendermintRPCServers, err := gen.vegaApi.HealthyEndpoints(context.Background(), gen.networkConfig.TendermintRPCServers)
+	healthyTendermintRPCServers, err := gen.vegaApi.HealthyEndpoints(ctx, gen.networkConfig.TendermintRPCServers)
 	if err != nil {
 		return fmt.Errorf("failed to find healthy tendermint rpc servers: %w", err)
 	}
@@ -204,11 +68,18 @@ func (gen *DataNodeGenerator) updateConfigs(
 		return fmt.Errorf("there is no healthy rpc server")
 	}
 
+	if !gen.userSettings.SkipRPCCheck {
+		healthyTendermintRPCServers, err = gen.checkRPCServers(ctx, logger, healthyTendermintRPCServers)
+		if err != nil {
+			return err
+		}
+	}
+
 	if len(healthyTendermintRPCServers) == 1 {
 		healthyTendermintRPCServers = append(healthyTendermintRPCServers, healthyTendermintRPCServers[0])
 	}
 
-	healthyBootstrapPeers, err := gen.vegaApi.HealthyEndpoints(context.Background(), gen.networkConfig.BootstrapPeers)
+	healthyBootstrapPeers, err := gen.vegaApi.HealthyEndpoints(ctx, gen.networkConfig.BootstrapPeers)
 	if err != nil {
 		return fmt.Errorf("failed to find healthy network history bootstrap peers: %w", err)
 	}
@@ -226,46 +97,40 @@ func (gen *DataNodeGenerator) updateConfigs(
 		"SQLStore.ConnectionConfig.Host":              gen.userSettings.SQLCredentials.Host,
 		"SQLStore.ConnectionConfig.Port":              gen.userSettings.SQLCredentials.Port,
 		"SQLStore.ConnectionConfig.Username":          gen.userSettings.SQLCredentials.User,
-		"SQLStore.ConnectionConfig.Password":          gen.userSettings.SQLCredentials.Pass,
+		"SQLStore.ConnectionConfig.Password":          gen.userSettings.sqlPasswordConfigValue(),
 		"SQLStore.ConnectionConfig.Database":          gen.userSettings.SQLCredentials.DatabaseName,
-		"SQLStore.WipeOnStartup":                      true,
-		"NetworkHistory.Store.BootstrapPeers":         healthyBootstrapPeers,
+		"SQLStore.WipeOnStartup":                      gen.userSettings.WipeOnStartup,
 		"NetworkHistory.Initialise.MinimumBlockCount": gen.userSettings.NetworkHistoryMinBlockCount,
-		"NetworkHistory.Initialise.Timeout":           "4h",
-		"NetworkHistory.RetryTimeout":                 "15s",
-		"API.RateLimit.Rate":                          300.0,
-		"API.RateLimit.Burst":                         1000,
 		// This is controversial for vega but most of the people does not care about network history
 		"NetworkHistory.Publish": false,
 	}
+	if sslMode := gen.userSettings.SQLCredentials.SSLMode; sslMode != "" && sslMode != SSLModeDisable {
+		dataNodeConfig["SQLStore.ConnectionConfig.SSLMode"] = sslMode
+		if gen.userSettings.SQLCredentials.SSLRootCert != "" {
+			dataNodeConfig["SQLStore.ConnectionConfig.SSLRootCert"] = gen.userSettings.SQLCredentials.SSLRootCert
+		}
+	}
+
+	mergeConfig(dataNodeConfig, gen.recommendedDataNodeConfig(healthyBootstrapPeers))
+	mergeConfig(dataNodeConfig, gen.userSettings.dataNodePortsConfig())
 
 	vegaConfig := map[string]interface{}{
-		"Snapshot.StartHeight":      -1,
-		"Broker.Socket.Enabled":     true,
-		"Broker.Socket.DialTimeout": "4h",
+		"Broker.Socket.Enabled": true,
 	}
+	mergeConfig(vegaConfig, gen.recommendedVegaConfig())
 
-	tendermintConfig := map[string]interface{}{
-		"p2p.seeds":              strings.Join(gen.networkConfig.TendermintSeeds, ","),
-		"p2p.persistent_peers":   strings.Join(gen.networkConfig.TendermintPersistentPeers, ","),
-		"p2p.pex":                true,
-		"statesync.enable":       false,
-		"statesync.rpc_servers":  strings.Join(healthyTendermintRPCServers, ","),
-		"statesync.trust_period": "672h0m0s",
-	}
+	// Tendermint expects comma separated strings for the rpc servers, not arrays
+	tendermintConfig := gen.tendermintPeersConfig()
+	// Statesync keys are written only in the network history mode, the block 0 replay does not use them
+	tendermintConfig["statesync.enable"] = false
+	mergeConfig(tendermintConfig, gen.userSettings.tendermintPortsConfig())
 
-	vegavisorConfig := map[string]interface{}{
-		"maxNumberOfFirstConnectionRetries": 43200,
-		"autoInstall.enabled":               true,
-		"autoInstall.repositoryOwner":       strings.Split(gen.networkConfig.Repository, "/")[0],
-		"autoInstall.repository":            strings.Split(gen.networkConfig.Repository, "/")[1],
-		"autoInstall.asset.name": fmt.Sprintf(
-			"vega-%s-%s.zip",
-			runtime.GOOS,
-			runtime.GOARCH,
-		),
-		"autoInstall.asset.binaryName": "vega",
-	}
+	vegavisorConfig := gen.vegavisorConfig()
+
+	mergeConfig(vegaConfig, gen.userSettings.snapshotConfig())
+	vegaPruningConfig, tendermintPruningConfig := gen.userSettings.pruningConfig()
+	mergeConfig(vegaConfig, vegaPruningConfig)
+	mergeConfig(tendermintConfig, tendermintPruningConfig)
 
 	if gen.userSettings.Mode == StartFromNetworkHistory {
 		if restartSnapshot == nil {
@@ -284,33 +149,66 @@ func (gen *DataNodeGenerator) updateConfigs(
 		if err != nil {
 			return fmt.Errorf("failed to convert trust block height from string to int: %w", err)
 		}
+		trustHash := restartSnapshot.BlockHash
+
+		// Fresh block confirmed by the RPC servers is preferred over the snapshot from the data-node API,
+		// but not over the block provided by the operator or the snapshot of the pinned segment. The node
+		// restored above the pinned segment cannot load it.
+		if segment := gen.userSettings.NetworkHistorySegment; segment != nil && !gen.userSettings.hasTrustedBlock() {
+			logger.Infof(
+				"Using trusted block %d(%s) of the snapshot below the pinned network-history segment %s",
+				trustHeight,
+				trustHash,
+				segment.HistorySegmentId,
+			)
+		} else if !gen.userSettings.hasTrustedBlock() {
+			trustedBlock, err := network.FetchLatestSnapshot(ctx, healthyTendermintRPCServers)
+			if err != nil {
+				logger.Warnf("Failed to fetch trusted block from the tendermint rpc servers, using the selected snapshot: %s", err.Error())
+			} else {
+				logger.Infof("Using trusted block %d(%s) confirmed by the tendermint rpc servers", trustedBlock.Height, trustedBlock.Hash)
+				trustHeight = int(trustedBlock.Height)
+				trustHash = trustedBlock.Hash
+			}
+		}
 
 		// We cannot use statis StartHeight value because it is not working when we are syncing more blocks from the data-node
 		// Tendermint does not offer more than 10 snapshots.
 		// vegaConfig["Snapshot.StartHeight"] = trustHeight
+		if !gen.userSettings.WipeOnStartup {
+			logger.Warn("SQLStore.WipeOnStartup is disabled: initialization from the network history fails when the database is not empty")
+		}
+
 		dataNodeConfig["AutoInitialiseFromNetworkHistory"] = true
+		// Pinned segment disables the selection of the latest segment
+		if segment := gen.userSettings.NetworkHistorySegment; segment != nil {
+			dataNodeConfig["NetworkHistory.Initialise.ToSegment"] = segment.HistorySegmentId
+		}
+		trustPeriod, err := gen.resolveTrustPeriod(ctx, logger, healthyTendermintRPCServers)
+		if err != nil {
+			return fmt.Errorf("failed to resolve statesync trust period: %w", err)
+		}
+
 		tendermintConfig["statesync.enable"] = true
+		tendermintConfig["statesync.rpc_servers"] = strings.Join(healthyTendermintRPCServers, ",")
+		tendermintConfig["statesync.trust_period"] = trustPeriod.String()
 		tendermintConfig["statesync.trust_height"] = trustHeight
-		tendermintConfig["statesync.trust_hash"] = restartSnapshot.BlockHash
+		tendermintConfig["statesync.trust_hash"] = trustHash
 	}
 
 	dataNodeConfigPath := filepath.Join(gen.userSettings.DataNodeHome, vegacmd.DataNodeConfigPath)
+	loggedDataNodeConfig := utils.RedactSecrets(dataNodeConfig)
+	if gen.userSettings.DBPasswordEnv != "" {
+		delete(loggedDataNodeConfig, "SQLStore.ConnectionConfig.Password")
+	}
 	logger.Infof(
 		"Updating data-node config(%s). New parameters: %v",
 		dataNodeConfigPath,
-		dataNodeConfig,
+		loggedDataNodeConfig,
 	)
-	if err := utils.UpdateConfig(dataNodeConfigPath, "toml", dataNodeConfig); err != nil {
-		return fmt.Errorf("failed to update the data-node config; %w", err)
-	}
-	logger.Info("Data-node config updated")
 
 	vegaConfigPath := filepath.Join(gen.userSettings.VegaHome, vegacmd.CoreConfigPath)
-	logger.Infof("Updating vega-core config(%s). New parameters: %v", vegaConfigPath, vegaConfig)
-	if err := utils.UpdateConfig(vegaConfigPath, "toml", vegaConfig); err != nil {
-		return fmt.Errorf("failed to update the vega config; %w", err)
-	}
-	logger.Info("Vega-core config updated")
+	logger.Infof("Updating vega-core config(%s). New parameters: %v", vegaConfigPath, utils.RedactSecrets(vegaConfig))
 
 	tendermintConfigPath := filepath.Join(
 		gen.userSettings.TendermintHome,
@@ -319,25 +217,107 @@ func (gen *DataNodeGenerator) updateConfigs(
 	logger.Infof(
 		"Updating tendermint config(%s). New parameters: %v",
 		tendermintConfigPath,
-		tendermintConfig,
+		utils.RedactSecrets(tendermintConfig),
 	)
-	if err := utils.UpdateConfig(tendermintConfigPath, "toml", tendermintConfig); err != nil {
-		return fmt.Errorf("failed to update the tendermint config; %w", err)
-	}
-	logger.Info("Tendermint config updated")
 
 	vegavisorConfigPath := filepath.Join(gen.userSettings.VisorHome, vegacmd.VegavisorConfigPath)
 	logger.Infof(
 		"Updating vegavisor config(%s). New parameters: %v",
 		vegavisorConfigPath,
-		vegavisorConfig,
+		utils.RedactSecrets(vegavisorConfig),
 	)
-	if err := utils.UpdateConfig(vegavisorConfigPath, "toml", vegavisorConfig); err != nil {
-		return fmt.Errorf("failed to update vegavisor config: %w", err)
+
+	if err := gen.updateConfigFiles(logger, []configUpdate{
+		{name: "data-node", path: dataNodeConfigPath, values: dataNodeConfig},
+		{name: "vega-core", path: vegaConfigPath, values: vegaConfig},
+		{name: "tendermint", path: tendermintConfigPath, values: tendermintConfig},
+		{name: "vegavisor", path: vegavisorConfigPath, values: vegavisorConfig},
+	}); err != nil {
+		return err
 	}
-	logger.Info("Vegavisor config updated")
 
-	return nil
+	return gen.applyConfigOverrides(logger)
+}
+
+// checkRPCServers drops tendermint RPC servers that are not reachable or serve a different chain.
+// Statesync requires at least two RPC servers, so it fails when fewer servers are left in the network
+// history mode.
+func (gen *DataNodeGenerator) checkRPCServers(
+	ctx context.Context,
+	logger *zap.SugaredLogger,
+	rpcServers []string,
+) ([]string, error) {
+	logger.Infof("Checking %d tendermint rpc servers", len(rpcServers))
+	reachable, unreachable := network.FilterReachableRPCServers(ctx, rpcServers, gen.userSettings.VegaChainId)
+	for _, rpcServer := range reachable {
+		logger.Infof("Tendermint rpc server %s is reachable", rpcServer)
+	}
+	chainMismatches := 0
+	for rpcServer, err := range unreachable {
+		if errors.Is(err, network.ErrChainIDMismatch) {
+			chainMismatches++
+		}
+		logger.Warnf("Tendermint rpc server %s dropped: %s", rpcServer, err.Error())
+	}
+
+	if len(reachable) < 1 && chainMismatches > 0 {
+		return nil, fmt.Errorf(
+			"none of the tendermint rpc servers serves the %s chain: check the --network or the --network-config flag",
+			gen.userSettings.VegaChainId,
+		)
+	}
+
+	if len(reachable) < 2 {
+		if gen.userSettings.Mode == StartFromNetworkHistory {
+			return nil, fmt.Errorf(
+				"statesync requires at least 2 reachable tendermint rpc servers, got %d: use the --skip-rpc-check flag to skip the check",
+				len(reachable),
+			)
+		}
+
+		logger.Warnf("Only %d tendermint rpc servers are reachable", len(reachable))
+	}
+
+	return reachable, nil
+}
+
+// resolveTrustPeriod returns the trust period set by user, or the one derived from the chain. The derived
+// value is 2/3 of the evidence max age, which tendermint uses as the unbonding window.
+func (gen *DataNodeGenerator) resolveTrustPeriod(
+	ctx context.Context,
+	logger *zap.SugaredLogger,
+	rpcServers []string,
+) (time.Duration, error) {
+	trustPeriod := gen.userSettings.TrustPeriod
+	if !gen.userSettings.DeriveTrustPeriod {
+		if trustPeriod == 0 {
+			trustPeriod = DefaultTrustPeriod
+		}
+		logger.Infof("Using statesync trust period %s", trustPeriod)
+
+		return trustPeriod, nil
+	}
+
+	unbondingWindow, err := gen.vegaApi.EvidenceMaxAge(ctx, rpcServers)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get the chain unbonding window: %w", err)
+	}
+	logger.Infof("The chain unbonding window(evidence max age) is %s", unbondingWindow)
+
+	if trustPeriod == 0 {
+		trustPeriod = unbondingWindow * 2 / 3
+	}
+
+	if trustPeriod >= unbondingWindow {
+		return 0, fmt.Errorf(
+			"trust period(%s) must be shorter than the chain unbonding window(%s)",
+			trustPeriod,
+			unbondingWindow,
+		)
+	}
+	logger.Infof("Using statesync trust period %s", trustPeriod)
+
+	return trustPeriod, nil
 }
 
 func (gen *DataNodeGenerator) selectSnapshotForRestart(
@@ -348,13 +328,26 @@ func (gen *DataNodeGenerator) selectSnapshotForRestart(
 		return &types.CoreSnapshot{}, nil
 	}
 
+	if gen.userSettings.hasTrustedBlock() {
+		logger.Infof(
+			"Using provided trusted block %d(%s): skipping the snapshot selection",
+			gen.userSettings.TrustHeight,
+			gen.userSettings.TrustHash,
+		)
+
+		return &types.CoreSnapshot{
+			BlockHeight: strconv.Itoa(gen.userSettings.TrustHeight),
+			BlockHash:   gen.userSettings.TrustHash,
+		}, nil
+	}
+
 	stats, err := gen.vegaApi.Statistics(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get statistics: %w", err)
 	}
 
 	logger.Info("Fetching network snapshots")
-	snapshots, err := gen.vegaApi.Snapshots(context.Background())
+	snapshots, err := gen.vegaApi.Snapshots(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get core snapshot for trusted block: %w", err)
 	}
@@ -368,7 +361,7 @@ func (gen *DataNodeGenerator) selectSnapshotForRestart(
 	}
 
 	logger.Info("Fetching network history segments")
-	segments, err := gen.vegaApi.NetworkHistorySegments(context.Background(), stats.BlockHeight)
+	segments, err := gen.vegaApi.NetworkHistorySegments(ctx, stats.BlockHeight)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get network-history segments: %w", err)
 	}
@@ -426,6 +419,9 @@ func (gen *DataNodeGenerator) selectSnapshotForRestart(
 
 	// select 3-rd highest segment for restart(latest segments may noy be published to the IPFS yet)
 	selectedSegment := segmentList[2]
+	if gen.userSettings.NetworkHistorySegment != nil {
+		selectedSegment = *gen.userSettings.NetworkHistorySegment
+	}
 	selectedSegmentHeight, err := strconv.Atoi(selectedSegment.ToHeight)
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert height for selected segment to int: %w", err)
@@ -446,8 +442,9 @@ func (gen *DataNodeGenerator) selectSnapshotForRestart(
 
 	if selectedSnapshot == nil {
 		return nil, fmt.Errorf(
-			"failed to find snapshot lower than block %s (3-rd highest segment)",
+			"failed to find snapshot lower than block %s (selected segment %s)",
 			selectedSegment.ToHeight,
+			selectedSegment.HistorySegmentId,
 		)
 	}
 
@@ -457,11 +454,21 @@ func (gen *DataNodeGenerator) selectSnapshotForRestart(
 }
 
 func (gen *DataNodeGenerator) initNode(
+	ctx context.Context,
 	logger *zap.SugaredLogger,
 	visorBinary, vegaBinary string,
 ) error {
+	if gen.userSettings.DryRun {
+		logger.Infof("Dry run: would initialize vegavisor in the %s", gen.userSettings.VisorHome)
+		logger.Infof("Dry run: would initialize tendermint in the %s", gen.userSettings.TendermintHome)
+		logger.Infof("Dry run: would initialize vega in the %s", gen.userSettings.VegaHome)
+		logger.Infof("Dry run: would initialize data-node in the %s", gen.userSettings.DataNodeHome)
+
+		return nil
+	}
+
 	logger.Infof("Initializing vegavisor in the %s", gen.userSettings.VisorHome)
-	if err := vegacmd.InitVisor(visorBinary, gen.userSettings.VisorHome); err != nil {
+	if err := vegacmd.InitVisor(ctx, logger, visorBinary, gen.userSettings.VisorHome); err != nil {
 		return fmt.Errorf(
 			"failed to initialize vegavisor in %s: %w",
 			gen.userSettings.VisorHome,
@@ -471,7 +478,7 @@ func (gen *DataNodeGenerator) initNode(
 	logger.Info("Visor successfully initialized")
 
 	logger.Infof("Initializing tendermint in the %s", gen.userSettings.TendermintHome)
-	if err := vegacmd.InitTendermint(vegaBinary, gen.userSettings.TendermintHome); err != nil {
+	if err := vegacmd.InitTendermint(ctx, logger, vegaBinary, gen.userSettings.TendermintHome); err != nil {
 		return fmt.Errorf(
 			"failed to initialize tendermint in %s: %w",
 			gen.userSettings.TendermintHome,
@@ -481,17 +488,17 @@ func (gen *DataNodeGenerator) initNode(
 	logger.Info("Tendermint successfully initialized")
 
 	logger.Infof("Initializing vega in the %s", gen.userSettings.VegaHome)
-	if err := vegacmd.InitVega(vegaBinary, gen.userSettings.VegaHome, vegacmd.VegaNodeFull); err != nil {
+	if err := vegacmd.InitVega(ctx, logger, vegaBinary, gen.userSettings.VegaHome, gen.userSettings.NodeType); err != nil {
 		return fmt.Errorf(
 			"failed to initialize vega in %s: %w",
 			gen.userSettings.VegaHome,
 			err,
 		)
 	}
-	logger.Info("Visor successfully initialized")
+	logger.Info("Vega successfully initialized")
 
-	logger.Infof("Initializing data-node n the %s", gen.userSettings.DataNodeHome)
-	if err := vegacmd.InitDataNode(vegaBinary, gen.userSettings.DataNodeHome, gen.userSettings.VegaChainId); err != nil {
+	logger.Infof("Initializing data-node in the %s", gen.userSettings.DataNodeHome)
+	if err := vegacmd.InitDataNode(ctx, logger, vegaBinary, gen.userSettings.DataNodeHome, gen.userSettings.VegaChainId); err != nil {
 		return fmt.Errorf(
 			"failed to initialize data-node in %s: %w",
 			gen.userSettings.DataNodeHome,
@@ -0,0 +1,89 @@
+package datanode
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/daniel1302/vega-assistant/network"
+	"github.com/daniel1302/vega-assistant/types"
+	"github.com/daniel1302/vega-assistant/vegaapi"
+	"github.com/daniel1302/vega-assistant/vegacmd"
+)
+
+func TestUpdateConfigsSkipsStatesyncInBlock0Mode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		now := time.Now().UTC().Format(time.RFC3339Nano)
+		fmt.Fprintf(
+			w,
+			`{"statistics":{"chainId":"vega-mainnet-0011","appVersion":"v0.73.4","currentTime":"%s","vegaTime":"%s","blockHeight":"100"}}`,
+			now,
+			now,
+		)
+	}))
+	defer server.Close()
+
+	apiClient, err := vegaapi.NewNetworkAPI([]string{server.URL}, false, server.Client())
+	if err != nil {
+		t.Fatalf("failed to create network api client: %s", err)
+	}
+
+	homes := t.TempDir()
+	settings := GenerateSettings{
+		Mode:              StartFromBlock0,
+		SkipRPCCheck:      true,
+		VisorHome:         filepath.Join(homes, "vegavisor"),
+		VegaHome:          filepath.Join(homes, "vega"),
+		TendermintHome:    filepath.Join(homes, "tendermint"),
+		DataNodeHome:      filepath.Join(homes, "vega"),
+		VegaBinaryVersion: "v0.73.4",
+	}
+	tendermintConfigPath := filepath.Join(settings.TendermintHome, vegacmd.TenderminConfigPath)
+	for _, configPath := range []string{
+		filepath.Join(settings.DataNodeHome, vegacmd.DataNodeConfigPath),
+		filepath.Join(settings.VegaHome, vegacmd.CoreConfigPath),
+		tendermintConfigPath,
+		filepath.Join(settings.VisorHome, vegacmd.VegavisorConfigPath),
+	} {
+		if err := os.MkdirAll(filepath.Dir(configPath), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(configPath, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	gen, err := NewDataNodeGenerator(apiClient, settings, network.NetworkConfig{
+		Repository:           "vegaprotocol/vega",
+		TendermintRPCServers: []types.EndpointWithVegaREST{{Endpoint: "rpc.vega.xyz:26657", REST: server.URL}},
+		BootstrapPeers:       []types.EndpointWithVegaREST{{Endpoint: "/dns/api.vega.xyz/tcp/4001", REST: server.URL}},
+	})
+	if err != nil {
+		t.Fatalf("failed to create generator: %s", err)
+	}
+
+	if err := gen.updateConfigs(context.Background(), zap.NewNop().Sugar(), &types.CoreSnapshot{}); err != nil {
+		t.Fatalf("failed to update configs: %s", err)
+	}
+
+	content, err := os.ReadFile(tendermintConfigPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "enable = false") {
+		t.Errorf("expected statesync to be disabled:\n%s", content)
+	}
+	for _, key := range []string{"rpc_servers", "trust_period", "trust_height", "trust_hash"} {
+		if strings.Contains(string(content), key) {
+			t.Errorf("expected no statesync %s in the block 0 mode:\n%s", key, content)
+		}
+	}
+}
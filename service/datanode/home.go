@@ -0,0 +1,49 @@
+package datanode
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/daniel1302/vega-assistant/utils"
+	"github.com/daniel1302/vega-assistant/vegacmd"
+)
+
+// ValidateExistingHomes checks the homes were already initialized with the vegavisor, vega, tendermint
+// and data-node init commands, so they can be reused without the init.
+func ValidateExistingHomes(settings GenerateSettings) error {
+	requiredFiles := []struct {
+		name string
+		path string
+	}{
+		{name: "vegavisor config", path: filepath.Join(settings.VisorHome, vegacmd.VegavisorConfigPath)},
+		{name: "vega config", path: filepath.Join(settings.VegaHome, vegacmd.CoreConfigPath)},
+		{name: "tendermint config", path: filepath.Join(settings.TendermintHome, vegacmd.TenderminConfigPath)},
+		{name: "tendermint genesis", path: filepath.Join(settings.TendermintHome, vegacmd.GenesisPath)},
+		{name: "data-node config", path: filepath.Join(settings.DataNodeHome, vegacmd.DataNodeConfigPath)},
+	}
+
+	missingFiles := []string{}
+	for _, file := range requiredFiles {
+		if !utils.FileExists(file.path) {
+			missingFiles = append(missingFiles, fmt.Sprintf("%s(%s)", file.name, file.path))
+		}
+	}
+
+	if len(missingFiles) > 0 {
+		return fmt.Errorf(
+			"homes are not initialized, missing files: %v: run the setup without the --reuse-home flag",
+			missingFiles,
+		)
+	}
+
+	return nil
+}
+
+// deriveHomes sets all the node homes in the HomeBase directory, so the common single disk layout does not
+// require the separate home for each component
+func (settings *GenerateSettings) deriveHomes() {
+	settings.VisorHome = filepath.Join(settings.HomeBase, "vegavisor")
+	settings.VegaHome = filepath.Join(settings.HomeBase, "vega")
+	settings.TendermintHome = filepath.Join(settings.HomeBase, "tendermint")
+	settings.DataNodeHome = filepath.Join(settings.HomeBase, "data-node")
+}
@@ -0,0 +1,73 @@
+package datanode
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/daniel1302/vega-assistant/types"
+	"github.com/daniel1302/vega-assistant/utils"
+)
+
+// Environment variables passed to the post-setup hook
+const (
+	HookEnvVisorHome      = "VEGA_ASSISTANT_VISOR_HOME"
+	HookEnvVegaHome       = "VEGA_ASSISTANT_VEGA_HOME"
+	HookEnvTendermintHome = "VEGA_ASSISTANT_TENDERMINT_HOME"
+	HookEnvDataNodeHome   = "VEGA_ASSISTANT_DATA_NODE_HOME"
+	HookEnvChainID        = "VEGA_ASSISTANT_CHAIN_ID"
+)
+
+func (settings GenerateSettings) validatePostSetupHook() error {
+	if settings.PostSetupHook == "" {
+		return nil
+	}
+
+	if !utils.IsExecutable(settings.PostSetupHook) {
+		return fmt.Errorf("invalid post-setup hook(%s): expected executable file", settings.PostSetupHook)
+	}
+
+	return nil
+}
+
+// hookEnv returns the environment variables describing the node set up. The data-node home is empty for
+// the validator.
+func (settings GenerateSettings) hookEnv() []string {
+	return []string{
+		fmt.Sprintf("%s=%s", HookEnvVisorHome, settings.VisorHome),
+		fmt.Sprintf("%s=%s", HookEnvVegaHome, settings.VegaHome),
+		fmt.Sprintf("%s=%s", HookEnvTendermintHome, settings.TendermintHome),
+		fmt.Sprintf("%s=%s", HookEnvDataNodeHome, settings.DataNodeHome),
+		fmt.Sprintf("%s=%s", HookEnvChainID, settings.VegaChainId),
+	}
+}
+
+// runPostSetupHook runs the user executable after the node is set up. The hook output is logged while
+// it runs. The hook is killed when ctx is done.
+func (gen *nodeGenerator) runPostSetupHook(ctx context.Context, logger *zap.SugaredLogger) error {
+	hookPath := gen.userSettings.PostSetupHook
+	if hookPath == "" {
+		return nil
+	}
+
+	if gen.userSettings.DryRun {
+		logger.Infof("Dry run: would run the post-setup hook %s", hookPath)
+
+		return nil
+	}
+
+	logger.Infof("Running the post-setup hook %s", hookPath)
+	if _, err := utils.ExecuteBinaryContextEnv(ctx, logger, hookPath, nil, gen.userSettings.hookEnv()); err != nil {
+		if gen.userSettings.IgnoreHookErrors && ctx.Err() == nil {
+			logger.Warnf("The post-setup hook failed, the error is ignored: %s", err.Error())
+
+			return nil
+		}
+
+		return types.NewHookError(fmt.Errorf("post-setup hook failed: %w", err))
+	}
+	logger.Info("The post-setup hook completed")
+
+	return nil
+}
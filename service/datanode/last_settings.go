@@ -0,0 +1,105 @@
+package datanode
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/daniel1302/vega-assistant/utils"
+)
+
+// DefaultLastSettingsPath returns the file with the settings of the last successful setup
+func DefaultLastSettingsPath() string {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		configDir = filepath.Join(utils.CurrentUserHomePath(), ".config")
+	}
+
+	return filepath.Join(configDir, "vega-assistant", "last.json")
+}
+
+// SaveLastSettings writes the settings of the successful setup, so they are the defaults for the next
+// setup. Passwords are never written.
+func SaveLastSettings(filePath string, settings GenerateSettings) error {
+	settings.SQLCredentials.Pass = ""
+	settings.SQLAdminPassword = ""
+	// The connection url may contain the password
+	settings.DatabaseURL = ""
+
+	content, err := json.MarshalIndent(settings, "", "    ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal last settings: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o700); err != nil {
+		return fmt.Errorf("failed to create last settings dir: %w", err)
+	}
+
+	if err := os.WriteFile(filePath, content, 0o600); err != nil {
+		return fmt.Errorf("failed to write last settings file: %w", err)
+	}
+
+	return nil
+}
+
+// LoadLastSettings returns the default settings with the values asked by the prompts taken from the last
+// successful setup. Other settings, e.g. the dry run or the force, are not reused. Homes that exist now
+// are replaced with the default ones, the last setup usually created them. The defaults are returned
+// when there is no last settings file.
+func LoadLastSettings(filePath string) (*GenerateSettings, error) {
+	result := DefaultGenerateSettings()
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return result, nil
+		}
+
+		return nil, fmt.Errorf("failed to read last settings file: %w", err)
+	}
+
+	last := GenerateSettings{}
+	if err := json.Unmarshal(content, &last); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal last settings file: %w", err)
+	}
+
+	if IsStartupModeValid(last.Mode) {
+		result.Mode = last.Mode
+	}
+	if last.DataRetention != "" {
+		result.DataRetention = last.DataRetention
+	}
+	if last.NetworkHistoryMinBlockCount > 0 {
+		result.NetworkHistoryMinBlockCount = last.NetworkHistoryMinBlockCount
+	}
+
+	for _, home := range []struct {
+		last   string
+		result *string
+	}{
+		{last: last.VisorHome, result: &result.VisorHome},
+		{last: last.VegaHome, result: &result.VegaHome},
+		{last: last.TendermintHome, result: &result.TendermintHome},
+	} {
+		if home.last == "" || !filepath.IsAbs(home.last) {
+			continue
+		}
+
+		if _, err := os.Stat(home.last); err == nil {
+			continue
+		}
+
+		*home.result = home.last
+	}
+
+	if last.SQLCredentials.Host != "" {
+		// Password is never saved, so the default one is kept
+		password := result.SQLCredentials.Pass
+		result.SQLCredentials = last.SQLCredentials
+		result.SQLCredentials.Pass = password
+	}
+
+	return result, nil
+}
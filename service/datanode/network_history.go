@@ -0,0 +1,45 @@
+package datanode
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/daniel1302/vega-assistant/types"
+	"github.com/daniel1302/vega-assistant/vegaapi"
+)
+
+// SelectNetworkHistorySegment finds the network-history segment containing the fromHeight block, so the
+// data-node initialises from the pinned segment instead of the latest one.
+func SelectNetworkHistorySegment(
+	ctx context.Context,
+	apiClient *vegaapi.NetworkAPI,
+	networkHeight uint64,
+	fromHeight uint64,
+) (*types.NetworkHistorySegment, error) {
+	segments, err := apiClient.NetworkHistorySegments(ctx, networkHeight)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get network-history segments: %w", err)
+	}
+
+	for idx, segment := range segments.Segments {
+		segmentFromHeight, err := strconv.ParseUint(segment.FromHeight, 10, 64)
+		if err != nil {
+			continue
+		}
+		segmentToHeight, err := strconv.ParseUint(segment.ToHeight, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		if segment.HistorySegmentId != "" && segmentFromHeight <= fromHeight && fromHeight <= segmentToHeight {
+			return &segments.Segments[idx], nil
+		}
+	}
+
+	return nil, fmt.Errorf(
+		"no network-history segment contains the block %d: %d segments available",
+		fromHeight,
+		len(segments.Segments),
+	)
+}
@@ -0,0 +1,1245 @@
+package datanode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/go-multierror"
+	"go.uber.org/zap"
+
+	"github.com/daniel1302/vega-assistant/github"
+	"github.com/daniel1302/vega-assistant/network"
+	"github.com/daniel1302/vega-assistant/types"
+	"github.com/daniel1302/vega-assistant/utils"
+	"github.com/daniel1302/vega-assistant/vega"
+	"github.com/daniel1302/vega-assistant/vegaapi"
+	"github.com/daniel1302/vega-assistant/vegacmd"
+)
+
+var defaultMinFreeSpace = map[StartupMode]uint64{
+	StartFromBlock0:         1 << 40,   // 1TB
+	StartFromNetworkHistory: 100 << 30, // 100GB
+}
+
+// minDownloadFreeSpace is required in the download dir for the vega and the visor binaries with their
+// archives and the genesis
+const minDownloadFreeSpace = 1 << 30 // 1GB
+
+type ConfigBackup struct {
+	ConfigPath string
+	BackupPath string
+}
+
+// nodeGenerator contains steps shared by the data-node and the validator setup: binaries download,
+// the visor home, the genesis and the config updates.
+type nodeGenerator struct {
+	vegaApi       *vegaapi.NetworkAPI
+	userSettings  GenerateSettings
+	networkConfig network.NetworkConfig
+	// withDataNode adds the data-node process to the visor run config
+	withDataNode bool
+
+	// configBackupsMu guards the configBackups appended by the concurrent config updates
+	configBackupsMu sync.Mutex
+	configBackups   []ConfigBackup
+	progress        *SetupProgress
+	// createdPaths are created by this run, paths created by the resumed setup are only in the progress
+	createdPaths []string
+}
+
+// ErrCancelled is returned when the setup is interrupted, e.g. with the Ctrl-C
+var ErrCancelled = errors.New("setup cancelled")
+
+// ErrSetupTimeout is returned together with the ErrCancelled when the ctx deadline is exceeded before the
+// setup completes
+var ErrSetupTimeout = errors.New("setup exceeded timeout")
+
+// nodeSetup contains the steps specific for the node type
+type nodeSetup struct {
+	initNode      func(ctx context.Context, logger *zap.SugaredLogger, visorBinary, vegaBinary string) error
+	updateConfigs func(ctx context.Context, logger *zap.SugaredLogger) error
+}
+
+// run executes the setup steps. Completed steps are saved in the progress, so the failed setup can be resumed.
+// The ErrCancelled is returned when ctx is done before the setup completes.
+func (gen *nodeGenerator) run(ctx context.Context, logger *zap.SugaredLogger, setup nodeSetup) (runErr error) {
+	defer func() {
+		if runErr == nil || ctx.Err() == nil {
+			return
+		}
+
+		if !errors.Is(runErr, ErrCancelled) {
+			runErr = fmt.Errorf("%w: %w", ErrCancelled, runErr)
+		}
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			runErr = fmt.Errorf("%w: %w", ErrSetupTimeout, runErr)
+		}
+	}()
+
+	if gen.userSettings.DryRun {
+		logger.Info("Dry run: no files will be written and no binaries will be downloaded")
+	}
+
+	if err := gen.checkFreeSpace(logger); err != nil {
+		return fmt.Errorf("failed to check free disk space: %w", err)
+	}
+
+	if err := gen.checkPortsFree(logger); err != nil {
+		return fmt.Errorf("failed to check node ports: %w", err)
+	}
+
+	if gen.userSettings.ReuseHome {
+		logger.Info("Binaries are not installed for reused homes: skipping the target platform check")
+	} else if gen.networkConfig.BinaryMirrorURL != "" {
+		logger.Info("Binaries are downloaded from the network binary mirror: skipping the GitHub target platform check")
+	} else if err := gen.checkTargetPlatform(logger); err != nil {
+		return fmt.Errorf("invalid target platform, use the --target-os and --target-arch flags to select the available one: %w", err)
+	}
+
+	if err := gen.removeExistingHomes(logger); err != nil {
+		return fmt.Errorf("failed to remove existing homes: %w", err)
+	}
+
+	if err := gen.cleanExistingHomes(logger); err != nil {
+		return fmt.Errorf("failed to clean existing homes: %w", err)
+	}
+
+	outputDir, err := gen.makeTempDir(logger)
+	if err != nil {
+		return fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer func() {
+		gen.removeTempDir(logger, outputDir, runErr != nil)
+	}()
+
+	var cache *github.ArtifactCache
+	if gen.userSettings.NoCache {
+		logger.Info("Binaries cache is disabled")
+	} else {
+		cache = github.NewArtifactCache(github.DefaultCacheDir())
+	}
+
+	progress, err := gen.loadProgress(logger)
+	if err != nil {
+		return fmt.Errorf("failed to load setup progress: %w", err)
+	}
+	gen.progress = progress
+
+	if gen.userSettings.ReuseHome {
+		// Reused homes are not tracked as created paths, so the rollback does not remove them
+		logger.Info("Reusing existing homes: skipping the node init, binaries and genesis setup")
+		if err := gen.runStep(ctx, logger, progress, StepUpdateConfigs, func() error {
+			return setup.updateConfigs(ctx, logger)
+		}); err != nil {
+			return types.NewConfigError(fmt.Errorf("failed to update config files for the node: %w", err))
+		}
+
+		if err := gen.chownHomes(logger); err != nil {
+			return types.NewInitError(fmt.Errorf("failed to change owner of the homes: %w", err))
+		}
+
+		if !gen.userSettings.DryRun {
+			if err := progress.Remove(); err != nil {
+				return err
+			}
+		}
+
+		return gen.runPostSetupHook(ctx, logger)
+	}
+
+	for _, home := range []string{
+		gen.userSettings.VisorHome,
+		gen.userSettings.VegaHome,
+		gen.userSettings.TendermintHome,
+		gen.userSettings.DataNodeHome,
+	} {
+		// Validator has no data-node home
+		if home == "" {
+			continue
+		}
+		gen.trackCreatedPath(home)
+	}
+
+	// Binaries are not needed when all steps using them are completed
+	var vegaBinaryPath, visorBinaryPath string
+	if !progress.Completed(StepInitNode) || !progress.Completed(StepCopyBinaries) {
+		vegaBinaryPath, visorBinaryPath, err = gen.prepareBinaries(ctx, logger, outputDir, cache)
+		if err != nil {
+			return types.NewDownloadError(err)
+		}
+	}
+
+	if err := gen.runStep(ctx, logger, progress, StepInitNode, func() error {
+		return setup.initNode(ctx, logger, visorBinaryPath, vegaBinaryPath)
+	}); err != nil {
+		return types.NewInitError(fmt.Errorf("failed to init vega node: %w", err))
+	}
+
+	if err := gen.runStep(ctx, logger, progress, StepPrepareVisorHome, func() error {
+		return gen.prepareVisorHome(logger)
+	}); err != nil {
+		return types.NewInitError(fmt.Errorf("failed to prepare visor home: %w", err))
+	}
+
+	if !progress.Completed(StepCopyBinaries) && gen.userSettings.TargetPlatform() != github.CurrentPlatform() {
+		vegaBinaryPath, visorBinaryPath, err = gen.prepareTargetBinaries(ctx, logger, outputDir, cache)
+		if err != nil {
+			return types.NewDownloadError(err)
+		}
+	}
+
+	if err := gen.runStep(ctx, logger, progress, StepCopyBinaries, func() error {
+		return gen.copyBinaries(ctx, logger, vegaBinaryPath, visorBinaryPath)
+	}); err != nil {
+		return types.NewInitError(fmt.Errorf("failed to copy binaries to visor home: %w", err))
+	}
+
+	if err := gen.runStep(ctx, logger, progress, StepDownloadGenesis, func() error {
+		return gen.downloadGenesis(ctx, logger, outputDir)
+	}); err != nil {
+		return types.NewGenesisError(fmt.Errorf("failed to download genesis: %w", err))
+	}
+
+	if err := gen.runStep(ctx, logger, progress, StepUpdateConfigs, func() error {
+		return setup.updateConfigs(ctx, logger)
+	}); err != nil {
+		return types.NewConfigError(fmt.Errorf("failed to update config files for the node: %w", err))
+	}
+
+	if err := gen.chownHomes(logger); err != nil {
+		return types.NewInitError(fmt.Errorf("failed to change owner of the homes: %w", err))
+	}
+
+	if !gen.userSettings.DryRun {
+		if err := progress.Remove(); err != nil {
+			return err
+		}
+	}
+
+	return gen.runPostSetupHook(ctx, logger)
+}
+
+func (gen *nodeGenerator) prepareBinaries(
+	ctx context.Context,
+	logger *zap.SugaredLogger,
+	outputDir string,
+	cache *github.ArtifactCache,
+) (string, string, error) {
+	vegaBinaryPath, vegaVersion, err := gen.prepareBinary(
+		ctx,
+		logger,
+		gen.userSettings.VegaBinaryPath,
+		gen.userSettings.VegaBinaryVersion,
+		outputDir,
+		github.ArtifactVega,
+		cache,
+	)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to prepare vega binary: %w", err)
+	}
+	logger.Infof("Vega binary is %s", vegaBinaryPath)
+	logger.Infof("Vega version is %s", vegaVersion)
+
+	visorBinaryPath, visorVersion, err := gen.prepareBinary(
+		ctx,
+		logger,
+		gen.userSettings.VisorBinaryPath,
+		gen.userSettings.VisorBinaryVersion,
+		outputDir,
+		github.ArtifactVisor,
+		cache,
+	)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to prepare visor binary: %w", err)
+	}
+	logger.Infof("Visor binary is %s", visorBinaryPath)
+	logger.Infof("Visor version is %s", visorVersion)
+
+	return vegaBinaryPath, visorBinaryPath, nil
+}
+
+// loadProgress returns progress of the resumed setup, or empty progress when setup starts from scratch
+func (gen *nodeGenerator) loadProgress(logger *zap.SugaredLogger) (*SetupProgress, error) {
+	if !gen.userSettings.Resume {
+		return NewSetupProgress(gen.userSettings), nil
+	}
+
+	progress, err := LoadSetupProgress(gen.userSettings.VisorHome)
+	if err != nil {
+		return nil, err
+	}
+
+	if progress == nil {
+		logger.Infof("No setup progress found in %s, starting from scratch", gen.userSettings.VisorHome)
+
+		return NewSetupProgress(gen.userSettings), nil
+	}
+
+	if err := progress.Matches(gen.userSettings); err != nil {
+		return nil, err
+	}
+	logger.Infof("Resuming setup. Completed steps: %v", progress.CompletedSteps)
+
+	return progress, nil
+}
+
+// runStep skips steps completed in the previous run and saves the progress after the step is done.
+// The step is not started when ctx is done.
+func (gen *nodeGenerator) runStep(
+	ctx context.Context,
+	logger *zap.SugaredLogger,
+	progress *SetupProgress,
+	step SetupStep,
+	stepFunc func() error,
+) error {
+	if progress.Completed(step) {
+		logger.Infof("Step %s already completed, skipping", step)
+
+		return nil
+	}
+
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%w before the %s step: %w", ErrCancelled, step, err)
+	}
+
+	if err := stepFunc(); err != nil {
+		// Paths created by the failed step are saved for the cleanup command
+		if !gen.userSettings.DryRun && utils.FileExists(gen.userSettings.VisorHome) {
+			if saveErr := progress.Save(); saveErr != nil {
+				logger.Errorf("Failed to save setup progress: %s", saveErr.Error())
+			}
+		}
+
+		return err
+	}
+
+	if gen.userSettings.DryRun {
+		return nil
+	}
+
+	return progress.MarkCompleted(step)
+}
+
+// removeExistingHomes removes the existing homes the user agreed to remove. They are removed only after
+// the setup is confirmed and the homes are validated.
+func (gen *nodeGenerator) removeExistingHomes(logger *zap.SugaredLogger) error {
+	for _, home := range gen.userSettings.HomesToRemove {
+		if gen.userSettings.DryRun {
+			logger.Infof("Dry run: %s not removed", home)
+			continue
+		}
+
+		logger.Infof("Removing existing home %s", home)
+		if err := os.RemoveAll(home); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", home, err)
+		}
+	}
+
+	return nil
+}
+
+// cleanExistingHomes removes contents of the existing homes selected for the Force setting. They are removed
+// only after the setup is confirmed and the homes are validated.
+func (gen *nodeGenerator) cleanExistingHomes(logger *zap.SugaredLogger) error {
+	for _, home := range gen.userSettings.HomesToClean {
+		if gen.userSettings.DryRun {
+			logger.Infof("Dry run: contents of %s not removed", home)
+			continue
+		}
+
+		logger.Warnf("Force: removing everything inside %s", home)
+		if err := utils.RemoveDirContents(logger, home); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// trackCreatedPath saves the path for the rollback and the cleanup. It must be called before the path is
+// created: paths existing before this run, e.g. homes kept with the --force or the --resume, are not tracked,
+// so the rollback does not remove them.
+func (gen *nodeGenerator) trackCreatedPath(path string) {
+	if gen.userSettings.DryRun || gen.progress == nil || utils.FileExists(path) {
+		return
+	}
+
+	if !slices.Contains(gen.createdPaths, path) {
+		gen.createdPaths = append(gen.createdPaths, path)
+	}
+	gen.progress.TrackCreatedPath(path)
+}
+
+// chownHomes gives the homes to the Owner, e.g. to the service user when the setup runs as root. Only root
+// can change the owner, so it is skipped with the warning for other users.
+func (gen *nodeGenerator) chownHomes(logger *zap.SugaredLogger) error {
+	if gen.userSettings.Owner == "" {
+		return nil
+	}
+
+	homes := []string{}
+	for _, home := range []string{
+		gen.userSettings.VisorHome,
+		gen.userSettings.VegaHome,
+		gen.userSettings.TendermintHome,
+		gen.userSettings.DataNodeHome,
+	} {
+		// Data-node shares the home with vega, validator has no data-node home
+		if home != "" && !slices.Contains(homes, home) {
+			homes = append(homes, home)
+		}
+	}
+
+	if os.Geteuid() != 0 {
+		logger.Warnf("Owner of the homes is not changed to %s: only root can change the owner", gen.userSettings.Owner)
+
+		return nil
+	}
+
+	uid, gid, err := utils.LookupOwner(gen.userSettings.Owner)
+	if err != nil {
+		return err
+	}
+
+	for _, home := range homes {
+		if gen.userSettings.DryRun {
+			logger.Infof("Dry run: owner of %s not changed to %s", home, gen.userSettings.Owner)
+			continue
+		}
+
+		logger.Infof("Changing owner of %s to %s", home, gen.userSettings.Owner)
+		if err := utils.ChownRecursive(home, uid, gid); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Rollback removes paths created by the assistant during the failed run
+func (gen *nodeGenerator) Rollback(logger *zap.SugaredLogger) error {
+	if gen.progress == nil || len(gen.createdPaths) < 1 {
+		logger.Info("Nothing to rollback")
+
+		return nil
+	}
+
+	if err := removeCreatedPaths(logger, gen.createdPaths); err != nil {
+		return err
+	}
+
+	// Paths created by the resumed setup are left for the cleanup command
+	gen.progress.CreatedPaths = slices.DeleteFunc(gen.progress.CreatedPaths, func(path string) bool {
+		return slices.Contains(gen.createdPaths, path)
+	})
+	gen.createdPaths = nil
+	if len(gen.progress.CreatedPaths) < 1 {
+		return gen.progress.Remove()
+	}
+	if !utils.FileExists(gen.progress.VisorHome) {
+		return nil
+	}
+
+	return gen.progress.Save()
+}
+
+// CreatedPaths returns paths created by this run of the assistant
+func (gen *nodeGenerator) CreatedPaths() []string {
+	return gen.createdPaths
+}
+
+// prepareBinary returns the local binary when localBinaryPath is given,
+// otherwise the binary is downloaded.
+func (gen *nodeGenerator) prepareBinary(
+	ctx context.Context,
+	logger *zap.SugaredLogger,
+	localBinaryPath, version, outputDir string,
+	artifactType github.ArtifactType,
+	cache *github.ArtifactCache,
+) (string, string, error) {
+	if localBinaryPath == "" {
+		if gen.userSettings.DryRun {
+			logger.Infof("Dry run: would download %s binary %s from %s", artifactType, version, gen.networkConfig.Repository)
+			return filepath.Join(outputDir, string(artifactType)), version, nil
+		}
+
+		logger.Infof("Downloading %s binary", artifactType)
+		return gen.downloadBinary(ctx, logger, version, outputDir, artifactType, cache)
+	}
+
+	logger.Infof("Using local %s binary %s", artifactType, localBinaryPath)
+	if !utils.FileExists(localBinaryPath) {
+		return "", "", fmt.Errorf("local %s binary %s does not exist", artifactType, localBinaryPath)
+	}
+	if !utils.IsExecutable(localBinaryPath) {
+		return "", "", fmt.Errorf("local %s binary %s is not executable", artifactType, localBinaryPath)
+	}
+
+	binaryVersion, err := gen.checkBinaryVersion(localBinaryPath, version)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to check %s version: %w", artifactType, err)
+	}
+
+	return localBinaryPath, binaryVersion, nil
+}
+
+type artifactDownloader func(
+	ctx context.Context,
+	source github.ReleaseSource,
+	version, outputDir string,
+	artifactType github.ArtifactType,
+	platform github.Platform,
+	cache *github.ArtifactCache,
+) (string, error)
+
+func (gen *nodeGenerator) artifactDownloader(logger *zap.SugaredLogger, artifactType github.ArtifactType) artifactDownloader {
+	if gen.userSettings.SkipChecksum {
+		logger.Infof("Checksum verification for the %s binary is disabled", artifactType)
+
+		return github.DownloadArtifact
+	}
+
+	return github.DownloadArtifactVerified
+}
+
+// checkTargetPlatform makes sure the release publishes binaries for the target platform, so typo or
+// architecture missing in the release fails before anything is downloaded
+func (gen *nodeGenerator) checkTargetPlatform(logger *zap.SugaredLogger) error {
+	platform := gen.userSettings.TargetPlatform()
+	crossPlatform := platform != github.CurrentPlatform()
+
+	for _, artifact := range []struct {
+		artifactType    github.ArtifactType
+		version         string
+		localBinaryPath string
+	}{
+		{artifactType: github.ArtifactVega, version: gen.userSettings.VegaBinaryVersion, localBinaryPath: gen.userSettings.VegaBinaryPath},
+		{artifactType: github.ArtifactVisor, version: gen.userSettings.VisorBinaryVersion, localBinaryPath: gen.userSettings.VisorBinaryPath},
+	} {
+		// Local binaries are used for the current platform, so nothing is downloaded
+		if !crossPlatform && artifact.localBinaryPath != "" {
+			continue
+		}
+
+		logger.Infof("Checking release %s publishes the %s binary for the %s platform", artifact.version, artifact.artifactType, platform)
+		err := github.ValidatePlatform(gen.networkConfig.ReleaseSource(), artifact.version, artifact.artifactType, platform)
+		if errors.Is(err, github.ErrAssetNotPublished) {
+			return err
+		}
+		// The download reports the error, when release cannot be checked, e.g. due to the rate limits
+		if err != nil {
+			logger.Warnf("Failed to check assets of the %s release: %s", artifact.version, err.Error())
+		}
+	}
+
+	return nil
+}
+
+// prepareTargetBinaries downloads binaries for the target platform, when it differs from the current one.
+// Binaries for the current platform are still required to init the node and check versions.
+func (gen *nodeGenerator) prepareTargetBinaries(
+	ctx context.Context,
+	logger *zap.SugaredLogger,
+	outputDir string,
+	cache *github.ArtifactCache,
+) (string, string, error) {
+	platform := gen.userSettings.TargetPlatform()
+	targetOutputDir := filepath.Join(outputDir, platform.String())
+	if gen.userSettings.DryRun {
+		logger.Infof("Dry run: would download vega and visor binaries for the %s platform", platform)
+
+		return filepath.Join(targetOutputDir, string(github.ArtifactVega)), filepath.Join(targetOutputDir, string(github.ArtifactVisor)), nil
+	}
+
+	if err := os.MkdirAll(targetOutputDir, utils.DirMode); err != nil {
+		return "", "", fmt.Errorf("failed to create dir for the %s binaries: %w", platform, err)
+	}
+
+	binaryPaths := map[github.ArtifactType]string{}
+	for artifactType, version := range map[github.ArtifactType]string{
+		github.ArtifactVega:  gen.userSettings.VegaBinaryVersion,
+		github.ArtifactVisor: gen.userSettings.VisorBinaryVersion,
+	} {
+		logger.Infof("Downloading %s binary for the %s platform", artifactType, platform)
+		downloadArtifact := gen.artifactDownloader(logger, artifactType)
+		binaryPath, err := downloadArtifact(ctx, gen.networkConfig.ReleaseSource(), version, targetOutputDir, artifactType, platform, cache)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to download %s binary for the %s platform: %w", artifactType, platform, err)
+		}
+		binaryPaths[artifactType] = binaryPath
+	}
+
+	return binaryPaths[github.ArtifactVega], binaryPaths[github.ArtifactVisor], nil
+}
+
+// downloadBinary downloads the binary and checks its version. When the version
+// check fails, the cached binary is invalidated and downloaded again.
+func (gen *nodeGenerator) downloadBinary(
+	ctx context.Context,
+	logger *zap.SugaredLogger,
+	version, outputDir string,
+	artifactType github.ArtifactType,
+	cache *github.ArtifactCache,
+) (string, string, error) {
+	downloadArtifact := gen.artifactDownloader(logger, artifactType)
+	platform := github.CurrentPlatform()
+	binaryPath, err := downloadArtifact(ctx, gen.networkConfig.ReleaseSource(), version, outputDir, artifactType, platform, cache)
+	if err != nil {
+		return "", "", err
+	}
+
+	binaryVersion, err := gen.checkBinaryVersion(binaryPath, version)
+	if err == nil {
+		return binaryPath, binaryVersion, nil
+	}
+
+	if cache == nil {
+		return "", "", fmt.Errorf("failed to check %s version: %w", artifactType, err)
+	}
+
+	logger.Infof("Failed to check %s version, invalidating cached binary: %s", artifactType, err.Error())
+	if err := cache.Invalidate(gen.networkConfig.Repository, version, artifactType, platform); err != nil {
+		return "", "", fmt.Errorf("failed to invalidate cached %s binary: %w", artifactType, err)
+	}
+
+	binaryPath, err = downloadArtifact(ctx, gen.networkConfig.ReleaseSource(), version, outputDir, artifactType, platform, nil)
+	if err != nil {
+		return "", "", err
+	}
+
+	binaryVersion, err = gen.checkBinaryVersion(binaryPath, version)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to check %s version: %w", artifactType, err)
+	}
+
+	return binaryPath, binaryVersion, nil
+}
+
+func (gen *nodeGenerator) checkBinaryVersion(binaryPath, expectedVersion string) (string, error) {
+	binaryVersion, err := vegacmd.BinaryVersion(binaryPath)
+	if err != nil {
+		return "", err
+	}
+
+	if !gen.userSettings.AllowVersionMismatch && !vegacmd.VersionsMatch(binaryVersion, expectedVersion) {
+		return "", fmt.Errorf(
+			"binary version mismatch: requested %s, got %s",
+			expectedVersion,
+			binaryVersion,
+		)
+	}
+
+	return binaryVersion, nil
+}
+
+func (gen *nodeGenerator) downloadGenesis(ctx context.Context, logger *zap.SugaredLogger, outputDir string) error {
+	genesisDestination := filepath.Join(gen.userSettings.TendermintHome, vegacmd.GenesisPath)
+	genesisSource := gen.userSettings.GenesisFile
+
+	if gen.userSettings.DryRun {
+		if genesisSource == "" {
+			genesisSource = strings.Join(gen.networkConfig.GenesisSources(), " or ")
+		}
+		logger.Infof("Dry run: would copy genesis.json file from %s to %s", genesisSource, genesisDestination)
+
+		return nil
+	}
+
+	if genesisSource == "" {
+		downloadedGenesis, err := gen.downloadGenesisFromMirrors(ctx, logger, outputDir)
+		if err != nil {
+			return err
+		}
+		genesisSource = downloadedGenesis
+	} else if err := gen.verifyGenesis(logger, genesisSource); err != nil {
+		return err
+	}
+
+	logger.Infof("Copying genesis.json file from %s to %s", genesisSource, genesisDestination)
+	if err := utils.CopyFile(genesisSource, genesisDestination); err != nil {
+		return fmt.Errorf("failed to copy genesis: %w", err)
+	}
+	logger.Infof("Genesis copied to %s", genesisDestination)
+
+	return nil
+}
+
+// downloadGenesisFromMirrors tries the genesis mirrors from the network config in order and returns
+// path to the first genesis that passes the verification
+func (gen *nodeGenerator) downloadGenesisFromMirrors(
+	ctx context.Context,
+	logger *zap.SugaredLogger,
+	outputDir string,
+) (string, error) {
+	genesisPath := filepath.Join(outputDir, "genesis.json")
+
+	var mirrorsErr error
+	for _, genesisURL := range gen.networkConfig.GenesisSources() {
+		logger.Infof("Downloading genesis.json file from %s", genesisURL)
+		err := utils.DownloadFileContext(ctx, genesisURL, genesisPath, nil)
+		if err == nil {
+			err = gen.verifyGenesis(logger, genesisPath)
+		}
+		if err == nil {
+			logger.Infof("Genesis downloaded from %s to %s", genesisURL, genesisPath)
+
+			return genesisPath, nil
+		}
+
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("failed to download genesis: %w", err)
+		}
+		// The next mirror must not resume the file partially downloaded from this one
+		os.Remove(fmt.Sprintf("%s.part", genesisPath))
+
+		logger.Warnf("Failed to get the genesis from %s: %s", genesisURL, err.Error())
+		mirrorsErr = multierror.Append(mirrorsErr, fmt.Errorf("%s: %w", genesisURL, err))
+	}
+
+	if mirrorsErr == nil {
+		return "", fmt.Errorf("failed to download genesis: the network config does not define any genesis url")
+	}
+
+	return "", fmt.Errorf("failed to download genesis from all the mirrors: %w", mirrorsErr)
+}
+
+// verifyGenesis checks the genesis checksum and validates the genesis content
+func (gen *nodeGenerator) verifyGenesis(logger *zap.SugaredLogger, genesisPath string) error {
+	if err := gen.verifyGenesisChecksum(logger, genesisPath); err != nil {
+		return err
+	}
+
+	logger.Infof("Validating genesis.json file %s", genesisPath)
+	if err := vega.ValidateGenesis(genesisPath, gen.userSettings.VegaChainId); err != nil {
+		return fmt.Errorf("invalid genesis file: %w", err)
+	}
+
+	return nil
+}
+
+// verifyGenesisChecksum compares the genesis checksum with the sha256 set by user or, when not set, with
+// the one from the network config
+func (gen *nodeGenerator) verifyGenesisChecksum(logger *zap.SugaredLogger, genesisPath string) error {
+	if gen.userSettings.SkipGenesisChecksum {
+		logger.Info("Checksum verification for the genesis is disabled")
+
+		return nil
+	}
+
+	expectedChecksum := gen.userSettings.GenesisSHA256
+	if expectedChecksum == "" {
+		expectedChecksum = gen.networkConfig.GenesisSHA256
+	}
+	if expectedChecksum == "" {
+		logger.Warn("The network config does not define the genesis sha256: genesis integrity is not verified. Use the --genesis-sha256 flag to verify it")
+
+		return nil
+	}
+
+	checksum, err := utils.FileSHA256(genesisPath)
+	if err != nil {
+		return fmt.Errorf("failed to compute genesis checksum: %w", err)
+	}
+
+	if !strings.EqualFold(checksum, expectedChecksum) {
+		return fmt.Errorf(
+			"genesis checksum mismatch for %s: expected %s, got %s: use the --skip-genesis-checksum flag only when you trust the genesis source",
+			genesisPath,
+			strings.ToLower(expectedChecksum),
+			checksum,
+		)
+	}
+	logger.Infof("Genesis checksum verified: %s", checksum)
+
+	return nil
+}
+
+func (gen *nodeGenerator) copyBinaries(
+	ctx context.Context,
+	logger *zap.SugaredLogger,
+	vegaBinaryPath, visorBinaryPath string,
+) error {
+	vegavisorDstFilePath := filepath.Join(gen.userSettings.VisorHome, "visor")
+	logger.Infof("Copying vegavisor from %s to %s", visorBinaryPath, vegavisorDstFilePath)
+	gen.trackCreatedPath(vegavisorDstFilePath)
+	if err := gen.copyBinary(logger, visorBinaryPath, vegavisorDstFilePath); err != nil {
+		return fmt.Errorf("failed to copy visor binary: %w", err)
+	}
+	logger.Info("Visor binary copied")
+
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%w before the vega binary is copied: %w", ErrCancelled, err)
+	}
+
+	version := gen.userSettings.VegaBinaryVersion
+	if gen.userSettings.Mode == StartFromBlock0 {
+		version = genesisRunConfigVersion
+	}
+
+	vegaDstFilePath := filepath.Join(gen.userSettings.VisorHome, version, "vega")
+	logger.Infof("Copying vega from %s to %s", vegaBinaryPath, vegaDstFilePath)
+	if err := gen.copyBinary(logger, vegaBinaryPath, vegaDstFilePath); err != nil {
+		return fmt.Errorf("failed to copy vega binary: %w", err)
+	}
+	logger.Info("Vega binary copied")
+
+	versionDirectory := filepath.Join(gen.userSettings.VisorHome, version)
+	currentDirectory := filepath.Join(gen.userSettings.VisorHome, "current")
+	logger.Infof("Creating symlink from %s to %s", versionDirectory, currentDirectory)
+	gen.trackCreatedPath(currentDirectory)
+	if gen.userSettings.DryRun {
+		logger.Info("Dry run: symlink not created")
+
+		return nil
+	}
+	if err := os.Symlink(versionDirectory, currentDirectory); err != nil {
+		return fmt.Errorf(
+			"failed to create symlink from %s to %s: %w",
+			versionDirectory,
+			currentDirectory,
+			err,
+		)
+	}
+	logger.Info("Symlink created")
+
+	return gen.verifyCurrentSymlink(logger, currentDirectory, versionDirectory)
+}
+
+// verifyCurrentSymlink makes sure visor finds the working vega binary in the current directory, when it
+// starts the node
+func (gen *nodeGenerator) verifyCurrentSymlink(logger *zap.SugaredLogger, currentDirectory, versionDirectory string) error {
+	logger.Infof("Verifying %s symlink", currentDirectory)
+	target, err := os.Readlink(currentDirectory)
+	if err != nil {
+		return fmt.Errorf("failed to read %s symlink: %w", currentDirectory, err)
+	}
+	if target != versionDirectory {
+		return fmt.Errorf(
+			"symlink %s points to %s instead of %s: remove the symlink and run the setup again",
+			currentDirectory,
+			target,
+			versionDirectory,
+		)
+	}
+
+	vegaPath := filepath.Join(currentDirectory, "vega")
+	if _, err := os.Stat(vegaPath); err != nil {
+		return fmt.Errorf("visor will not find the vega binary in the %s: %w", versionDirectory, err)
+	}
+	if !utils.IsExecutable(vegaPath) {
+		return fmt.Errorf("vega binary %s is not executable: run chmod +x on it", vegaPath)
+	}
+
+	if platform := gen.userSettings.TargetPlatform(); platform != github.CurrentPlatform() {
+		logger.Infof("Vega binary for the %s platform cannot be executed here: skipping the version check", platform)
+
+		return nil
+	}
+
+	version, err := gen.checkBinaryVersion(vegaPath, gen.userSettings.VegaBinaryVersion)
+	if err != nil {
+		return fmt.Errorf("vega binary %s does not work: %w", vegaPath, err)
+	}
+	logger.Infof("Visor will start vega %s from %s", version, vegaPath)
+
+	return nil
+}
+
+func (gen *nodeGenerator) prepareVisorHome(logger *zap.SugaredLogger) error {
+	runConfigDirPath := filepath.Join(gen.userSettings.VisorHome, gen.userSettings.VegaBinaryVersion)
+	version := gen.userSettings.VegaBinaryVersion
+
+	if gen.userSettings.Mode == StartFromBlock0 {
+		runConfigDirPath = filepath.Join(gen.userSettings.VisorHome, genesisRunConfigVersion)
+		version = genesisRunConfigVersion
+	}
+
+	logger.Infof("Preparing %s folder for vega", runConfigDirPath)
+	gen.trackCreatedPath(runConfigDirPath)
+	if gen.userSettings.DryRun {
+		logger.Infof("Dry run: folder %s not created", runConfigDirPath)
+	} else {
+		if err := os.MkdirAll(runConfigDirPath, utils.DirMode); err != nil {
+			return fmt.Errorf("failed to make directory: %w", err)
+		}
+		logger.Infof("Folder %s created", runConfigDirPath)
+	}
+
+	runConfigPath := filepath.Join(runConfigDirPath, "run-config.toml")
+	logger.Infof("Preparing run-config toml file in %s", runConfigPath)
+	dataNodeHome := ""
+	if gen.withDataNode {
+		dataNodeHome = gen.userSettings.DataNodeHome
+	}
+
+	runConfigContent, err := vegacmd.TemplateVisorRunConfig(
+		version,
+		gen.userSettings.VegaHome,
+		gen.userSettings.TendermintHome,
+		dataNodeHome,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to generate run-config.toml from template: %w", err)
+	}
+	if gen.userSettings.DryRun {
+		logger.Infof("Dry run: would write run-config.toml to %s with content:\n%s", runConfigPath, runConfigContent)
+
+		return nil
+	}
+	if err := os.WriteFile(runConfigPath, []byte(runConfigContent), utils.ConfigFileMode); err != nil {
+		return fmt.Errorf("failed to write run-config.toml in %s: %w", runConfigPath, err)
+	}
+	logger.Infof("The run-config.toml file saved in %s", runConfigPath)
+
+	return nil
+}
+
+// mergePersistentPeers returns default and extra persistent peers without
+// duplicates. Peers are compared by the node ID, and peers already listed in
+// the seeds are skipped.
+func mergePersistentPeers(seeds, defaultPeers, extraPeers []string) []string {
+	peerNodeID := func(peer string) string {
+		return strings.TrimSpace(strings.Split(peer, "@")[0])
+	}
+
+	seenNodeIDs := map[string]struct{}{}
+	for _, seed := range seeds {
+		seenNodeIDs[peerNodeID(seed)] = struct{}{}
+	}
+
+	result := []string{}
+	for _, peer := range append(append([]string{}, defaultPeers...), extraPeers...) {
+		peer = strings.TrimSpace(peer)
+		if peer == "" {
+			continue
+		}
+
+		nodeID := peerNodeID(peer)
+		if _, seen := seenNodeIDs[nodeID]; seen {
+			continue
+		}
+
+		seenNodeIDs[nodeID] = struct{}{}
+		result = append(result, peer)
+	}
+
+	return result
+}
+
+// checkFreeSpace warns when there is not enough free space for any home. It
+// fails when the strict free space check is enabled.
+func (gen *nodeGenerator) checkFreeSpace(logger *zap.SugaredLogger) error {
+	requiredSpace := defaultMinFreeSpace[gen.userSettings.Mode]
+	if gen.userSettings.MinFreeSpace != "" {
+		parsedSpace, err := utils.ParseSize(gen.userSettings.MinFreeSpace)
+		if err != nil {
+			return fmt.Errorf("invalid minimum free space: %w", err)
+		}
+		requiredSpace = parsedSpace
+	}
+
+	homes := []string{
+		gen.userSettings.VisorHome,
+		gen.userSettings.VegaHome,
+		gen.userSettings.TendermintHome,
+		gen.userSettings.DataNodeHome,
+	}
+
+	logger.Infof("Checking there is at least %s of free space for node homes", utils.FormatSize(requiredSpace))
+	for _, home := range homes {
+		if err := utils.CheckFreeSpace(home, requiredSpace); err != nil {
+			if gen.userSettings.StrictFreeSpace {
+				return err
+			}
+
+			logger.Warnf("Low disk space: %s", err.Error())
+		}
+	}
+
+	return nil
+}
+
+// makeTempDir creates the temp dir for downloads in the default temp location. When the DownloadDir is set,
+// the dir for downloads in it is named after the chain and the vega version, so the next run for the same
+// release resumes interrupted downloads. Only the dir for downloads is removed after the setup, the
+// DownloadDir is kept.
+func (gen *nodeGenerator) makeTempDir(logger *zap.SugaredLogger) (string, error) {
+	downloadDir := gen.userSettings.DownloadDir
+	if downloadDir == "" {
+		downloadDir = os.TempDir()
+	}
+
+	if gen.userSettings.DryRun {
+		outputDir := filepath.Join(downloadDir, "vega-assistant-dry-run")
+		logger.Infof("Dry run: would create temp dir for downloads in %s", downloadDir)
+
+		return outputDir, nil
+	}
+
+	if gen.userSettings.DownloadDir != "" {
+		if err := os.MkdirAll(gen.userSettings.DownloadDir, utils.DirMode); err != nil {
+			return "", fmt.Errorf("failed to create download dir: %w", err)
+		}
+
+		if err := utils.CheckFreeSpace(gen.userSettings.DownloadDir, minDownloadFreeSpace); err != nil {
+			if gen.userSettings.StrictFreeSpace {
+				return "", err
+			}
+
+			logger.Warnf("Low disk space in the download dir: %s", err.Error())
+		}
+	}
+
+	if gen.userSettings.DownloadDir != "" {
+		outputDir := gen.resumableDownloadDir()
+		if err := os.MkdirAll(outputDir, utils.DirMode); err != nil {
+			return "", fmt.Errorf("failed to create dir for downloads: %w", err)
+		}
+		logger.Infof("Dir for downloads is %s, interrupted downloads are resumed from it", outputDir)
+
+		return outputDir, nil
+	}
+
+	outputDir, err := os.MkdirTemp("", "vega-assistant")
+	if err != nil {
+		return "", err
+	}
+	logger.Infof("Temp dir for downloads is %s", outputDir)
+
+	return outputDir, nil
+}
+
+// resumableDownloadDir returns the dir for downloads in the DownloadDir. The default asset names do not
+// contain the version, so each release gets its own dir.
+func (gen *nodeGenerator) resumableDownloadDir() string {
+	return filepath.Join(
+		gen.userSettings.DownloadDir,
+		fmt.Sprintf("vega-assistant-%s-%s", gen.userSettings.VegaChainId, gen.userSettings.VegaBinaryVersion),
+	)
+}
+
+// removeTempDir removes downloads, unless user wants to keep them or inspect the failed run
+func (gen *nodeGenerator) removeTempDir(logger *zap.SugaredLogger, outputDir string, failed bool) {
+	if gen.userSettings.DryRun {
+		return
+	}
+
+	if gen.userSettings.KeepDownloads {
+		logger.Infof("Temp dir %s retained: downloads are kept", outputDir)
+
+		return
+	}
+
+	if failed && gen.userSettings.Debug {
+		logger.Infof("Temp dir %s retained for debugging of the failed setup", outputDir)
+
+		return
+	}
+
+	if failed && gen.userSettings.DownloadDir != "" {
+		logger.Infof("Dir for downloads %s retained: the next setup resumes the interrupted downloads", outputDir)
+
+		return
+	}
+
+	if err := os.RemoveAll(outputDir); err != nil {
+		logger.Errorf("Failed to remove temp dir %s: %s", outputDir, err.Error())
+
+		return
+	}
+	logger.Infof("Temp dir %s removed", outputDir)
+}
+
+func (gen *nodeGenerator) copyFile(logger *zap.SugaredLogger, srcFile, dstFile string) error {
+	if gen.userSettings.DryRun {
+		logger.Infof("Dry run: file %s not copied to %s", srcFile, dstFile)
+
+		return nil
+	}
+
+	return utils.CopyFile(srcFile, dstFile)
+}
+
+// copyBinary copies the binary and makes it executable, so it does not depend on the source file mode
+func (gen *nodeGenerator) copyBinary(logger *zap.SugaredLogger, srcFile, dstFile string) error {
+	if err := gen.copyFile(logger, srcFile, dstFile); err != nil {
+		return err
+	}
+
+	if gen.userSettings.DryRun {
+		return nil
+	}
+
+	if err := os.Chmod(dstFile, utils.ExecutableFileMode); err != nil {
+		return fmt.Errorf("failed to make binary %s executable: %w", dstFile, err)
+	}
+
+	return nil
+}
+
+func (gen *nodeGenerator) updateConfig(
+	logger *zap.SugaredLogger,
+	configPath string,
+	newValues map[string]interface{},
+) error {
+	if gen.userSettings.DryRun {
+		logger.Infof("Dry run: config %s not updated", configPath)
+
+		return nil
+	}
+
+	backupPath, err := gen.backupConfig(logger, configPath)
+	if err != nil {
+		return err
+	}
+
+	changes, err := utils.UpdateConfig(configPath, "toml", newValues)
+	if err != nil {
+		return err
+	}
+	logger.Infof("Changes applied to %s:\n%s", configPath, utils.FormatConfigChanges(changes))
+
+	if gen.userSettings.ReuseHome {
+		overwritten := []utils.ConfigChange{}
+		for _, change := range changes {
+			if change.Modified() && !change.Created {
+				overwritten = append(overwritten, change)
+			}
+		}
+		if len(overwritten) > 0 {
+			logger.Warnf(
+				"Existing values overwritten in %s, previous config is in %s:\n%s",
+				configPath,
+				backupPath,
+				utils.FormatConfigChanges(overwritten),
+			)
+		}
+	}
+
+	if err := utils.VerifyTOMLConfig(configPath, newValues); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// backupConfig backs up the config before its first update in the Run. Later updates of the same file,
+// e.g. the config overrides, reuse the first backup, so the rollback restores the original config.
+func (gen *nodeGenerator) backupConfig(logger *zap.SugaredLogger, configPath string) (string, error) {
+	gen.configBackupsMu.Lock()
+	defer gen.configBackupsMu.Unlock()
+
+	for _, backup := range gen.configBackups {
+		if backup.ConfigPath == configPath {
+			return backup.BackupPath, nil
+		}
+	}
+
+	backupPath, err := utils.BackupConfig(configPath)
+	if err != nil {
+		return "", err
+	}
+	logger.Infof("Config %s backed up to %s", configPath, backupPath)
+	gen.configBackups = append(gen.configBackups, ConfigBackup{
+		ConfigPath: configPath,
+		BackupPath: backupPath,
+	})
+
+	return backupPath, nil
+}
+
+// configUpdate describes new values for one config file
+type configUpdate struct {
+	name   string
+	path   string
+	values map[string]interface{}
+}
+
+// updateConfigFiles updates the config files concurrently, the files are independent. The returned error
+// reports every file that failed, not only the first one.
+func (gen *nodeGenerator) updateConfigFiles(logger *zap.SugaredLogger, updates []configUpdate) error {
+	updateErrors := make([]error, len(updates))
+	wg := sync.WaitGroup{}
+	for idx, update := range updates {
+		wg.Add(1)
+		go func(idx int, update configUpdate) {
+			defer wg.Done()
+
+			if err := gen.updateConfig(logger, update.path, update.values); err != nil {
+				updateErrors[idx] = fmt.Errorf("failed to update the %s config(%s): %w", update.name, update.path, err)
+
+				return
+			}
+			logger.Infof("The %s config updated", update.name)
+		}(idx, update)
+	}
+	wg.Wait()
+
+	var resErr error
+	for _, err := range updateErrors {
+		if err != nil {
+			resErr = multierror.Append(resErr, err)
+		}
+	}
+
+	return resErr
+}
+
+// ConfigBackups returns backups of the config files modified during the Run
+func (gen *nodeGenerator) ConfigBackups() []ConfigBackup {
+	return gen.configBackups
+}
+
+// RestoreConfigBackups restores all the config files modified during the Run
+func (gen *nodeGenerator) RestoreConfigBackups(logger *zap.SugaredLogger) error {
+	for _, backup := range gen.configBackups {
+		logger.Infof("Restoring config %s from %s", backup.ConfigPath, backup.BackupPath)
+		if err := utils.CopyFile(backup.BackupPath, backup.ConfigPath); err != nil {
+			return fmt.Errorf("failed to restore config %s: %w", backup.ConfigPath, err)
+		}
+	}
+
+	return nil
+}
+
+// tendermintPeersConfig returns the tendermint p2p config with the network seeds and persistent peers.
+// Tendermint expects comma separated strings for the peers, not arrays.
+func (gen *nodeGenerator) tendermintPeersConfig() map[string]interface{} {
+	return map[string]interface{}{
+		"p2p.seeds": strings.Join(gen.networkConfig.TendermintSeeds, ","),
+		"p2p.persistent_peers": strings.Join(
+			mergePersistentPeers(
+				gen.networkConfig.TendermintSeeds,
+				gen.networkConfig.TendermintPersistentPeers,
+				gen.userSettings.ExtraPersistentPeers,
+			),
+			",",
+		),
+		"p2p.pex": true,
+	}
+}
+
+// vegavisorConfig returns the vegavisor config with the auto install of the network releases. Without
+// the auto install, the operator prepares the upgrade folder before the upgrade block.
+func (gen *nodeGenerator) vegavisorConfig() map[string]interface{} {
+	releaseSource := gen.networkConfig.ReleaseSource()
+
+	return map[string]interface{}{
+		"maxNumberOfFirstConnectionRetries": gen.userSettings.VisorFirstConnectionRetries,
+		"autoInstall.enabled":               !gen.userSettings.NoAutoUpgrade,
+		"autoInstall.repositoryOwner":       strings.Split(gen.networkConfig.Repository, "/")[0],
+		"autoInstall.repository":            strings.Split(gen.networkConfig.Repository, "/")[1],
+		"autoInstall.asset.name": releaseSource.ArtifactName(
+			github.ArtifactVega,
+			gen.userSettings.TargetPlatform(),
+			gen.userSettings.VegaBinaryVersion,
+		),
+		"autoInstall.asset.binaryName": releaseSource.ArtifactBinaryName(github.ArtifactVega),
+	}
+}
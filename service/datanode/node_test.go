@@ -0,0 +1,402 @@
+package datanode
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"go.uber.org/zap"
+
+	"github.com/daniel1302/vega-assistant/network"
+	"github.com/daniel1302/vega-assistant/utils"
+)
+
+func TestRollbackKeepsExistingPaths(t *testing.T) {
+	visorHome := filepath.Join(t.TempDir(), "vegavisor")
+	existingHome := t.TempDir()
+	if err := os.WriteFile(filepath.Join(existingHome, "keep"), []byte("data"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	settings := GenerateSettings{VisorHome: visorHome, VegaHome: existingHome}
+	gen := &nodeGenerator{userSettings: settings, progress: NewSetupProgress(settings)}
+	gen.trackCreatedPath(existingHome)
+	gen.trackCreatedPath(visorHome)
+	if err := os.MkdirAll(visorHome, 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := gen.CreatedPaths(); !slices.Equal(got, []string{visorHome}) {
+		t.Fatalf("expected only %s to be tracked, got %v", visorHome, got)
+	}
+
+	if err := gen.Rollback(zap.NewNop().Sugar()); err != nil {
+		t.Fatalf("rollback failed: %s", err)
+	}
+
+	if _, err := os.Stat(visorHome); !os.IsNotExist(err) {
+		t.Errorf("expected %s to be removed, got %v", visorHome, err)
+	}
+	if _, err := os.Stat(filepath.Join(existingHome, "keep")); err != nil {
+		t.Errorf("expected existing home to be kept: %s", err)
+	}
+}
+
+func TestRollbackKeepsPathsOfResumedSetup(t *testing.T) {
+	visorHome := t.TempDir()
+	resumedPath := filepath.Join(visorHome, "resumed")
+	createdPath := filepath.Join(visorHome, "created")
+
+	settings := GenerateSettings{VisorHome: visorHome}
+	progress := NewSetupProgress(settings)
+	progress.TrackCreatedPath(resumedPath)
+	if err := os.Mkdir(resumedPath, 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	gen := &nodeGenerator{userSettings: settings, progress: progress}
+	gen.trackCreatedPath(resumedPath)
+	gen.trackCreatedPath(createdPath)
+	if err := os.Mkdir(createdPath, 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := gen.Rollback(zap.NewNop().Sugar()); err != nil {
+		t.Fatalf("rollback failed: %s", err)
+	}
+
+	if _, err := os.Stat(createdPath); !os.IsNotExist(err) {
+		t.Errorf("expected %s to be removed, got %v", createdPath, err)
+	}
+	if _, err := os.Stat(resumedPath); err != nil {
+		t.Errorf("expected path of the resumed setup to be kept: %s", err)
+	}
+
+	saved, err := LoadSetupProgress(visorHome)
+	if err != nil {
+		t.Fatalf("failed to load progress: %s", err)
+	}
+	if saved == nil || !slices.Equal(saved.CreatedPaths, []string{resumedPath}) {
+		t.Errorf("expected progress to keep %s for the cleanup, got %v", resumedPath, saved)
+	}
+}
+
+func TestVegavisorConfigAutoUpgrade(t *testing.T) {
+	tests := []struct {
+		name          string
+		noAutoUpgrade bool
+		expected      bool
+	}{
+		{name: "auto upgrade by default", noAutoUpgrade: false, expected: true},
+		{name: "auto upgrade disabled", noAutoUpgrade: true, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gen := &nodeGenerator{
+				userSettings: GenerateSettings{
+					NoAutoUpgrade:               tt.noAutoUpgrade,
+					VegaBinaryVersion:           "v0.73.4",
+					VisorFirstConnectionRetries: 10,
+				},
+				networkConfig: network.NetworkConfig{Repository: "vegaprotocol/vega"},
+			}
+
+			config := gen.vegavisorConfig()
+			if config["autoInstall.enabled"] != tt.expected {
+				t.Errorf("expected autoInstall.enabled = %t, got %v", tt.expected, config["autoInstall.enabled"])
+			}
+			if config["autoInstall.repositoryOwner"] != "vegaprotocol" || config["autoInstall.repository"] != "vega" {
+				t.Errorf("unexpected auto install repository: %v", config)
+			}
+		})
+	}
+}
+
+func TestUpdateConfigFilesReportsEveryFailedFile(t *testing.T) {
+	dir := t.TempDir()
+	configs := map[string]string{
+		"vega":       "[Admin]\n  Enabled = true\n",
+		"data-node":  "[SQLStore]\n  Enabled = false\n",
+		"tendermint": "invalid = = toml\n",
+	}
+	for name, content := range configs {
+		if err := os.WriteFile(filepath.Join(dir, name+".toml"), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	gen := &nodeGenerator{}
+	err := gen.updateConfigFiles(zap.NewNop().Sugar(), []configUpdate{
+		{name: "vega", path: filepath.Join(dir, "vega.toml"), values: map[string]interface{}{"Admin.Enabled": false}},
+		{name: "data-node", path: filepath.Join(dir, "data-node.toml"), values: map[string]interface{}{"SQLStore.Enabled": true}},
+		{name: "tendermint", path: filepath.Join(dir, "tendermint.toml"), values: map[string]interface{}{"p2p.pex": true}},
+		{name: "vegavisor", path: filepath.Join(dir, "vegavisor.toml"), values: map[string]interface{}{"autoInstall.enabled": true}},
+	})
+
+	merr := &multierror.Error{}
+	if !errors.As(err, &merr) {
+		t.Fatalf("expected multierror, got %v", err)
+	}
+	if len(merr.Errors) != 2 {
+		t.Errorf("expected 2 errors, got %d: %v", len(merr.Errors), err)
+	}
+	for _, name := range []string{"tendermint", "vegavisor"} {
+		if !strings.Contains(err.Error(), fmt.Sprintf("failed to update the %s config", name)) {
+			t.Errorf("expected %s config error, got %s", name, err)
+		}
+	}
+
+	for name, expected := range map[string]string{
+		"vega":      "[Admin]\n  Enabled = false\n",
+		"data-node": "[SQLStore]\n  Enabled = true\n",
+	} {
+		content, err := os.ReadFile(filepath.Join(dir, name+".toml"))
+		if err != nil || string(content) != expected {
+			t.Errorf("expected %s config to be updated, got %q(%v)", name, content, err)
+		}
+	}
+
+	if len(gen.ConfigBackups()) != 3 {
+		t.Errorf("expected backups of the 3 existing configs, got %v", gen.ConfigBackups())
+	}
+}
+
+func TestDownloadGenesisFromMirrorsDoesNotResumeFailedMirror(t *testing.T) {
+	const chainID = "vega-testnet-0001"
+	genesis := []byte(`{"chain_id": "` + chainID + `", "app_state": {"network": {}}}`)
+
+	brokenMirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", strconv.Itoa(len(genesis)))
+		w.Write(genesis[:len(genesis)/2])
+	}))
+	defer brokenMirror.Close()
+
+	var rangeRequested bool
+	healthyMirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rangeRequested = rangeRequested || r.Header.Get("Range") != ""
+		http.ServeContent(w, r, "genesis.json", time.Time{}, bytes.NewReader(genesis))
+	}))
+	defer healthyMirror.Close()
+
+	gen := &nodeGenerator{
+		userSettings:  GenerateSettings{VegaChainId: chainID},
+		networkConfig: network.NetworkConfig{GenesisURLs: []string{brokenMirror.URL, healthyMirror.URL}},
+	}
+	genesisPath, err := gen.downloadGenesisFromMirrors(context.Background(), zap.NewNop().Sugar(), t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if rangeRequested {
+		t.Error("expected the second mirror to download the whole genesis")
+	}
+	content, err := os.ReadFile(genesisPath)
+	if err != nil || !bytes.Equal(content, genesis) {
+		t.Errorf("expected genesis from the second mirror, got %q(%v)", content, err)
+	}
+}
+
+func TestRestoreConfigBackupsAfterRepeatedUpdates(t *testing.T) {
+	const original = "[Admin]\n  Enabled = true\n  Port = 3000\n"
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(configPath, []byte(original), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	gen := &nodeGenerator{}
+	logger := zap.NewNop().Sugar()
+	if err := gen.updateConfig(logger, configPath, map[string]interface{}{"Admin.Enabled": false}); err != nil {
+		t.Fatalf("failed to update config: %s", err)
+	}
+	if err := gen.updateConfig(logger, configPath, map[string]interface{}{"Admin.Port": 3001}); err != nil {
+		t.Fatalf("failed to apply config overrides: %s", err)
+	}
+
+	if len(gen.ConfigBackups()) != 1 {
+		t.Errorf("expected one backup of the config, got %v", gen.ConfigBackups())
+	}
+
+	if err := gen.RestoreConfigBackups(logger); err != nil {
+		t.Fatalf("failed to restore configs: %s", err)
+	}
+	content, err := os.ReadFile(configPath)
+	if err != nil || string(content) != original {
+		t.Errorf("expected original config after restore, got %q(%v)", content, err)
+	}
+}
+
+func TestMakeTempDirInDownloadDirIsResumable(t *testing.T) {
+	gen := &nodeGenerator{userSettings: GenerateSettings{
+		DownloadDir:       t.TempDir(),
+		VegaChainId:       "vega-mainnet-0011",
+		VegaBinaryVersion: "v0.73.4",
+	}}
+	logger := zap.NewNop().Sugar()
+
+	outputDir, err := gen.makeTempDir(logger)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	partFile := filepath.Join(outputDir, "genesis.json.part")
+	if err := os.WriteFile(partFile, []byte("partial"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	gen.removeTempDir(logger, outputDir, true)
+
+	resumedDir, err := gen.makeTempDir(logger)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if resumedDir != outputDir {
+		t.Errorf("expected the same dir for downloads %s, got %s", outputDir, resumedDir)
+	}
+	if _, err := os.Stat(partFile); err != nil {
+		t.Errorf("expected partial download to be kept after the failed setup: %s", err)
+	}
+
+	gen.removeTempDir(logger, resumedDir, false)
+	if _, err := os.Stat(resumedDir); !os.IsNotExist(err) {
+		t.Errorf("expected dir for downloads to be removed after the successful setup, got %v", err)
+	}
+}
+
+func TestMergePersistentPeers(t *testing.T) {
+	tests := []struct {
+		name         string
+		seeds        []string
+		defaultPeers []string
+		extraPeers   []string
+		expected     []string
+	}{
+		{
+			name:         "default peers come before extra peers",
+			defaultPeers: []string{"aaa@default0.example:26656"},
+			extraPeers:   []string{"bbb@extra0.example:26656", "ccc@extra1.example:26656"},
+			expected:     []string{"aaa@default0.example:26656", "bbb@extra0.example:26656", "ccc@extra1.example:26656"},
+		},
+		{
+			name:         "peers listed in seeds are skipped",
+			seeds:        []string{"aaa@seed0.example:26656"},
+			defaultPeers: []string{"aaa@default0.example:26656", "bbb@default1.example:26656"},
+			extraPeers:   []string{"aaa@extra0.example:26656"},
+			expected:     []string{"bbb@default1.example:26656"},
+		},
+		{
+			name:         "extra peer duplicating default peer node id is skipped",
+			defaultPeers: []string{"aaa@default0.example:26656"},
+			extraPeers:   []string{"aaa@extra0.example:26656", "bbb@extra1.example:26656", "bbb@extra2.example:26656"},
+			expected:     []string{"aaa@default0.example:26656", "bbb@extra1.example:26656"},
+		},
+		{
+			name:       "blank peers are dropped and peers are trimmed",
+			extraPeers: []string{"", "  ", " aaa@extra0.example:26656 "},
+			expected:   []string{"aaa@extra0.example:26656"},
+		},
+		{
+			name:     "no peers",
+			seeds:    []string{"aaa@seed0.example:26656"},
+			expected: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mergePersistentPeers(tt.seeds, tt.defaultPeers, tt.extraPeers)
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("expected peers %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestUpdateConfigBacksUpConfig(t *testing.T) {
+	const original = "[Admin]\n  Enabled = true\n"
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(configPath, []byte(original), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	gen := &nodeGenerator{}
+	logger := zap.NewNop().Sugar()
+	if err := gen.updateConfig(logger, configPath, map[string]interface{}{"Admin.Enabled": false}); err != nil {
+		t.Fatalf("failed to update config: %s", err)
+	}
+
+	backups := gen.ConfigBackups()
+	if len(backups) != 1 || backups[0].ConfigPath != configPath {
+		t.Fatalf("expected backup of %s, got %v", configPath, backups)
+	}
+	content, err := os.ReadFile(backups[0].BackupPath)
+	if err != nil || string(content) != original {
+		t.Errorf("expected pre-modification content %q in the backup, got %q(%v)", original, content, err)
+	}
+	updated, err := os.ReadFile(configPath)
+	if err != nil || string(updated) == original {
+		t.Errorf("expected config to be updated, got %q(%v)", updated, err)
+	}
+}
+
+func TestVisorHomeFileModes(t *testing.T) {
+	oldUmask := syscall.Umask(0o022)
+	defer syscall.Umask(oldUmask)
+
+	homes := t.TempDir()
+	gen := &nodeGenerator{userSettings: GenerateSettings{
+		VisorHome:         filepath.Join(homes, "vegavisor"),
+		VegaHome:          filepath.Join(homes, "vega"),
+		TendermintHome:    filepath.Join(homes, "tendermint"),
+		VegaBinaryVersion: "v0.73.4",
+	}}
+	logger := zap.NewNop().Sugar()
+
+	if err := gen.prepareVisorHome(logger); err != nil {
+		t.Fatalf("failed to prepare visor home: %s", err)
+	}
+
+	srcBinary := filepath.Join(homes, "vega-downloaded")
+	if err := os.WriteFile(srcBinary, []byte("binary"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	versionDir := filepath.Join(gen.userSettings.VisorHome, gen.userSettings.VegaBinaryVersion)
+	for _, binary := range []string{"vega", "visor"} {
+		if err := gen.copyBinary(logger, srcBinary, filepath.Join(versionDir, binary)); err != nil {
+			t.Fatalf("failed to copy %s binary: %s", binary, err)
+		}
+	}
+
+	tests := []struct {
+		path     string
+		expected os.FileMode
+	}{
+		{path: gen.userSettings.VisorHome, expected: os.ModeDir | utils.DirMode},
+		{path: versionDir, expected: os.ModeDir | utils.DirMode},
+		{path: filepath.Join(versionDir, "run-config.toml"), expected: utils.ConfigFileMode},
+		{path: filepath.Join(versionDir, "vega"), expected: utils.ExecutableFileMode},
+		{path: filepath.Join(versionDir, "visor"), expected: utils.ExecutableFileMode},
+	}
+
+	for _, tt := range tests {
+		t.Run(filepath.Base(tt.path), func(t *testing.T) {
+			info, err := os.Stat(tt.path)
+			if err != nil {
+				t.Fatalf("failed to stat %s: %s", tt.path, err)
+			}
+			if info.Mode() != tt.expected {
+				t.Errorf("expected mode %s for %s, got %s", tt.expected, tt.path, info.Mode())
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package datanode
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+
+	"github.com/pelletier/go-toml"
+	"go.uber.org/zap"
+
+	"github.com/daniel1302/vega-assistant/utils"
+	"github.com/daniel1302/vega-assistant/vegacmd"
+)
+
+// Sections of the config overrides file, each section is applied to the config of one component
+const (
+	OverridesDataNode   = "data-node"
+	OverridesVega       = "vega"
+	OverridesTendermint = "tendermint"
+	OverridesVisor      = "visor"
+)
+
+func AvailableOverridesSections() []string {
+	return []string{OverridesDataNode, OverridesVega, OverridesTendermint, OverridesVisor}
+}
+
+// ConfigOverrides maps the section to the flattened config keys, e.g. `Logging.Level`
+type ConfigOverrides map[string]map[string]interface{}
+
+// LoadConfigOverrides reads the TOML file with the config overrides. Nested tables in the sections are
+// flattened into the dotted keys.
+func LoadConfigOverrides(filePath string) (ConfigOverrides, error) {
+	tree, err := toml.LoadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config overrides file %s: %w", filePath, err)
+	}
+
+	overrides := ConfigOverrides{}
+	for _, section := range tree.Keys() {
+		if !slices.Contains(AvailableOverridesSections(), section) {
+			return nil, fmt.Errorf(
+				"invalid section [%s] in the config overrides file: expected one of %v",
+				section,
+				AvailableOverridesSections(),
+			)
+		}
+
+		sectionTree, isTable := tree.GetPath([]string{section}).(*toml.Tree)
+		if !isTable {
+			return nil, fmt.Errorf("invalid section %s in the config overrides file: expected table", section)
+		}
+
+		overrides[section] = map[string]interface{}{}
+		flattenConfigTree(sectionTree, "", overrides[section])
+	}
+
+	return overrides, nil
+}
+
+func flattenConfigTree(tree *toml.Tree, prefix string, values map[string]interface{}) {
+	for _, key := range tree.Keys() {
+		value := tree.GetPath([]string{key})
+		if subTree, isTable := value.(*toml.Tree); isTable {
+			flattenConfigTree(subTree, prefix+key+".", values)
+			continue
+		}
+
+		values[prefix+key] = value
+	}
+}
+
+func (settings GenerateSettings) validateConfigOverrides() error {
+	if settings.ConfigOverridesFile == "" {
+		return nil
+	}
+
+	_, err := LoadConfigOverrides(settings.ConfigOverridesFile)
+
+	return err
+}
+
+// applyConfigOverrides puts the values from the config overrides file into the node configs. It must be
+// called after the assistant updates the configs, so the overrides take precedence.
+func (gen *nodeGenerator) applyConfigOverrides(logger *zap.SugaredLogger) error {
+	if gen.userSettings.ConfigOverridesFile == "" {
+		return nil
+	}
+
+	overrides, err := LoadConfigOverrides(gen.userSettings.ConfigOverridesFile)
+	if err != nil {
+		return err
+	}
+
+	configPaths := map[string]string{
+		OverridesVega:       filepath.Join(gen.userSettings.VegaHome, vegacmd.CoreConfigPath),
+		OverridesTendermint: filepath.Join(gen.userSettings.TendermintHome, vegacmd.TenderminConfigPath),
+		OverridesVisor:      filepath.Join(gen.userSettings.VisorHome, vegacmd.VegavisorConfigPath),
+	}
+	if gen.userSettings.DataNodeHome != "" {
+		configPaths[OverridesDataNode] = filepath.Join(gen.userSettings.DataNodeHome, vegacmd.DataNodeConfigPath)
+	}
+
+	for _, section := range AvailableOverridesSections() {
+		values := overrides[section]
+		if len(values) == 0 {
+			continue
+		}
+
+		configPath, ok := configPaths[section]
+		if !ok {
+			logger.Warnf("The [%s] config overrides are skipped: the node does not run the %s", section, section)
+			continue
+		}
+
+		if !gen.userSettings.DryRun {
+			warnUnknownConfigKeys(logger, configPath, values)
+		}
+
+		logger.Infof("Applying config overrides to %s. New parameters: %v", configPath, utils.RedactSecrets(values))
+		if err := gen.updateConfig(logger, configPath, values); err != nil {
+			return fmt.Errorf("failed to apply the [%s] config overrides: %w", section, err)
+		}
+	}
+
+	return nil
+}
+
+// warnUnknownConfigKeys warns about the keys missing in the config, they are usually mistyped
+func warnUnknownConfigKeys(logger *zap.SugaredLogger, configPath string, values map[string]interface{}) {
+	config, err := toml.LoadFile(configPath)
+	if err != nil {
+		logger.Warnf("Failed to check the config overrides keys exist in %s: %s", configPath, err.Error())
+
+		return
+	}
+
+	unknownKeys := []string{}
+	for key := range values {
+		if !config.Has(key) {
+			unknownKeys = append(unknownKeys, key)
+		}
+	}
+	sort.Strings(unknownKeys)
+
+	if len(unknownKeys) > 0 {
+		logger.Warnf("Config overrides keys %v do not exist in %s: they are created, check they are not mistyped", unknownKeys, configPath)
+	}
+}
@@ -0,0 +1,128 @@
+package datanode
+
+import (
+	"fmt"
+	"sort"
+
+	"go.uber.org/zap"
+
+	"github.com/daniel1302/vega-assistant/utils"
+)
+
+// Default ports bound by the node components
+const (
+	DefaultTendermintP2PPort  = 26656
+	DefaultTendermintRPCPort  = 26657
+	DefaultTendermintABCIPort = 26658
+	DefaultVegaGRPCPort       = 3002
+	DefaultVegaRESTPort       = 3003
+	DefaultBrokerPort         = 3005
+	DefaultDataNodeGRPCPort   = 3007
+	DefaultDataNodeRESTPort   = 3008
+)
+
+const maxPort = 65535
+
+// NodePorts returns the TCP ports bound by the node components, keyed by the listener name. The
+// data-node ports are included only when the node runs the data-node.
+func (settings GenerateSettings) NodePorts() map[string]int {
+	ports := map[string]int{
+		"tendermint p2p":  settings.TendermintP2PPort,
+		"tendermint rpc":  settings.TendermintRPCPort,
+		"tendermint abci": DefaultTendermintABCIPort,
+		"vega grpc":       DefaultVegaGRPCPort,
+		"vega rest":       DefaultVegaRESTPort,
+	}
+
+	if settings.DataNodeHome != "" {
+		ports["data-node broker"] = DefaultBrokerPort
+		ports["data-node grpc"] = settings.DataNodeGRPCPort
+		ports["data-node rest"] = settings.DataNodeRESTPort
+	}
+
+	return ports
+}
+
+// validatePorts checks the ports are in range and no two listeners use the same port
+func (settings GenerateSettings) validatePorts() error {
+	ports := settings.NodePorts()
+	names := make([]string, 0, len(ports))
+	for name := range ports {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	portNames := map[int]string{}
+	for _, name := range names {
+		port := ports[name]
+		if port < 1 || port > maxPort {
+			return fmt.Errorf("invalid %s port(%d): expected value between 1 and %d", name, port, maxPort)
+		}
+
+		if otherName, taken := portNames[port]; taken {
+			return fmt.Errorf("the %s and the %s cannot use the same port(%d)", otherName, name, port)
+		}
+		portNames[port] = name
+	}
+
+	return nil
+}
+
+// tendermintPortsConfig returns the tendermint listen addresses for the custom ports. The default
+// ports are not written, so the addresses generated by the tendermint init are kept.
+func (settings GenerateSettings) tendermintPortsConfig() map[string]interface{} {
+	config := map[string]interface{}{}
+	if settings.TendermintP2PPort != DefaultTendermintP2PPort {
+		config["p2p.laddr"] = fmt.Sprintf("tcp://0.0.0.0:%d", settings.TendermintP2PPort)
+	}
+	if settings.TendermintRPCPort != DefaultTendermintRPCPort {
+		config["rpc.laddr"] = fmt.Sprintf("tcp://127.0.0.1:%d", settings.TendermintRPCPort)
+	}
+
+	return config
+}
+
+// dataNodePortsConfig returns the data-node API ports, when they are not the default ones
+func (settings GenerateSettings) dataNodePortsConfig() map[string]interface{} {
+	config := map[string]interface{}{}
+	if settings.DataNodeGRPCPort != DefaultDataNodeGRPCPort {
+		config["API.Port"] = settings.DataNodeGRPCPort
+	}
+	if settings.DataNodeRESTPort != DefaultDataNodeRESTPort {
+		config["Gateway.Port"] = settings.DataNodeRESTPort
+	}
+
+	return config
+}
+
+// formatPorts returns the configurable ports for the summary
+func (settings GenerateSettings) formatPorts() string {
+	result := fmt.Sprintf("tendermint p2p %d, tendermint rpc %d", settings.TendermintP2PPort, settings.TendermintRPCPort)
+	if settings.DataNodeHome != "" {
+		result += fmt.Sprintf(", data-node grpc %d, data-node rest %d", settings.DataNodeGRPCPort, settings.DataNodeRESTPort)
+	}
+
+	return result
+}
+
+// checkPortsFree fails when any port the node binds is already used by other process
+func (gen *nodeGenerator) checkPortsFree(logger *zap.SugaredLogger) error {
+	if gen.userSettings.SkipPortCheck {
+		logger.Info("Skipping the ports check")
+
+		return nil
+	}
+
+	ports := []int{}
+	for _, port := range gen.userSettings.NodePorts() {
+		ports = append(ports, port)
+	}
+	sort.Ints(ports)
+
+	logger.Infof("Checking the node ports %v are free", ports)
+	if err := utils.CheckPortsFree(ports); err != nil {
+		return fmt.Errorf("%w: stop the processes or use the --skip-port-check flag", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,172 @@
+package datanode
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"go.uber.org/zap"
+)
+
+const progressFileName = ".vega-assistant-state.json"
+
+type SetupStep string
+
+const (
+	StepInitNode         SetupStep = "init-node"
+	StepPrepareVisorHome SetupStep = "prepare-visor-home"
+	StepCopyBinaries     SetupStep = "copy-binaries"
+	StepDownloadGenesis  SetupStep = "download-genesis"
+	StepUpdateConfigs    SetupStep = "update-configs"
+)
+
+// SetupProgress keeps steps completed by the generator, so failed setup can be resumed.
+// Settings are stored to make sure the setup is resumed with the same homes and version.
+// Only the CreatedPaths are removed by the cleanup.
+type SetupProgress struct {
+	Mode              StartupMode `json:"mode"`
+	VisorHome         string      `json:"visor-home"`
+	VegaHome          string      `json:"vega-home"`
+	TendermintHome    string      `json:"tendermint-home"`
+	VegaBinaryVersion string      `json:"vega-binary-version"`
+	VegaChainId       string      `json:"vega-chain-id"`
+	CompletedSteps    []SetupStep `json:"completed-steps"`
+	CreatedPaths      []string    `json:"created-paths"`
+}
+
+// ProgressFilePath returns the progress file path. It is kept in the visor home, because homes are
+// removed when setup starts from scratch.
+func ProgressFilePath(visorHome string) string {
+	return filepath.Join(visorHome, progressFileName)
+}
+
+func NewSetupProgress(settings GenerateSettings) *SetupProgress {
+	return &SetupProgress{
+		Mode:              settings.Mode,
+		VisorHome:         settings.VisorHome,
+		VegaHome:          settings.VegaHome,
+		TendermintHome:    settings.TendermintHome,
+		VegaBinaryVersion: settings.VegaBinaryVersion,
+		VegaChainId:       settings.VegaChainId,
+		CompletedSteps:    []SetupStep{},
+		CreatedPaths:      []string{},
+	}
+}
+
+// LoadSetupProgress returns nil when there is no progress file in the visor home
+func LoadSetupProgress(visorHome string) (*SetupProgress, error) {
+	content, err := os.ReadFile(ProgressFilePath(visorHome))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("failed to read progress file: %w", err)
+	}
+
+	progress := &SetupProgress{}
+	if err := json.Unmarshal(content, progress); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal progress file: %w", err)
+	}
+
+	return progress, nil
+}
+
+// Matches returns error when settings differ from the settings of the setup being resumed
+func (progress SetupProgress) Matches(settings GenerateSettings) error {
+	expected := NewSetupProgress(settings)
+	mismatches := []string{}
+	compare := func(name, resumed, current string) {
+		if resumed != current {
+			mismatches = append(mismatches, fmt.Sprintf("%s(%s != %s)", name, resumed, current))
+		}
+	}
+
+	compare("mode", string(progress.Mode), string(expected.Mode))
+	compare("vega home", progress.VegaHome, expected.VegaHome)
+	compare("tendermint home", progress.TendermintHome, expected.TendermintHome)
+	compare("vega version", progress.VegaBinaryVersion, expected.VegaBinaryVersion)
+	compare("chain id", progress.VegaChainId, expected.VegaChainId)
+
+	if len(mismatches) > 0 {
+		return fmt.Errorf("settings do not match the resumed setup: %v", mismatches)
+	}
+
+	return nil
+}
+
+func (progress SetupProgress) Completed(step SetupStep) bool {
+	return slices.Contains(progress.CompletedSteps, step)
+}
+
+// TrackCreatedPath remembers the path, when it does not exist yet
+func (progress *SetupProgress) TrackCreatedPath(path string) {
+	// Lstat is used, so the pre-existing broken symlinks are not removed
+	if _, err := os.Lstat(path); err == nil || slices.Contains(progress.CreatedPaths, path) {
+		return
+	}
+
+	progress.CreatedPaths = append(progress.CreatedPaths, path)
+}
+
+// MarkCompleted saves the step in the progress file
+func (progress *SetupProgress) MarkCompleted(step SetupStep) error {
+	if !progress.Completed(step) {
+		progress.CompletedSteps = append(progress.CompletedSteps, step)
+	}
+
+	return progress.Save()
+}
+
+// Save writes the progress file. The visor home must exist.
+func (progress SetupProgress) Save() error {
+	content, err := json.MarshalIndent(progress, "", "    ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal progress: %w", err)
+	}
+
+	if err := os.WriteFile(ProgressFilePath(progress.VisorHome), content, 0o600); err != nil {
+		return fmt.Errorf("failed to write progress file: %w", err)
+	}
+
+	return nil
+}
+
+// Cleanup removes paths created by the assistant. Nested paths are removed first, and the pre-existing data is never touched.
+func (progress SetupProgress) Cleanup(logger *zap.SugaredLogger) error {
+	if err := removeCreatedPaths(logger, progress.CreatedPaths); err != nil {
+		return err
+	}
+
+	// Progress file is left when the visor home existed before the setup
+	return progress.Remove()
+}
+
+// removeCreatedPaths removes paths in the reverse order, so nested paths are removed first
+func removeCreatedPaths(logger *zap.SugaredLogger, createdPaths []string) error {
+	for idx := len(createdPaths) - 1; idx >= 0; idx-- {
+		createdPath := createdPaths[idx]
+		if _, err := os.Lstat(createdPath); errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+
+		logger.Infof("Removing %s", createdPath)
+		if err := os.RemoveAll(createdPath); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", createdPath, err)
+		}
+	}
+
+	return nil
+}
+
+// Remove deletes the progress file once the setup is finished
+func (progress SetupProgress) Remove() error {
+	if err := os.Remove(ProgressFilePath(progress.VisorHome)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove progress file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package datanode
+
+import (
+	"fmt"
+	"slices"
+)
+
+type PruningMode string
+
+const (
+	// PruningDefault keeps the node defaults, no keys are written
+	PruningDefault PruningMode = "default"
+	// PruningNothing keeps all the tendermint tx index and ABCI responses, used by archival nodes
+	PruningNothing PruningMode = "nothing"
+	// PruningEverything discards tx index and ABCI responses and keeps minimal number of snapshots
+	PruningEverything PruningMode = "everything"
+	// PruningCustom uses the keep recent and interval set by user
+	PruningCustom PruningMode = "custom"
+)
+
+// everythingKeepRecent is the number of vega snapshots kept in the everything mode. One snapshot
+// more than the latest is kept, so node can restart when the latest snapshot is broken.
+const everythingKeepRecent = 2
+
+func AvailablePruningModes() []PruningMode {
+	return []PruningMode{PruningDefault, PruningNothing, PruningEverything, PruningCustom}
+}
+
+// validatePruning checks the pruning mode and the custom values. Custom values are accepted only in the
+// custom mode. Archival data-node must keep the whole chain history, so it cannot prune.
+func (settings GenerateSettings) validatePruning() error {
+	if !slices.Contains(AvailablePruningModes(), settings.Pruning) {
+		return fmt.Errorf("invalid pruning mode(%s): expected one of %v", settings.Pruning, AvailablePruningModes())
+	}
+
+	if settings.Pruning != PruningCustom && (settings.PruningKeepRecent != 0 || settings.PruningInterval != 0) {
+		return fmt.Errorf("pruning keep recent and interval can be set only in the %s pruning mode", PruningCustom)
+	}
+
+	if settings.Pruning == PruningCustom {
+		if settings.PruningKeepRecent < 1 {
+			return fmt.Errorf("pruning keep recent(%d) must be a positive integer in the %s pruning mode", settings.PruningKeepRecent, PruningCustom)
+		}
+
+		if settings.PruningInterval < 0 {
+			return fmt.Errorf("pruning interval(%s) must not be negative", settings.PruningInterval)
+		}
+	}
+
+	if settings.DataRetention == "forever" && (settings.Pruning == PruningEverything || settings.Pruning == PruningCustom) {
+		return fmt.Errorf(
+			"the %s pruning mode cannot be used with the forever retention policy: use the %s or the %s mode for archival node",
+			settings.Pruning,
+			PruningDefault,
+			PruningNothing,
+		)
+	}
+
+	return nil
+}
+
+// pruningConfig returns the vega and the tendermint config values for the pruning mode
+func (settings GenerateSettings) pruningConfig() (map[string]interface{}, map[string]interface{}) {
+	vegaConfig := map[string]interface{}{}
+	tendermintConfig := map[string]interface{}{}
+
+	switch settings.Pruning {
+	case PruningNothing:
+		tendermintConfig["tx_index.indexer"] = "kv"
+		tendermintConfig["storage.discard_abci_responses"] = false
+	case PruningEverything:
+		tendermintConfig["tx_index.indexer"] = "null"
+		tendermintConfig["storage.discard_abci_responses"] = true
+		vegaConfig["Snapshot.KeepRecent"] = everythingKeepRecent
+	case PruningCustom:
+		tendermintConfig["storage.discard_abci_responses"] = true
+		vegaConfig["Snapshot.KeepRecent"] = settings.PruningKeepRecent
+		if settings.PruningInterval > 0 {
+			tendermintConfig["storage.pruning.interval"] = settings.PruningInterval.String()
+		}
+	}
+
+	return vegaConfig, tendermintConfig
+}
+
+// pruningSummary describes the pruning mode tradeoff for the summary
+func (settings GenerateSettings) pruningSummary() string {
+	switch settings.Pruning {
+	case PruningNothing:
+		return "nothing(full tx index and ABCI responses, the largest disk usage)"
+	case PruningEverything:
+		return fmt.Sprintf(
+			"everything(no tx index nor ABCI responses, %d vega snapshots kept, the smallest disk usage, no historic queries)",
+			everythingKeepRecent,
+		)
+	case PruningCustom:
+		interval := "node default"
+		if settings.PruningInterval > 0 {
+			interval = settings.PruningInterval.String()
+		}
+
+		return fmt.Sprintf(
+			"custom(%d vega snapshots kept, pruning interval: %s, no ABCI responses)",
+			settings.PruningKeepRecent,
+			interval,
+		)
+	default:
+		return "default(node defaults)"
+	}
+}
+
+// mergeConfig adds values to the config, existing keys are overwritten
+func mergeConfig(config, values map[string]interface{}) {
+	for key, value := range values {
+		config[key] = value
+	}
+}
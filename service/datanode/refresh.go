@@ -0,0 +1,128 @@
+package datanode
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/daniel1302/vega-assistant/network"
+	"github.com/daniel1302/vega-assistant/types"
+	"github.com/daniel1302/vega-assistant/utils"
+	"github.com/daniel1302/vega-assistant/vegaapi"
+	"github.com/daniel1302/vega-assistant/vegacmd"
+)
+
+// ConfigRefresher re-applies the recommended config values to the existing installation. It never
+// initializes the node, downloads binaries or touches the database.
+type ConfigRefresher struct {
+	*nodeGenerator
+}
+
+func NewConfigRefresher(
+	vegaApi *vegaapi.NetworkAPI,
+	settings GenerateSettings,
+	networkConfig network.NetworkConfig,
+) (*ConfigRefresher, error) {
+	// Overwritten values of the existing installation are reported
+	settings.ReuseHome = true
+
+	return &ConfigRefresher{
+		nodeGenerator: &nodeGenerator{
+			vegaApi:       vegaApi,
+			userSettings:  settings,
+			networkConfig: networkConfig,
+			withDataNode:  true,
+		},
+	}, nil
+}
+
+func (refresher *ConfigRefresher) Run(ctx context.Context, logger *zap.SugaredLogger) error {
+	if err := ValidateExistingHomes(refresher.userSettings); err != nil {
+		return err
+	}
+
+	healthyTendermintRPCServers, err := refresher.vegaApi.HealthyEndpoints(ctx, refresher.networkConfig.TendermintRPCServers)
+	if err != nil {
+		return fmt.Errorf("failed to find healthy tendermint rpc servers: %w", err)
+	}
+	if len(healthyTendermintRPCServers) < 1 {
+		return fmt.Errorf("there is no healthy rpc server")
+	}
+	if len(healthyTendermintRPCServers) == 1 {
+		healthyTendermintRPCServers = append(healthyTendermintRPCServers, healthyTendermintRPCServers[0])
+	}
+
+	healthyBootstrapPeers, err := refresher.vegaApi.HealthyEndpoints(ctx, refresher.networkConfig.BootstrapPeers)
+	if err != nil {
+		return fmt.Errorf("failed to find healthy network history bootstrap peers: %w", err)
+	}
+	if len(healthyBootstrapPeers) < 1 {
+		return fmt.Errorf("no healthy network history bootstrap peer")
+	}
+	if len(healthyBootstrapPeers) == 1 {
+		healthyBootstrapPeers = append(healthyBootstrapPeers, healthyBootstrapPeers[0])
+	}
+
+	tendermintConfig := refresher.tendermintPeersConfig()
+	tendermintConfig["statesync.rpc_servers"] = strings.Join(healthyTendermintRPCServers, ",")
+
+	configs := []struct {
+		path   string
+		values map[string]interface{}
+	}{
+		{
+			path:   filepath.Join(refresher.userSettings.DataNodeHome, vegacmd.DataNodeConfigPath),
+			values: refresher.recommendedDataNodeConfig(healthyBootstrapPeers),
+		},
+		{
+			path:   filepath.Join(refresher.userSettings.VegaHome, vegacmd.CoreConfigPath),
+			values: refresher.recommendedVegaConfig(),
+		},
+		{
+			path:   filepath.Join(refresher.userSettings.TendermintHome, vegacmd.TenderminConfigPath),
+			values: tendermintConfig,
+		},
+		{
+			path: filepath.Join(refresher.userSettings.VisorHome, vegacmd.VegavisorConfigPath),
+			values: map[string]interface{}{
+				"maxNumberOfFirstConnectionRetries": refresher.userSettings.VisorFirstConnectionRetries,
+			},
+		},
+	}
+
+	for _, config := range configs {
+		if refresher.userSettings.DryRun {
+			logger.Infof("Dry run: would refresh config %s. New parameters: %v", config.path, utils.RedactSecrets(config.values))
+
+			continue
+		}
+
+		if err := refresher.updateConfig(logger, config.path, config.values); err != nil {
+			return types.NewConfigError(fmt.Errorf("failed to refresh config %s: %w", config.path, err))
+		}
+	}
+
+	return nil
+}
+
+// recommendedDataNodeConfig returns the data-node values recommended for all the installations. They do
+// not depend on the node state, so they can be refreshed for the running node.
+func (gen *nodeGenerator) recommendedDataNodeConfig(bootstrapPeers []string) map[string]interface{} {
+	return map[string]interface{}{
+		"NetworkHistory.Store.BootstrapPeers": bootstrapPeers,
+		"NetworkHistory.Initialise.Timeout":   gen.userSettings.NetworkHistoryTimeout.String(),
+		"NetworkHistory.RetryTimeout":         "15s",
+		"API.RateLimit.Rate":                  300.0,
+		"API.RateLimit.Burst":                 1000,
+	}
+}
+
+// recommendedVegaConfig returns the vega values recommended for all the installations
+func (gen *nodeGenerator) recommendedVegaConfig() map[string]interface{} {
+	return map[string]interface{}{
+		"Broker.Socket.DialTimeout": gen.userSettings.BrokerDialTimeout.String(),
+	}
+}
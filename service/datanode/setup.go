@@ -0,0 +1,108 @@
+package datanode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/daniel1302/vega-assistant/network"
+	"github.com/daniel1302/vega-assistant/vegaapi"
+)
+
+// SetupError is returned by the Setup when the node setup fails. Generator is nil when the setup failed
+// before anything was created, otherwise it can be used to rollback the created paths or to restore
+// the config backups.
+type SetupError struct {
+	Generator *DataNodeGenerator
+	Err       error
+}
+
+func (e *SetupError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *SetupError) Unwrap() error {
+	return e.Err
+}
+
+// Setup prepares the data-node without any user interaction, so it can be embedded in other programs.
+// Settings collected by the StateMachine are used as they are. The binary versions, the chain id and the
+// network-history segment missing in the settings are resolved from the network. The ErrCancelled is
+// returned when ctx is done before the setup completes. Failed setup is not rolled back, it is up to the
+// caller, see the SetupError.
+func Setup(
+	ctx context.Context,
+	settings GenerateSettings,
+	networkConfig network.NetworkConfig,
+	logger *zap.SugaredLogger,
+) error {
+	if err := ctx.Err(); err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return &SetupError{Err: fmt.Errorf("%w: %w: %w", ErrSetupTimeout, ErrCancelled, err)}
+		}
+
+		return &SetupError{Err: fmt.Errorf("%w: %w", ErrCancelled, err)}
+	}
+
+	if err := networkConfig.ReleaseSource().Validate(); err != nil {
+		return &SetupError{Err: fmt.Errorf("invalid network config: %w", err)}
+	}
+
+	apiClient, err := vegaapi.NewNetworkAPI(networkConfig.DataNodesRESTUrls, true, nil)
+	if err != nil {
+		return &SetupError{Err: fmt.Errorf("failed to create vega network api client: %w", err)}
+	}
+
+	settings, err = completeNetworkSettings(ctx, logger, apiClient, settings, networkConfig)
+	if err != nil {
+		return &SetupError{Err: fmt.Errorf("failed to resolve settings from the network: %w", err)}
+	}
+
+	generator, err := NewDataNodeGenerator(apiClient, settings, networkConfig)
+	if err != nil {
+		return &SetupError{Err: fmt.Errorf("failed to start generator service: %w", err)}
+	}
+
+	if err := generator.Run(ctx, logger); err != nil {
+		return &SetupError{Generator: generator, Err: err}
+	}
+
+	return nil
+}
+
+// completeNetworkSettings fills the settings that depend on the running network, when they are missing.
+// Values already in the settings are kept.
+func completeNetworkSettings(
+	ctx context.Context,
+	logger *zap.SugaredLogger,
+	apiClient *vegaapi.NetworkAPI,
+	settings GenerateSettings,
+	networkConfig network.NetworkConfig,
+) (GenerateSettings, error) {
+	missingSegment := settings.NetworkHistoryFromHeight > 0 && settings.NetworkHistorySegment == nil
+	if settings.VegaBinaryVersion != "" && settings.VisorBinaryVersion != "" && settings.VegaChainId != "" && !missingSegment {
+		return settings, nil
+	}
+
+	resolved := settings
+	if err := resolveNetworkSettings(ctx, logger, apiClient, &resolved, networkConfig); err != nil {
+		return settings, err
+	}
+
+	if settings.VegaBinaryVersion == "" {
+		settings.VegaBinaryVersion = resolved.VegaBinaryVersion
+	}
+	if settings.VisorBinaryVersion == "" {
+		settings.VisorBinaryVersion = resolved.VisorBinaryVersion
+	}
+	if settings.VegaChainId == "" {
+		settings.VegaChainId = resolved.VegaChainId
+	}
+	if settings.NetworkHistorySegment == nil {
+		settings.NetworkHistorySegment = resolved.NetworkHistorySegment
+	}
+
+	return settings, nil
+}
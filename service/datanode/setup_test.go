@@ -0,0 +1,77 @@
+package datanode
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/daniel1302/vega-assistant/network"
+	"github.com/daniel1302/vega-assistant/types"
+	"github.com/daniel1302/vega-assistant/vegaapi"
+)
+
+func TestCompleteNetworkSettingsKeepsResolvedSettings(t *testing.T) {
+	settings := GenerateSettings{
+		VegaBinaryVersion:        "v0.73.4",
+		VisorBinaryVersion:       "v0.73.3",
+		VegaChainId:              "vega-mainnet-0011",
+		NetworkHistoryFromHeight: 100,
+		NetworkHistorySegment:    &types.NetworkHistorySegment{HistorySegmentId: "segment"},
+	}
+
+	// Nothing is requested from the network, so the api client is not required
+	got, err := completeNetworkSettings(context.Background(), zap.NewNop().Sugar(), nil, settings, network.NetworkConfig{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if got.VegaBinaryVersion != settings.VegaBinaryVersion ||
+		got.VisorBinaryVersion != settings.VisorBinaryVersion ||
+		got.VegaChainId != settings.VegaChainId ||
+		got.NetworkHistorySegment != settings.NetworkHistorySegment {
+		t.Errorf("expected settings to be kept, got %+v", got)
+	}
+}
+
+func TestCompleteNetworkSettingsResolvesMissingSettings(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/statistics" {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		w.Write([]byte(`{"statistics": {
+			"chainId": "vega-testnet-0015",
+			"appVersion": "v0.73.4",
+			"currentTime": "2024-01-01T00:00:00Z",
+			"vegaTime": "2024-01-01T00:00:00Z",
+			"blockHeight": "1000"
+		}}`))
+	}))
+	defer server.Close()
+
+	apiClient, err := vegaapi.NewNetworkAPI([]string{server.URL}, false, nil)
+	if err != nil {
+		t.Fatalf("failed to create api client: %s", err)
+	}
+
+	settings := GenerateSettings{Mode: StartFromNetworkHistory, VegaBinaryVersion: "v0.73.5"}
+	got, err := completeNetworkSettings(context.Background(), zap.NewNop().Sugar(), apiClient, settings, network.NetworkConfig{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if got.VegaBinaryVersion != "v0.73.5" {
+		t.Errorf("expected vega version set by user to be kept, got %s", got.VegaBinaryVersion)
+	}
+	if got.VisorBinaryVersion != "v0.73.4" {
+		t.Errorf("expected visor version reported by the network, got %s", got.VisorBinaryVersion)
+	}
+	if got.VegaChainId != "vega-testnet-0015" {
+		t.Errorf("expected chain id reported by the network, got %s", got.VegaChainId)
+	}
+}
@@ -0,0 +1,64 @@
+package datanode
+
+import (
+	"fmt"
+)
+
+// validateSnapshots checks the vega snapshot values. Zero values keep the node defaults. The keep recent
+// value is also set by the everything and the custom pruning modes, so both cannot be used together.
+func (settings GenerateSettings) validateSnapshots() error {
+	if settings.SnapshotInterval < 0 {
+		return fmt.Errorf("snapshot interval(%d) must be a positive number of blocks", settings.SnapshotInterval)
+	}
+
+	if settings.SnapshotKeepRecent < 0 {
+		return fmt.Errorf("snapshot keep recent(%d) must be a positive integer", settings.SnapshotKeepRecent)
+	}
+
+	if settings.SnapshotKeepRecent > 0 && (settings.Pruning == PruningEverything || settings.Pruning == PruningCustom) {
+		return fmt.Errorf(
+			"snapshot keep recent cannot be used with the %s pruning mode: the pruning mode sets the number of kept snapshots",
+			settings.Pruning,
+		)
+	}
+
+	return nil
+}
+
+// snapshotConfig returns the vega config values for the snapshots. The node starts from the latest local
+// snapshot in both modes: there is no snapshot at the block 0 replay, and the statesync snapshot is the
+// latest one after the network history load.
+func (settings GenerateSettings) snapshotConfig() map[string]interface{} {
+	vegaConfig := map[string]interface{}{
+		"Snapshot.StartHeight": -1,
+	}
+
+	if settings.SnapshotInterval > 0 {
+		vegaConfig["Snapshot.Interval"] = settings.SnapshotInterval
+	}
+	if settings.SnapshotKeepRecent > 0 {
+		vegaConfig["Snapshot.KeepRecent"] = settings.SnapshotKeepRecent
+	}
+
+	return vegaConfig
+}
+
+// snapshotSummary describes the snapshot values for the summary
+func (settings GenerateSettings) snapshotSummary() string {
+	interval := "node default"
+	if settings.SnapshotInterval > 0 {
+		interval = fmt.Sprintf("every %d blocks", settings.SnapshotInterval)
+	}
+
+	keepRecent := "node default"
+	switch {
+	case settings.SnapshotKeepRecent > 0:
+		keepRecent = fmt.Sprint(settings.SnapshotKeepRecent)
+	case settings.Pruning == PruningEverything:
+		keepRecent = fmt.Sprintf("%d(pruning)", everythingKeepRecent)
+	case settings.Pruning == PruningCustom:
+		keepRecent = fmt.Sprintf("%d(pruning)", settings.PruningKeepRecent)
+	}
+
+	return fmt.Sprintf("interval: %s, kept: %s", interval, keepRecent)
+}
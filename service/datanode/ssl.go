@@ -0,0 +1,104 @@
+package datanode
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"github.com/daniel1302/vega-assistant/types"
+	"github.com/daniel1302/vega-assistant/utils"
+)
+
+const (
+	SSLModeDisable    = "disable"
+	SSLModeRequire    = "require"
+	SSLModeVerifyCA   = "verify-ca"
+	SSLModeVerifyFull = "verify-full"
+)
+
+func AvailableSSLModes() []string {
+	return []string{SSLModeDisable, SSLModeRequire, SSLModeVerifyCA, SSLModeVerifyFull}
+}
+
+func isVerifySSLMode(sslMode string) bool {
+	return sslMode == SSLModeVerifyCA || sslMode == SSLModeVerifyFull
+}
+
+// ValidateSSLSettings checks the ssl mode and the root certificate required by the verify modes
+func ValidateSSLSettings(creds types.SQLCredentials) error {
+	switch creds.SSLMode {
+	case "", SSLModeDisable, SSLModeRequire:
+		return nil
+	case SSLModeVerifyCA, SSLModeVerifyFull:
+		if creds.SSLRootCert == "" {
+			return fmt.Errorf("ssl root certificate is required for the %s ssl mode", creds.SSLMode)
+		}
+
+		if !utils.FileExists(creds.SSLRootCert) {
+			return fmt.Errorf("ssl root certificate %s does not exist", creds.SSLRootCert)
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("invalid ssl mode(%s): expected one of %v", creds.SSLMode, AvailableSSLModes())
+	}
+}
+
+// sqlTLSConfig returns tls config for the given ssl mode. It follows the libpq sslmode semantics.
+func sqlTLSConfig(creds types.SQLCredentials) (*tls.Config, error) {
+	switch creds.SSLMode {
+	case "", SSLModeDisable:
+		return nil, nil
+	case SSLModeRequire:
+		return &tls.Config{InsecureSkipVerify: true}, nil //nolint
+	}
+
+	rootCert, err := os.ReadFile(creds.SSLRootCert)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ssl root certificate: %w", err)
+	}
+
+	rootCAs := x509.NewCertPool()
+	if !rootCAs.AppendCertsFromPEM(rootCert) {
+		return nil, fmt.Errorf("failed to parse ssl root certificate %s", creds.SSLRootCert)
+	}
+
+	if creds.SSLMode == SSLModeVerifyFull {
+		return &tls.Config{
+			RootCAs:    rootCAs,
+			ServerName: creds.Host,
+		}, nil
+	}
+
+	// verify-ca checks the certificate chain, but not the host name
+	return &tls.Config{
+		InsecureSkipVerify: true, //nolint
+		VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			if len(rawCerts) < 1 {
+				return fmt.Errorf("no server certificate")
+			}
+
+			certs := make([]*x509.Certificate, len(rawCerts))
+			for idx, rawCert := range rawCerts {
+				cert, err := x509.ParseCertificate(rawCert)
+				if err != nil {
+					return fmt.Errorf("failed to parse server certificate: %w", err)
+				}
+				certs[idx] = cert
+			}
+
+			intermediates := x509.NewCertPool()
+			for _, cert := range certs[1:] {
+				intermediates.AddCert(cert)
+			}
+
+			_, err := certs[0].Verify(x509.VerifyOptions{
+				Roots:         rootCAs,
+				Intermediates: intermediates,
+			})
+
+			return err
+		},
+	}, nil
+}
@@ -6,22 +6,26 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
 	"time"
 
-	pg "github.com/go-pg/pg/v11"
 	"github.com/tcnksm/go-input"
 	"go.uber.org/zap"
 	"golang.org/x/mod/semver"
+	"gopkg.in/yaml.v2"
 
 	"github.com/pelletier/go-toml"
 
+	"github.com/daniel1302/vega-assistant/github"
 	"github.com/daniel1302/vega-assistant/network"
 	"github.com/daniel1302/vega-assistant/types"
 	"github.com/daniel1302/vega-assistant/uilib"
 	"github.com/daniel1302/vega-assistant/utils"
 	"github.com/daniel1302/vega-assistant/vega"
 	"github.com/daniel1302/vega-assistant/vegaapi"
+	"github.com/daniel1302/vega-assistant/vegacmd"
 )
 
 type (
@@ -39,12 +43,14 @@ const (
 	StateSelectHowManyBlockToSync
 	SelectDataRetention
 	StateSelectVisorHome
-	StateExistingVisorHome
 	StateSelectVegaHome
-	StateExistingVegaHome
 	StateSelectTendermintHome
+	StateValidateHomes
+	StateExistingVisorHome
+	StateExistingVegaHome
 	StateExistingTendermintHome
 	StateGetSQLCredentials
+	StateCheckExistingData
 	StateCheckLatestVersion
 	StateSummary
 )
@@ -57,22 +63,125 @@ type StateMachine struct {
 }
 
 type GenerateSettings struct {
-	Mode StartupMode
-
-	NonInteractive              bool   `toml:"non-interactive"`
-	DataRetention               string `toml:"data-retention"`
-	VisorHome                   string `toml:"visor-home"`
-	VegaHome                    string `toml:"vega-home"`
-	TendermintHome              string `toml:"tendermint-home"`
-	DataNodeHome                string `toml:"data-node-home"`
-	VisorBinaryVersion          string
-	VegaBinaryVersion           string
-	VegaChainId                 string
-	NetworkHistoryMinBlockCount int                  `toml:"network-history-min-block-count"`
-	RemoveExistingFiles         bool                 `toml:"remove-existing-file"`
-	SQLCredentials              types.SQLCredentials `toml:"sql-credentials"`
+	Mode StartupMode `toml:"mode" json:"mode"`
+
+	NonInteractive              bool                 `toml:"non-interactive" json:"non-interactive"`
+	DataRetention               string               `toml:"data-retention" json:"data-retention"`
+	VisorHome                   string               `toml:"visor-home" json:"visor-home"`
+	VegaHome                    string               `toml:"vega-home" json:"vega-home"`
+	TendermintHome              string               `toml:"tendermint-home" json:"tendermint-home"`
+	DataNodeHome                string               `toml:"data-node-home" json:"data-node-home"`
+	HomeBase                    string               `toml:"home-base" json:"home-base"`
+	Version                     string               `toml:"version" json:"version"`
+	VersionConstraint           string               `toml:"version-constraint" json:"version-constraint"`
+	NodeType                    vegacmd.VegaNodeMode `toml:"node-type" json:"node-type"`
+	VisorBinaryVersion          string               `toml:"-" json:"visor-binary-version"`
+	VegaBinaryVersion           string               `toml:"-" json:"vega-binary-version"`
+	VegaChainId                 string               `toml:"-" json:"vega-chain-id"`
+	ChainID                     string               `toml:"chain-id" json:"chain-id"`
+	NetworkHistoryMinBlockCount int                  `toml:"network-history-min-block-count" json:"network-history-min-block-count"`
+	NetworkHistoryFromHeight    uint64               `toml:"network-history-from-height" json:"network-history-from-height"`
+	RemoveExistingFiles         bool                 `toml:"remove-existing-file" json:"remove-existing-file"`
+	SQLCredentials              types.SQLCredentials `toml:"sql-credentials" json:"sql-credentials"`
+	DatabaseURL                 string               `toml:"db-url" json:"db-url"`
+	SkipSQLPasswordPrompt       bool                 `toml:"-" json:"-"`
+	DBPasswordEnv               string               `toml:"db-password-env" json:"db-password-env"`
+	CreateDatabase              bool                 `toml:"create-db" json:"create-db"`
+	SQLAdminUser                string               `toml:"sql-admin-user" json:"sql-admin-user"`
+	SQLAdminPassword            string               `toml:"sql-admin-password" json:"sql-admin-password"`
+	InstallTimescaleDB          bool                 `toml:"install-timescaledb" json:"install-timescaledb"`
+	TimescaleDBVersion          string               `toml:"timescaledb-version" json:"timescaledb-version"`
+	ExtraPersistentPeers        []string             `toml:"extra-persistent-peers" json:"extra-persistent-peers"`
+	SkipChecksum                bool                 `toml:"skip-checksum" json:"skip-checksum"`
+	NoCache                     bool                 `toml:"no-cache" json:"no-cache"`
+	AllowVersionMismatch        bool                 `toml:"allow-version-mismatch" json:"allow-version-mismatch"`
+	VegaBinaryPath              string               `toml:"vega-binary" json:"vega-binary"`
+	VisorBinaryPath             string               `toml:"visor-binary" json:"visor-binary"`
+	GenesisFile                 string               `toml:"genesis-file" json:"genesis-file"`
+	GenesisSHA256               string               `toml:"genesis-sha256" json:"genesis-sha256"`
+	SkipGenesisChecksum         bool                 `toml:"skip-genesis-checksum" json:"skip-genesis-checksum"`
+	DryRun                      bool                 `toml:"dry-run" json:"dry-run"`
+	Resume                      bool                 `toml:"resume" json:"resume"`
+	ReuseHome                   bool                 `toml:"reuse-home" json:"reuse-home"`
+	Force                       bool                 `toml:"force" json:"force"`
+	KeepDownloads               bool                 `toml:"keep-downloads" json:"keep-downloads"`
+	DownloadDir                 string               `toml:"download-dir" json:"download-dir"`
+	Debug                       bool                 `toml:"debug" json:"debug"`
+	NetworkHistoryTimeout       time.Duration        `toml:"network-history-timeout" json:"network-history-timeout"`
+	BrokerDialTimeout           time.Duration        `toml:"broker-dial-timeout" json:"broker-dial-timeout"`
+	VisorFirstConnectionRetries int                  `toml:"visor-first-connection-retries" json:"visor-first-connection-retries"`
+	NoAutoUpgrade               bool                 `toml:"no-auto-upgrade" json:"no-auto-upgrade"`
+	TrustPeriod                 time.Duration        `toml:"trust-period" json:"trust-period"`
+	DeriveTrustPeriod           bool                 `toml:"derive-trust-period" json:"derive-trust-period"`
+	TrustHeight                 int                  `toml:"trust-height" json:"trust-height"`
+	TrustHash                   string               `toml:"trust-hash" json:"trust-hash"`
+	SkipRPCCheck                bool                 `toml:"skip-rpc-check" json:"skip-rpc-check"`
+	TargetOS                    string               `toml:"target-os" json:"target-os"`
+	TargetArch                  string               `toml:"target-arch" json:"target-arch"`
+	Pruning                     PruningMode          `toml:"pruning" json:"pruning"`
+	PruningKeepRecent           int                  `toml:"pruning-keep-recent" json:"pruning-keep-recent"`
+	PruningInterval             time.Duration        `toml:"pruning-interval" json:"pruning-interval"`
+	SnapshotInterval            int                  `toml:"snapshot-interval" json:"snapshot-interval"`
+	SnapshotKeepRecent          int                  `toml:"snapshot-keep-recent" json:"snapshot-keep-recent"`
+	WipeOnStartup               bool                 `toml:"wipe-on-startup" json:"wipe-on-startup"`
+	ConfirmWipe                 bool                 `toml:"confirm-wipe" json:"confirm-wipe"`
+	MinFreeSpace                string               `toml:"min-free-space" json:"min-free-space"`
+	StrictFreeSpace             bool                 `toml:"strict-free-space" json:"strict-free-space"`
+	SkipPortCheck               bool                 `toml:"skip-port-check" json:"skip-port-check"`
+	TendermintP2PPort           int                  `toml:"tm-p2p-port" json:"tm-p2p-port"`
+	TendermintRPCPort           int                  `toml:"tm-rpc-port" json:"tm-rpc-port"`
+	DataNodeGRPCPort            int                  `toml:"data-node-grpc-port" json:"data-node-grpc-port"`
+	DataNodeRESTPort            int                  `toml:"data-node-rest-port" json:"data-node-rest-port"`
+	PostSetupHook               string               `toml:"post-setup-hook" json:"post-setup-hook"`
+	IgnoreHookErrors            bool                 `toml:"ignore-hook-errors" json:"ignore-hook-errors"`
+	ConfigOverridesFile         string               `toml:"config-overrides" json:"config-overrides"`
+	Owner                       string               `toml:"owner" json:"owner"`
+
+	// NetworkHistorySegment is the segment selected for the NetworkHistoryFromHeight
+	NetworkHistorySegment *types.NetworkHistorySegment `toml:"-" json:"-"`
+	// AssumeYes skips the confirmation before the Force removes the existing homes contents
+	AssumeYes bool `toml:"-" json:"-"`
+	// HomesToRemove are the existing homes, the user agreed to remove. They are removed by the generator,
+	// after the setup is confirmed
+	HomesToRemove []string `toml:"-" json:"-"`
+	// HomesToClean are the existing homes, which contents are removed for the Force setting. They are
+	// cleaned by the generator, after the setup is confirmed
+	HomesToClean []string `toml:"-" json:"-"`
 }
 
+const minimalTimeout = time.Minute
+
+var envVariableNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// sqlPasswordConfigValue returns the password written into the data-node config. The environment variable
+// placeholder is written instead of the password when the DBPasswordEnv is set.
+func (settings GenerateSettings) sqlPasswordConfigValue() string {
+	if settings.DBPasswordEnv != "" {
+		return fmt.Sprintf("${%s}", settings.DBPasswordEnv)
+	}
+
+	return settings.SQLCredentials.Pass
+}
+
+// DefaultVisorFirstConnectionRetries makes visor wait 24h for the first connection to the node
+const DefaultVisorFirstConnectionRetries = 43200
+
+// VisorFirstConnectionRetryDelay is the delay between visor attempts to connect to the node
+const VisorFirstConnectionRetryDelay = 2 * time.Second
+
+// DefaultTrustPeriod is used for statesync, when the trust period is zero and it is not derived from the chain
+const DefaultTrustPeriod = 672 * time.Hour
+
+// RequiredTimescaleDBVersion is the minimal TimescaleDB extension version supported by the data-node
+const RequiredTimescaleDBVersion = "v2.8.0"
+
+// timescaleDBVersionQuery returns version of the enabled extension and falls back to the installed one
+const timescaleDBVersionQuery = `SELECT version FROM (
+	SELECT 1 AS priority, extversion AS version FROM pg_extension WHERE extname = 'timescaledb'
+	UNION
+	SELECT 2 AS priority, COALESCE(installed_version, default_version) AS version FROM pg_available_extensions WHERE name = 'timescaledb'
+) AS versions ORDER BY priority LIMIT 1;`
+
 func DefaultGenerateSettings() *GenerateSettings {
 	return &GenerateSettings{
 		NonInteractive:              false,
@@ -82,24 +191,88 @@ func DefaultGenerateSettings() *GenerateSettings {
 		TendermintHome:              filepath.Join(utils.CurrentUserHomePath(), "tendermint_home"),
 		RemoveExistingFiles:         false,
 		NetworkHistoryMinBlockCount: 100,
+		NetworkHistoryTimeout:       4 * time.Hour,
+		BrokerDialTimeout:           4 * time.Hour,
+		VisorFirstConnectionRetries: DefaultVisorFirstConnectionRetries,
+		WipeOnStartup:               true,
+		SQLAdminUser:                defaultSQLAdminUser,
+		Pruning:                     PruningDefault,
+		NodeType:                    vegacmd.VegaNodeFull,
+		TendermintP2PPort:           DefaultTendermintP2PPort,
+		TendermintRPCPort:           DefaultTendermintRPCPort,
+		DataNodeGRPCPort:            DefaultDataNodeGRPCPort,
+		DataNodeRESTPort:            DefaultDataNodeRESTPort,
 
 		SQLCredentials: types.SQLCredentials{
-			Host:         "localhost",
-			User:         "vega",
-			Pass:         "vega",
-			Port:         5432,
-			DatabaseName: "vega",
+			Host:           "localhost",
+			User:           "vega",
+			Pass:           "vega",
+			Port:           5432,
+			DatabaseName:   "vega",
+			SSLMode:        SSLModeDisable,
+			ConnectTimeout: DefaultSQLConnectTimeout,
 		},
 	}
 }
 
+// LoadSettingsTree reads the TOML or YAML settings file. YAML is picked for the .yaml and .yml files.
+func LoadSettingsTree(filePath string) (*toml.Tree, error) {
+	extension := strings.ToLower(filepath.Ext(filePath))
+	if extension != ".yaml" && extension != ".yml" {
+		return toml.LoadFile(filePath)
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	yamlValues := map[string]interface{}{}
+	if err := yaml.Unmarshal(content, &yamlValues); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal yaml: %w", err)
+	}
+
+	// Settings have only toml tags, so yaml is converted into the toml tree
+	return toml.TreeFromMap(normalizeYAMLMap(yamlValues))
+}
+
+// normalizeYAMLMap converts nested map[interface{}]interface{} produced by yaml into map[string]interface{}
+func normalizeYAMLMap(values map[string]interface{}) map[string]interface{} {
+	result := map[string]interface{}{}
+	for key, value := range values {
+		result[key] = normalizeYAMLValue(value)
+	}
+
+	return result
+}
+
+func normalizeYAMLValue(value interface{}) interface{} {
+	switch typedValue := value.(type) {
+	case map[interface{}]interface{}:
+		result := map[string]interface{}{}
+		for key, nestedValue := range typedValue {
+			result[fmt.Sprintf("%v", key)] = normalizeYAMLValue(nestedValue)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(typedValue))
+		for idx, nestedValue := range typedValue {
+			result[idx] = normalizeYAMLValue(nestedValue)
+		}
+		return result
+	default:
+		return value
+	}
+}
+
 func ReadGeneratorSettingsFromFile(filePath string) (*GenerateSettings, error) {
-	tomlTree, err := toml.LoadFile(filePath)
+	tomlTree, err := LoadSettingsTree(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load config file: %w", err)
 	}
 
-	result := &GenerateSettings{}
+	// Values missing in the file are taken from defaults
+	result := DefaultGenerateSettings()
 	if err := tomlTree.Unmarshal(result); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config file: %w", err)
 	}
@@ -107,6 +280,191 @@ func ReadGeneratorSettingsFromFile(filePath string) (*GenerateSettings, error) {
 	return result, nil
 }
 
+func IsStartupModeValid(mode StartupMode) bool {
+	return mode == StartFromBlock0 || mode == StartFromNetworkHistory
+}
+
+// DumpSettings returns settings in the TOML format, that can be used as a settings file. Passwords are
+// left empty, they are asked by the prompts or read from the --sql-password-file and the PGPASSWORD.
+func (settings GenerateSettings) DumpSettings() ([]byte, error) {
+	settings.SQLCredentials.Pass = ""
+	settings.SQLAdminPassword = ""
+	settings.DatabaseURL = removeURLPassword(settings.DatabaseURL)
+
+	result, err := toml.Marshal(settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal settings: %w", err)
+	}
+
+	return result, nil
+}
+
+// TargetPlatform returns the platform the node is set up for. It defaults to the current platform.
+func (settings GenerateSettings) TargetPlatform() github.Platform {
+	platform := github.CurrentPlatform()
+	if settings.TargetOS != "" {
+		platform.OS = settings.TargetOS
+	}
+	if settings.TargetArch != "" {
+		platform.Arch = settings.TargetArch
+	}
+
+	return platform
+}
+
+// Validate checks values that cannot be verified by the interactive prompts
+func (settings GenerateSettings) Validate() error {
+	if !IsStartupModeValid(settings.Mode) {
+		return fmt.Errorf(
+			"invalid startup mode(%s): expected %s or %s",
+			settings.Mode,
+			StartFromBlock0,
+			StartFromNetworkHistory,
+		)
+	}
+
+	if settings.Force && settings.ReuseHome {
+		return fmt.Errorf("force and reuse home cannot be used together: force removes the homes contents")
+	}
+
+	if settings.NetworkHistoryTimeout < minimalTimeout {
+		return fmt.Errorf(
+			"network history timeout(%s) must be at least %s",
+			settings.NetworkHistoryTimeout,
+			minimalTimeout,
+		)
+	}
+
+	for _, peer := range settings.ExtraPersistentPeers {
+		if peer = strings.TrimSpace(peer); peer == "" {
+			continue
+		}
+
+		if err := network.ValidatePeerAddress(peer); err != nil {
+			return fmt.Errorf("invalid extra persistent peer: %w", err)
+		}
+	}
+
+	if settings.GenesisSHA256 != "" {
+		if !utils.IsSHA256(settings.GenesisSHA256) {
+			return fmt.Errorf("invalid genesis sha256(%s): expected hex encoded sha256 checksum", settings.GenesisSHA256)
+		}
+
+		if settings.SkipGenesisChecksum {
+			return fmt.Errorf("genesis sha256 cannot be set when the genesis checksum verification is skipped")
+		}
+	}
+
+	if err := settings.validateTrustedBlock(); err != nil {
+		return err
+	}
+
+	if err := settings.validatePorts(); err != nil {
+		return err
+	}
+
+	if err := settings.validatePostSetupHook(); err != nil {
+		return err
+	}
+
+	if settings.Version != "" && settings.Mode == StartFromBlock0 {
+		return fmt.Errorf("version(%s) cannot be used in the %s mode: the genesis version is used", settings.Version, StartFromBlock0)
+	}
+
+	if settings.VersionConstraint != "" {
+		if settings.Version != "" {
+			return fmt.Errorf("version and version constraint cannot be used together")
+		}
+
+		if settings.Mode == StartFromBlock0 {
+			return fmt.Errorf("version constraint cannot be used in the %s mode: the genesis version is used", StartFromBlock0)
+		}
+
+		if err := github.ValidateVersionConstraint(settings.VersionConstraint); err != nil {
+			return err
+		}
+	}
+
+	if err := settings.validateConfigOverrides(); err != nil {
+		return err
+	}
+
+	if settings.Owner != "" {
+		if _, _, err := utils.LookupOwner(settings.Owner); err != nil {
+			return fmt.Errorf("invalid homes owner: %w", err)
+		}
+	}
+
+	if err := settings.validatePruning(); err != nil {
+		return err
+	}
+
+	if err := settings.validateSnapshots(); err != nil {
+		return err
+	}
+
+	if settings.TrustPeriod < 0 {
+		return fmt.Errorf("trust period(%s) must not be negative", settings.TrustPeriod)
+	}
+
+	if settings.SQLCredentials.ConnectTimeout < 0 {
+		return fmt.Errorf("sql connect timeout(%s) must not be negative", settings.SQLCredentials.ConnectTimeout)
+	}
+
+	if settings.DBPasswordEnv != "" && !envVariableNamePattern.MatchString(settings.DBPasswordEnv) {
+		return fmt.Errorf(
+			"invalid db password env(%s): expected name of the environment variable, e.g. DATANODE_DB_PASSWORD",
+			settings.DBPasswordEnv,
+		)
+	}
+
+	if err := ValidateSSLSettings(settings.SQLCredentials); err != nil {
+		return fmt.Errorf("invalid sql credentials: %w", err)
+	}
+
+	if !slices.Contains(vegacmd.AvailableVegaNodeModes(), settings.NodeType) {
+		return fmt.Errorf("invalid node type(%s): expected one of %v", settings.NodeType, vegacmd.AvailableVegaNodeModes())
+	}
+	// Validator requires the node wallets, they are generated only by the validator setup
+	if settings.NodeType == vegacmd.VegaNodeValidator {
+		return fmt.Errorf("node type %s is not supported for the data-node: use the validator setup", vegacmd.VegaNodeValidator)
+	}
+
+	if settings.TimescaleDBVersion != "" {
+		timescaleVersion := "v" + strings.TrimPrefix(settings.TimescaleDBVersion, "v")
+		if !semver.IsValid(timescaleVersion) || semver.Compare(timescaleVersion, RequiredTimescaleDBVersion) < 0 {
+			return fmt.Errorf(
+				"invalid timescaledb version(%s): expected version %s or newer, e.g. 2.8.0",
+				settings.TimescaleDBVersion,
+				RequiredTimescaleDBVersion,
+			)
+		}
+	}
+
+	if settings.MinFreeSpace != "" {
+		if _, err := utils.ParseSize(settings.MinFreeSpace); err != nil {
+			return fmt.Errorf("invalid minimum free space: %w", err)
+		}
+	}
+
+	if settings.VisorFirstConnectionRetries < 1 {
+		return fmt.Errorf(
+			"visor first connection retries(%d) must be a positive integer",
+			settings.VisorFirstConnectionRetries,
+		)
+	}
+
+	if settings.BrokerDialTimeout < minimalTimeout {
+		return fmt.Errorf(
+			"broker dial timeout(%s) must be at least %s",
+			settings.BrokerDialTimeout,
+			minimalTimeout,
+		)
+	}
+
+	return nil
+}
+
 func NewStateMachine(logger *zap.SugaredLogger, config GenerateSettings) StateMachine {
 	return StateMachine{
 		logger:       logger,
@@ -124,13 +482,19 @@ func (state StateMachine) Dump() string {
 	return string(result)
 }
 
-func (state *StateMachine) Run(apiClient *vegaapi.NetworkAPI, ui *input.UI, networkConfig network.NetworkConfig) error {
+// Run asks for the settings. Requests to the network are cancelled when the ctx is done.
+func (state *StateMachine) Run(
+	ctx context.Context,
+	apiClient *vegaapi.NetworkAPI,
+	ui *input.UI,
+	networkConfig network.NetworkConfig,
+) error {
 STATE_RUN:
 	for {
 		switch state.CurrentState {
 		case StateSelectStartupMode:
 			if state.Settings.NonInteractive {
-				state.logger.Info("NonInteractive: Using %s mode", state.Settings.Mode)
+				state.logger.Infof("NonInteractive: Using %s mode", state.Settings.Mode)
 			} else {
 				mode, err := SelectStartupMode(ui, state.Settings.Mode)
 				if err != nil {
@@ -147,7 +511,7 @@ STATE_RUN:
 
 		case StateSelectHowManyBlockToSync:
 			if state.Settings.NonInteractive {
-				state.logger.Info("NonInteractive: Will sync %d blocks from the network history", state.Settings.NetworkHistoryMinBlockCount)
+				state.logger.Infof("NonInteractive: Will sync %d blocks from the network history", state.Settings.NetworkHistoryMinBlockCount)
 				state.CurrentState = SelectDataRetention
 
 				continue
@@ -163,6 +527,7 @@ STATE_RUN:
 		case SelectDataRetention:
 			if state.Settings.NonInteractive {
 				if !vega.IsRetentionPolicyValid(state.Settings.DataRetention) {
+					state.Settings.DataRetention = "forever"
 					state.logger.Info("Data node retention: forever")
 				} else {
 					state.logger.Infof("Data node retention: %s", state.Settings.DataRetention)
@@ -172,7 +537,7 @@ STATE_RUN:
 				continue
 			}
 
-			retentionPolicy, err := AskRetentionPolicy(ui)
+			retentionPolicy, err := AskRetentionPolicy(ui, state.Settings.DataRetention)
 			if err != nil {
 				return fmt.Errorf("failed getting retention policy: %w", err)
 			}
@@ -180,8 +545,13 @@ STATE_RUN:
 			state.CurrentState = StateSelectVisorHome
 
 		case StateSelectVisorHome:
-			if state.Settings.NonInteractive {
-				state.logger.Info("NonInteractive: Using %s for vegavisor home", state.Settings.VisorHome)
+			if state.Settings.HomeBase != "" {
+				state.Settings.deriveHomes()
+				state.logger.Infof("Using homes derived from the %s home base", state.Settings.HomeBase)
+			}
+
+			if state.Settings.NonInteractive || state.Settings.HomeBase != "" {
+				state.logger.Infof("Using %s for vegavisor home", state.Settings.VisorHome)
 			} else {
 				visorHome, err := uilib.AskPath(ui, "vegavisor home", state.Settings.VisorHome)
 				if err != nil {
@@ -191,18 +561,94 @@ STATE_RUN:
 				state.Settings.VisorHome = visorHome
 			}
 
-			if utils.FileExists(state.Settings.VisorHome) {
-				state.CurrentState = StateExistingVisorHome
+			state.CurrentState = StateSelectVegaHome
+
+		case StateSelectVegaHome:
+			if state.Settings.HomeBase != "" {
+				state.logger.Infof("Using %s for vega home and %s for data-node home", state.Settings.VegaHome, state.Settings.DataNodeHome)
+			} else if state.Settings.NonInteractive {
+				state.logger.Infof("NonInteractive: Using %s for vega home", state.Settings.VegaHome)
+
+				state.Settings.DataNodeHome = state.Settings.VegaHome
 			} else {
-				state.CurrentState = StateSelectVegaHome
+				vegaHome, err := uilib.AskPath(ui, "vega home", state.Settings.VegaHome)
+				if err != nil {
+					return fmt.Errorf("failed getting vega home: %w", err)
+				}
+				state.Settings.VegaHome = vegaHome
+				state.Settings.DataNodeHome = vegaHome
 			}
 
+			state.CurrentState = StateSelectTendermintHome
+
+		case StateSelectTendermintHome:
+			if state.Settings.NonInteractive || state.Settings.HomeBase != "" {
+				state.logger.Infof("Using %s for tendermint home", state.Settings.TendermintHome)
+			} else {
+				tendermintHome, err := uilib.AskPath(ui, "tendermint home", state.Settings.TendermintHome)
+				if err != nil {
+					return fmt.Errorf("failed getting tendermint home: %w", err)
+				}
+				state.Settings.TendermintHome = tendermintHome
+			}
+
+			state.CurrentState = StateValidateHomes
+
+		case StateValidateHomes:
+			if err := validateHomes(state.Settings); err != nil {
+				// Derived homes are not asked again
+				if state.Settings.NonInteractive || state.Settings.HomeBase != "" {
+					return fmt.Errorf("invalid homes: %w", err)
+				}
+
+				fmt.Printf("Invalid homes: %s. Please provide homes again\n", err.Error())
+				state.CurrentState = StateSelectVisorHome
+				break
+			}
+
+			if state.Settings.ReuseHome {
+				if err := ValidateExistingHomes(state.Settings); err != nil {
+					return fmt.Errorf("cannot reuse existing homes: %w", err)
+				}
+				state.logger.Info("Existing homes are initialized: only the config files will be updated")
+			}
+
+			// Homes are selected again when the summary is not correct
+			state.Settings.HomesToRemove = nil
+			state.Settings.HomesToClean = nil
+			state.CurrentState = StateExistingVisorHome
+
 		case StateExistingVisorHome:
+			if !utils.FileExists(state.Settings.VisorHome) {
+				state.CurrentState = StateExistingVegaHome
+				break
+			}
+
+			if state.isResuming() {
+				state.logger.Infof("Resuming setup: keeping existing vegavisor home %s", state.Settings.VisorHome)
+				state.CurrentState = StateExistingVegaHome
+				break
+			}
+
+			if state.Settings.ReuseHome {
+				state.logger.Infof("Reusing existing vegavisor home %s", state.Settings.VisorHome)
+				state.CurrentState = StateExistingVegaHome
+				break
+			}
+
+			if state.Settings.Force {
+				if err := state.cleanHome(ui, "vegavisor home", state.Settings.VisorHome); err != nil {
+					return fmt.Errorf("failed to clean vegavisor home: %w", err)
+				}
+				state.CurrentState = StateExistingVegaHome
+				break
+			}
+
 			if state.Settings.NonInteractive {
 				if !state.Settings.RemoveExistingFiles {
 					return fmt.Errorf("cannot remove existing visor home: non-interactive mode is enabled and config flag 'remove-existing-file' is disabled: provide different vegavisor home in the config or remove it manually")
 				}
-				state.logger.Info("NonInteractive: Will remove vegavisor home: %s", state.Settings.VisorHome)
+				state.logger.Infof("NonInteractive: Will remove vegavisor home: %s", state.Settings.VisorHome)
 			} else {
 				removeAnswer, err := uilib.AskRemoveExistingFile(ui, state.Settings.VisorHome, uilib.AnswerYes)
 				if err != nil {
@@ -214,33 +660,36 @@ STATE_RUN:
 				}
 			}
 
-			if err := os.RemoveAll(state.Settings.VisorHome); err != nil {
-				return fmt.Errorf("failed to remove vegavisor home: %w", err)
-			}
+			state.removeHome(state.Settings.VisorHome)
 
-			state.CurrentState = StateSelectVegaHome
+			state.CurrentState = StateExistingVegaHome
 
-		case StateSelectVegaHome:
-			if state.Settings.NonInteractive {
-				state.logger.Infof("NonInteractive: Using %s for vega home", state.Settings.VegaHome)
+		case StateExistingVegaHome:
+			if !utils.FileExists(state.Settings.VegaHome) {
+				state.CurrentState = StateExistingTendermintHome
+				break
+			}
 
-				state.Settings.DataNodeHome = state.Settings.VegaHome
-			} else {
-				vegaHome, err := uilib.AskPath(ui, "vega home", state.Settings.VegaHome)
-				if err != nil {
-					return fmt.Errorf("failed getting vega home: %w", err)
-				}
-				state.Settings.VegaHome = vegaHome
-				state.Settings.DataNodeHome = vegaHome
+			if state.isResuming() {
+				state.logger.Infof("Resuming setup: keeping existing vega home %s", state.Settings.VegaHome)
+				state.CurrentState = StateExistingTendermintHome
+				break
 			}
 
-			if utils.FileExists(state.Settings.VegaHome) {
-				state.CurrentState = StateExistingVegaHome
-			} else {
-				state.CurrentState = StateSelectTendermintHome
+			if state.Settings.ReuseHome {
+				state.logger.Infof("Reusing existing vega home %s", state.Settings.VegaHome)
+				state.CurrentState = StateExistingTendermintHome
+				break
+			}
+
+			if state.Settings.Force {
+				if err := state.cleanHome(ui, "vega home", state.Settings.VegaHome); err != nil {
+					return fmt.Errorf("failed to clean vega home: %w", err)
+				}
+				state.CurrentState = StateExistingTendermintHome
+				break
 			}
 
-		case StateExistingVegaHome:
 			if state.Settings.NonInteractive {
 				if !state.Settings.RemoveExistingFiles {
 					return fmt.Errorf("cannot remove existing vega home: non-interactive mode is enabled and config flag 'remove-existing-file' is disabled: provide different vega home in the config or remove it manually")
@@ -257,35 +706,41 @@ STATE_RUN:
 				}
 			}
 
-			if err := os.RemoveAll(state.Settings.VegaHome); err != nil {
-				return fmt.Errorf("failed to remove vega home: %w", err)
+			state.removeHome(state.Settings.VegaHome)
+
+			state.CurrentState = StateExistingTendermintHome
+
+		case StateExistingTendermintHome:
+			if !utils.FileExists(state.Settings.TendermintHome) {
+				state.CurrentState = StateGetSQLCredentials
+				break
 			}
 
-			state.CurrentState = StateSelectTendermintHome
+			if state.isResuming() {
+				state.logger.Infof("Resuming setup: keeping existing tendermint home %s", state.Settings.TendermintHome)
+				state.CurrentState = StateGetSQLCredentials
+				break
+			}
 
-		case StateSelectTendermintHome:
-			if state.Settings.NonInteractive {
-				state.logger.Infof("NonInteractive: Using %s for tendermint home", state.Settings.TendermintHome)
-			} else {
-				tendermintHome, err := uilib.AskPath(ui, "tendermint home", state.Settings.TendermintHome)
-				if err != nil {
-					return fmt.Errorf("failed getting tendermint home: %w", err)
-				}
-				state.Settings.TendermintHome = tendermintHome
+			if state.Settings.ReuseHome {
+				state.logger.Infof("Reusing existing tendermint home %s", state.Settings.TendermintHome)
+				state.CurrentState = StateGetSQLCredentials
+				break
 			}
 
-			if utils.FileExists(state.Settings.TendermintHome) {
-				state.CurrentState = StateExistingTendermintHome
-			} else {
+			if state.Settings.Force {
+				if err := state.cleanHome(ui, "tendermint home", state.Settings.TendermintHome); err != nil {
+					return fmt.Errorf("failed to clean tendermint home: %w", err)
+				}
 				state.CurrentState = StateGetSQLCredentials
+				break
 			}
 
-		case StateExistingTendermintHome:
 			if state.Settings.NonInteractive {
 				if !state.Settings.RemoveExistingFiles {
 					return fmt.Errorf("cannot remove existing tendermint home: non-interactive mode is enabled and config flag 'remove-existing-file' is disabled: provide different tendermint home in the config or remove it manually")
 				}
-				state.logger.Infof("NonInteractive: Will remove vega home: %s", state.Settings.VegaHome)
+				state.logger.Infof("NonInteractive: Will remove tendermint home: %s", state.Settings.TendermintHome)
 			} else {
 				removeAnswer, err := uilib.AskRemoveExistingFile(ui, state.Settings.TendermintHome, uilib.AnswerYes)
 				if err != nil {
@@ -297,64 +752,56 @@ STATE_RUN:
 				}
 			}
 
-			if err := os.RemoveAll(state.Settings.TendermintHome); err != nil {
-				return fmt.Errorf("failed to remove tendermint home: %w", err)
-			}
+			state.removeHome(state.Settings.TendermintHome)
 
 			state.CurrentState = StateGetSQLCredentials
 
 		case StateGetSQLCredentials:
-			if state.Settings.NonInteractive {
+			// Prompts are skipped when the connection url is provided
+			if state.Settings.NonInteractive || state.Settings.DatabaseURL != "" {
 				state.logger.Infof(
-					"NonInteractive: Using provided SQL settings: User(%s), Password(***), Host(%s), Port(%d), DbName(%s)",
+					"Using provided SQL settings: User(%s), Password(***), Host(%s), Port(%d), DbName(%s)",
 					state.Settings.SQLCredentials.User,
 					state.Settings.SQLCredentials.Host,
 					state.Settings.SQLCredentials.Port,
 					state.Settings.SQLCredentials.DatabaseName,
 				)
 
-				if err := checkSQLCredentials(state.Settings.SQLCredentials); err != nil {
-					return fmt.Errorf("failed to check sql credentials: %w", err)
+				if err := state.checkOrCreateSQLDatabase(ui)(state.Settings.SQLCredentials); err != nil {
+					return types.NewDBError(fmt.Errorf("failed to check sql credentials: %w", err))
 				}
 
-				state.CurrentState = StateCheckLatestVersion
+				state.CurrentState = StateCheckExistingData
 				continue
 			}
 
-			sqlCredentials, err := AskSQLCredentials(ui, state.Settings.SQLCredentials, checkSQLCredentials)
+			sqlCredentials, err := AskSQLCredentials(
+				ui,
+				state.Settings.SQLCredentials,
+				!state.Settings.SkipSQLPasswordPrompt,
+				SQLRequirements(state.Settings.Mode),
+				state.checkOrCreateSQLDatabase(ui),
+			)
 			if err != nil {
-				return fmt.Errorf("failed getting sql credentials: %w", err)
+				return types.NewDBError(fmt.Errorf("failed getting sql credentials: %w", err))
 			}
 			state.Settings.SQLCredentials = *sqlCredentials
-			state.CurrentState = StateCheckLatestVersion
+			state.CurrentState = StateCheckExistingData
 
-		case StateCheckLatestVersion:
-			statisticsResponse, err := apiClient.Statistics(context.Background())
-			if err != nil {
-				return fmt.Errorf("failed to get response for the /statistics endpoint from the mainnet servers: %w", err)
+		case StateCheckExistingData:
+			if err := state.confirmDatabaseWipe(ui); err != nil {
+				return types.NewDBError(err)
 			}
+			state.CurrentState = StateCheckLatestVersion
 
-			if state.Settings.Mode == StartFromBlock0 {
-				state.Settings.VegaBinaryVersion = networkConfig.GenesisVersion
-				state.Settings.VisorBinaryVersion = networkConfig.LowestVisorVersion
-			} else {
-				releaseVersion := statisticsResponse.AppVersion
-
-				for _, binaryOverride := range networkConfig.BinariesOverride {
-					if binaryOverride.OldVersion == releaseVersion && statisticsResponse.BlockHeight >= binaryOverride.Block {
-						releaseVersion = binaryOverride.NewVersion
-					}
-				}
-
-				state.Settings.VegaBinaryVersion = releaseVersion
-				state.Settings.VisorBinaryVersion = statisticsResponse.AppVersion
+		case StateCheckLatestVersion:
+			if err := resolveNetworkSettings(ctx, state.logger, apiClient, &state.Settings, networkConfig); err != nil {
+				return err
 			}
-
-			state.Settings.VegaChainId = statisticsResponse.ChainID
 			state.CurrentState = StateSummary
 
 		case StateSummary:
-			printSummary(state.Settings)
+			printSummary(state.Settings, networkConfig)
 
 			if state.Settings.NonInteractive {
 				state.logger.Info("NonInteractive: Moving to installation steps")
@@ -378,42 +825,402 @@ STATE_RUN:
 	return nil
 }
 
-func checkSQLCredentials(creds types.SQLCredentials) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-	db := pg.Connect(&pg.Options{
-		Addr:     fmt.Sprintf("%s:%d", creds.Host, creds.Port),
-		User:     creds.User,
-		Password: creds.Pass,
-		Database: creds.DatabaseName,
-	})
-	defer db.Close(ctx)
+// resolveNetworkSettings completes the settings that depend on the running network: the binary versions,
+// the chain id and the network-history segment
+func resolveNetworkSettings(
+	ctx context.Context,
+	logger *zap.SugaredLogger,
+	apiClient *vegaapi.NetworkAPI,
+	settings *GenerateSettings,
+	networkConfig network.NetworkConfig,
+) error {
+	statisticsResponse, err := apiClient.Statistics(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get response for the /statistics endpoint from the network servers: %w", err)
+	}
+
+	if settings.Mode == StartFromBlock0 {
+		// Replay must start with the binary the network was started with, then visor upgrades it
+		if networkConfig.GenesisVersion == "" || networkConfig.LowestVisorVersion == "" {
+			return fmt.Errorf("network config must define genesis-version and lowest-visor-version to start from block 0")
+		}
+		if settings.Version != "" {
+			logger.Warnf("The --version=%s flag is ignored when starting from block 0", settings.Version)
+		}
+		if settings.VersionConstraint != "" {
+			logger.Warnf("The --vega-version-constraint=%s flag is ignored when starting from block 0", settings.VersionConstraint)
+		}
+		logger.Infof("Using genesis vega version %s to start from block 0", networkConfig.GenesisVersion)
 
-	var n int
-	_, err := db.QueryOne(ctx, pg.Scan(&n), "SELECT 1")
+		settings.VegaBinaryVersion = networkConfig.GenesisVersion
+		settings.VisorBinaryVersion = networkConfig.LowestVisorVersion
+	} else {
+		releaseVersion := statisticsResponse.AppVersion
+
+		for _, binaryOverride := range networkConfig.BinariesOverride {
+			if binaryOverride.OldVersion == releaseVersion && statisticsResponse.BlockHeight >= binaryOverride.Block {
+				releaseVersion = binaryOverride.NewVersion
+			}
+		}
+
+		settings.VegaBinaryVersion = releaseVersion
+		settings.VisorBinaryVersion = statisticsResponse.AppVersion
+
+		if settings.Version == github.LatestVersion {
+			latestVersion, err := github.LatestReleaseTag(networkConfig.Repository)
+			if err != nil {
+				return fmt.Errorf("failed to resolve latest version: %w", err)
+			}
+			logger.Infof("Resolved latest version to %s", latestVersion)
+
+			settings.VegaBinaryVersion = latestVersion
+			settings.VisorBinaryVersion = latestVersion
+		} else if settings.Version != "" {
+			settings.VegaBinaryVersion = settings.Version
+			settings.VisorBinaryVersion = settings.Version
+		} else if settings.VersionConstraint != "" {
+			constraintVersion, err := github.ResolveVersionConstraint(networkConfig.Repository, settings.VersionConstraint)
+			if err != nil {
+				return fmt.Errorf("failed to resolve version constraint: %w", err)
+			}
+			logger.Infof("Resolved version constraint %s to %s", settings.VersionConstraint, constraintVersion)
+
+			settings.VegaBinaryVersion = constraintVersion
+			settings.VisorBinaryVersion = constraintVersion
+		}
+	}
+
+	chainID, err := ResolveChainID(settings.ChainID, networkConfig, statisticsResponse.ChainID)
 	if err != nil {
 		return err
 	}
+	settings.VegaChainId = chainID
+
+	if settings.NetworkHistoryFromHeight > 0 {
+		if settings.Mode != StartFromNetworkHistory {
+			return fmt.Errorf("network-history from height is supported only in the %s mode", StartFromNetworkHistory)
+		}
+
+		segment, err := SelectNetworkHistorySegment(
+			ctx,
+			apiClient,
+			statisticsResponse.BlockHeight,
+			settings.NetworkHistoryFromHeight,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to select network-history segment: %w", err)
+		}
+		logger.Infof(
+			"Selected network-history segment %s(blocks %s-%s)",
+			segment.HistorySegmentId,
+			segment.FromHeight,
+			segment.ToHeight,
+		)
+		settings.NetworkHistorySegment = segment
+	}
 
-	var timescaleVersion string
-	_, err = db.QueryOne(
-		ctx,
-		pg.Scan(&timescaleVersion),
-		`SELECT COALESCE(installed_version, default_version) AS extversion FROM pg_available_extensions WHERE name = 'timescaledb' LIMIT 1;`,
+	return nil
+}
+
+// validateHomes makes sure homes do not overlap. Data-node shares the home with vega.
+func validateHomes(settings GenerateSettings) error {
+	homes := []struct {
+		name string
+		path string
+	}{
+		{name: "vegavisor home", path: settings.VisorHome},
+		{name: "vega home", path: settings.VegaHome},
+		{name: "tendermint home", path: settings.TendermintHome},
+	}
+	if settings.DataNodeHome != "" && settings.DataNodeHome != settings.VegaHome {
+		homes = append(homes, struct {
+			name string
+			path string
+		}{name: "data-node home", path: settings.DataNodeHome})
+	}
+
+	for i := 0; i < len(homes); i++ {
+		for j := i + 1; j < len(homes); j++ {
+			if utils.PathsOverlap(homes[i].path, homes[j].path) {
+				return types.NewInputError(fmt.Errorf(
+					"%s(%s) and %s(%s) must not be the same or nested in each other",
+					homes[i].name,
+					homes[i].path,
+					homes[j].name,
+					homes[j].path,
+				))
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkOrCreateSQLDatabase returns check function, that offers creating the database and the user,
+// when they are missing, and installing the timescaledb extension. In the non-interactive mode the database
+// is created only with the create-db flag.
+func (state *StateMachine) checkOrCreateSQLDatabase(ui *input.UI) func(types.SQLCredentials) error {
+	return func(creds types.SQLCredentials) error {
+		err := checkSQLCredentials(creds)
+		if isMissingDatabaseError(err) {
+			err = state.createSQLDatabase(ui, creds, err)
+		}
+
+		if err == nil || isTimescaleDBVersionError(err) {
+			err = state.installTimescaleDB(ui, creds, err)
+		}
+
+		if isTimescaleDBVersionError(err) {
+			// The network history mode loads the history segments into the timescaledb tables on the first start
+			if state.Settings.Mode != StartFromBlock0 {
+				return fmt.Errorf("the %s mode requires the timescaledb extension: %w", state.Settings.Mode, err)
+			}
+
+			state.logger.Warnf(
+				"Continuing in the %s mode, but the timescaledb extension must be installed before the data-node start: %s",
+				state.Settings.Mode,
+				err.Error(),
+			)
+			err = nil
+		}
+
+		if err == nil {
+			if preloadErr := timescaleDBPreloadWarning(creds); preloadErr != nil {
+				state.logger.Warnf("TimescaleDB is not configured properly, the data-node may misbehave: %s", preloadErr.Error())
+			}
+		}
+
+		return err
+	}
+}
+
+// installTimescaleDB offers creating the timescaledb extension or updating it to the pinned version. The
+// checkErr is returned when the extension is not installed. In the non-interactive mode the extension is
+// installed only with the install-timescaledb flag.
+func (state *StateMachine) installTimescaleDB(ui *input.UI, creds types.SQLCredentials, checkErr error) error {
+	version := RequiredTimescaleDBVersion
+	if state.Settings.TimescaleDBVersion != "" {
+		version = "v" + strings.TrimPrefix(state.Settings.TimescaleDBVersion, "v")
+	}
+
+	createdVersion, err := timescaleDBExtensionVersion(creds)
+	if err != nil {
+		return err
+	}
+	if createdVersion != "" && semver.Compare("v"+strings.TrimPrefix(createdVersion, "v"), version) >= 0 {
+		return checkErr
+	}
+
+	if !state.Settings.InstallTimescaleDB {
+		if state.Settings.NonInteractive {
+			return checkErr
+		}
+
+		question := fmt.Sprintf("The timescaledb extension is not created in the %s database. Do you want to create it in the %s version?", creds.DatabaseName, version)
+		if createdVersion != "" {
+			question = fmt.Sprintf("The timescaledb extension %s is created in the %s database. Do you want to update it to the %s version?", createdVersion, creds.DatabaseName, version)
+		}
+		answer, err := uilib.AskYesNo(ui, question, uilib.AnswerNo)
+		if err != nil {
+			return fmt.Errorf("failed to ask for installing the timescaledb extension: %w", err)
+		}
+		if answer == uilib.AnswerNo {
+			return checkErr
+		}
+	}
+
+	if state.Settings.DryRun {
+		state.logger.Infof("Dry run: would install the timescaledb extension %s in the %s database", version, creds.DatabaseName)
+
+		return checkErr
+	}
+
+	// Only superuser can create the timescaledb extension, so the admin user is preferred when provided
+	installCreds := creds
+	if state.Settings.SQLAdminPassword != "" {
+		installCreds.User = state.Settings.SQLAdminUser
+		installCreds.Pass = state.Settings.SQLAdminPassword
+	}
+	if err := installTimescaleDBExtension(state.logger, installCreds, version); err != nil {
+		return fmt.Errorf("failed to install the timescaledb extension: %w", err)
+	}
+
+	return checkSQLCredentials(creds)
+}
+
+// createSQLDatabase offers creating the missing database and the user. The checkErr is returned when
+// the database is not created.
+func (state *StateMachine) createSQLDatabase(ui *input.UI, creds types.SQLCredentials, checkErr error) error {
+	if state.Settings.NonInteractive && !state.Settings.CreateDatabase {
+		return checkErr
+	}
+
+	adminCreds := types.SQLCredentials{
+		User: state.Settings.SQLAdminUser,
+		Pass: state.Settings.SQLAdminPassword,
+	}
+
+	if !state.Settings.NonInteractive {
+		if !state.Settings.CreateDatabase {
+			answer, askErr := uilib.AskYesNo(
+				ui,
+				fmt.Sprintf(
+					"Cannot connect to the %s database as %s(%s). Do you want to create the database and the user?",
+					creds.DatabaseName,
+					creds.User,
+					checkErr.Error(),
+				),
+				uilib.AnswerNo,
+			)
+			if askErr != nil {
+				return fmt.Errorf("failed to ask for creating the database: %w", askErr)
+			}
+
+			if answer == uilib.AnswerNo {
+				return checkErr
+			}
+		}
+
+		var err error
+		adminCreds, err = AskSQLAdminCredentials(ui, adminCreds)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := createSQLDatabase(state.logger, adminCreds, creds); err != nil {
+		return fmt.Errorf("failed to create the database: %w", err)
+	}
+
+	return checkSQLCredentials(creds)
+}
+
+// isResuming returns true when the resumed setup left the progress file in the visor home
+func (state *StateMachine) isResuming() bool {
+	return state.Settings.Resume && utils.FileExists(ProgressFilePath(state.Settings.VisorHome))
+}
+
+// removeHome marks the existing home to remove, it is removed after the setup is confirmed
+func (state *StateMachine) removeHome(homePath string) {
+	state.Settings.HomesToRemove = append(state.Settings.HomesToRemove, homePath)
+}
+
+// cleanHome marks the existing home to remove everything inside it for the Force setting, the home directory
+// itself is kept. The confirmation is asked unless the AssumeYes is set or the non-interactive mode is enabled.
+func (state *StateMachine) cleanHome(ui *input.UI, name, homePath string) error {
+	if !utils.IsDir(homePath) {
+		return fmt.Errorf("%s %s is not a directory: remove it manually", name, homePath)
+	}
+
+	if !state.Settings.AssumeYes && !state.Settings.NonInteractive {
+		cleanAnswer, err := uilib.AskCleanExistingDir(ui, homePath, uilib.AnswerNo)
+		if err != nil {
+			return fmt.Errorf("failed to get answer for clean existing %s: %w", name, err)
+		}
+
+		if cleanAnswer == uilib.AnswerNo {
+			return fmt.Errorf("%s exists. You must provide different %s or remove it", name, name)
+		}
+	}
+
+	state.logger.Warnf("Force: everything inside %s %s will be removed", name, homePath)
+	state.Settings.HomesToClean = append(state.Settings.HomesToClean, homePath)
+
+	return nil
+}
+
+// confirmDatabaseWipe asks before the node is set up against the database populated by the previous
+// data-node. Both startup modes require the empty database, so the existing data is wiped on startup or
+// the data-node ends up in the inconsistent state. The non-interactive mode requires the confirm-wipe flag.
+func (state *StateMachine) confirmDatabaseWipe(ui *input.UI) error {
+	populated, err := hasDataNodeData(state.Settings.SQLCredentials)
+	if err != nil {
+		return fmt.Errorf("failed to check existing data-node data: %w", err)
+	}
+	if !populated {
+		return nil
+	}
+
+	warning := fmt.Sprintf(
+		"The %s database already contains the data-node data. The %s mode requires the empty database: existing data is lost",
+		state.Settings.SQLCredentials.DatabaseName,
+		state.Settings.Mode,
 	)
+	if !state.Settings.WipeOnStartup {
+		warning = fmt.Sprintf("%s, and the node fails or ends in the inconsistent state unless you wipe the database manually", warning)
+	}
+
+	if state.Settings.ConfirmWipe {
+		state.logger.Warnf("%s. Wipe confirmed with the confirm-wipe flag", warning)
+
+		return nil
+	}
+
+	if state.Settings.DryRun {
+		state.logger.Warnf("Dry run: %s", warning)
+
+		return nil
+	}
+
+	if state.Settings.NonInteractive {
+		return fmt.Errorf("%s: use the confirm-wipe flag to continue in the non-interactive mode", warning)
+	}
+
+	fmt.Println(warning)
+	answer, err := uilib.AskYesNo(ui, "Do you want to wipe the existing data-node data?", uilib.AnswerNo)
 	if err != nil {
-		return fmt.Errorf("failed to check timescale extension version: %w", err)
+		return fmt.Errorf("failed asking for the database wipe confirmation: %w", err)
 	}
+	if answer == uilib.AnswerNo {
+		return fmt.Errorf("existing data-node data must not be wiped: provide different database or back it up first")
+	}
+	state.Settings.ConfirmWipe = true
 
-	if !strings.HasPrefix(timescaleVersion, "v") {
-		timescaleVersion = fmt.Sprintf("v%s", timescaleVersion)
+	return nil
+}
+
+func checkSQLCredentials(creds types.SQLCredentials) error {
+	db, err := connectSQL(creds)
+	if err != nil {
+		return err
 	}
 
-	if semver.Compare(timescaleVersion, "v2.8.0") != 0 {
-		return fmt.Errorf(
-			"Vega support only timescale v2.8.0. Installed version is %s",
-			timescaleVersion,
-		)
+	ctx, cancel := context.WithTimeout(context.Background(), sqlConnectTimeout(creds))
+	defer cancel()
+	defer db.Close(ctx)
+
+	if err := pingSQL(ctx, db, sqlConnectTimeout(creds)); err != nil {
+		return err
+	}
+
+	return checkTimescaleDBVersion(ctx, db)
+}
+
+// hasTrustedBlock returns true when the statesync trusted block is provided by the operator
+func (settings GenerateSettings) hasTrustedBlock() bool {
+	return settings.TrustHeight != 0 || settings.TrustHash != ""
+}
+
+func (settings GenerateSettings) validateTrustedBlock() error {
+	if !settings.hasTrustedBlock() {
+		return nil
+	}
+
+	if settings.TrustHeight == 0 || settings.TrustHash == "" {
+		return fmt.Errorf("trust height and trust hash must be provided together")
+	}
+
+	if settings.TrustHeight < 0 {
+		return fmt.Errorf("invalid trust height(%d): expected positive block height", settings.TrustHeight)
+	}
+
+	// Tendermint block hash is the hex encoded sha256 checksum
+	if !utils.IsSHA256(settings.TrustHash) {
+		return fmt.Errorf("invalid trust hash(%s): expected 64 hex characters", settings.TrustHash)
+	}
+
+	if settings.Mode != StartFromNetworkHistory {
+		return fmt.Errorf("trusted block is used only by the statesync in the %s mode", StartFromNetworkHistory)
 	}
 
 	return nil
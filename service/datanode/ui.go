@@ -1,15 +1,21 @@
 package datanode
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/url"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/rodaine/table"
 	input "github.com/tcnksm/go-input"
 
+	"github.com/daniel1302/vega-assistant/network"
 	"github.com/daniel1302/vega-assistant/types"
+	"github.com/daniel1302/vega-assistant/uilib"
 	"github.com/daniel1302/vega-assistant/vega"
 )
 
@@ -46,12 +52,16 @@ func SelectStartupMode(ui *input.UI, defaultValue StartupMode) (*StartupMode, er
 	return &result, nil
 }
 
-func AskRetentionPolicy(ui *input.UI) (string, error) {
+func AskRetentionPolicy(ui *input.UI, defaultValue string) (string, error) {
+	if defaultValue == "" {
+		defaultValue = "standard"
+	}
+
 	val, err := ui.Ask(`Retention policy. Possible values: 
 - standard - ~ 1 month of data retention, 
 - forever - full archival node, 
 - lite - ~ 1 day retention for most of the tables`, &input.Options{
-		Default:  "standard",
+		Default:  defaultValue,
 		Required: true,
 		Loop:     true,
 		ValidateFunc: func(s string) error {
@@ -78,9 +88,30 @@ func AskRetentionPolicy(ui *input.UI) (string, error) {
 	return val, err
 }
 
+// SQLRequirements describes the database required by the startup mode. The requirements of all modes are
+// described for the empty mode.
+func SQLRequirements(mode StartupMode) string {
+	switch mode {
+	case StartFromNetworkHistory:
+		return fmt.Sprintf(
+			"PostgreSQL server must be running with the empty database and you MUST install the TimescaleDB %s or newer: the network history is loaded into the TimescaleDB tables",
+			RequiredTimescaleDBVersion,
+		)
+	case StartFromBlock0:
+		return fmt.Sprintf(
+			"PostgreSQL server must be running with the empty database. You should install the TimescaleDB %s or newer: the setup continues without it, but the data-node requires it on the start",
+			RequiredTimescaleDBVersion,
+		)
+	default:
+		return fmt.Sprintf("PostgreSQL server must be running and you MUST install the TimescaleDB %s or newer", RequiredTimescaleDBVersion)
+	}
+}
+
 func AskSQLCredentials(
 	ui *input.UI,
 	defaultValue types.SQLCredentials,
+	askPassword bool,
+	requirements string,
 	checkFunc func(types.SQLCredentials) error,
 ) (*types.SQLCredentials, error) {
 	var (
@@ -90,10 +121,13 @@ func AskSQLCredentials(
 		dbPass string
 		dbName string
 
+		sslMode     string
+		sslRootCert string
+
 		err error
 	)
 
-	fmt.Println("PostgreSQL server must be running and you MUST install the TimescaleDB v2.8.0")
+	fmt.Println(requirements)
 	for {
 		dbHost, err = ui.Ask("PostgreSQL host for the data-node", &input.Options{
 			Default:  defaultValue.Host,
@@ -135,14 +169,17 @@ func AskSQLCredentials(
 			return nil, fmt.Errorf("failed to ask for database user name: %w", err)
 		}
 
-		dbPass, err = ui.Ask("PostgreSQL password for the given username", &input.Options{
-			Default:  defaultValue.Pass,
-			Required: true,
-			Loop:     true,
-		})
+		dbPass = defaultValue.Pass
+		if askPassword {
+			dbPass, err = ui.Ask("PostgreSQL password for the given username", &input.Options{
+				Default:  defaultValue.Pass,
+				Required: true,
+				Loop:     true,
+			})
 
-		if err != nil {
-			return nil, fmt.Errorf("failed to ask for database password: %w", err)
+			if err != nil {
+				return nil, fmt.Errorf("failed to ask for database password: %w", err)
+			}
 		}
 
 		dbName, err = ui.Ask("PostgreSQL database name for the data-node", &input.Options{
@@ -155,36 +192,49 @@ func AskSQLCredentials(
 			return nil, fmt.Errorf("failed to ask for database name: %w", err)
 		}
 
+		sslMode, err = askSSLMode(ui, defaultValue.SSLMode)
+		if err != nil {
+			return nil, fmt.Errorf("failed to ask for ssl mode: %w", err)
+		}
+
+		sslRootCert = ""
+		if isVerifySSLMode(sslMode) {
+			sslRootCert, err = ui.Ask("Path to the SSL root certificate", &input.Options{
+				Default:  defaultValue.SSLRootCert,
+				Required: true,
+				Loop:     true,
+				ValidateFunc: func(s string) error {
+					return ValidateSSLSettings(types.SQLCredentials{SSLMode: sslMode, SSLRootCert: s})
+				},
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to ask for ssl root certificate: %w", err)
+			}
+		}
+
 		if err := checkFunc(types.SQLCredentials{
-			Host:         dbHost,
-			User:         dbUser,
-			Port:         dbPort,
-			Pass:         dbPass,
-			DatabaseName: dbName,
+			Host:           dbHost,
+			User:           dbUser,
+			Port:           dbPort,
+			Pass:           dbPass,
+			DatabaseName:   dbName,
+			SSLMode:        sslMode,
+			SSLRootCert:    sslRootCert,
+			ConnectTimeout: defaultValue.ConnectTimeout,
 		}); err != nil {
-			tryAgain, err := ui.Ask(
+			tryAgain, err := uilib.AskYesNo(
+				ui,
 				fmt.Sprintf(
 					"Cannot connect to the data base with given credentials(%s). Try again? (Yes/No)",
 					err.Error(),
 				),
-				&input.Options{
-					Default:  "Yes",
-					Required: true,
-					Loop:     true,
-					ValidateFunc: func(s string) error {
-						normalizedResponse := strings.ToLower(s)
-						if normalizedResponse != "yes" && normalizedResponse != "no" {
-							return fmt.Errorf("invalid response; got %s, expected Yes or No", s)
-						}
-						return nil
-					},
-				},
+				uilib.AnswerYes,
 			)
 			if err != nil {
 				return nil, fmt.Errorf("failed to ask for try-again: %w", err)
 			}
 
-			if strings.ToLower(tryAgain) == "yes" {
+			if tryAgain == uilib.AnswerYes {
 				continue
 			}
 		}
@@ -193,16 +243,59 @@ func AskSQLCredentials(
 	}
 
 	return &types.SQLCredentials{
-		Host:         dbHost,
-		User:         dbUser,
-		Port:         dbPort,
-		Pass:         dbPass,
-		DatabaseName: dbName,
+		Host:           dbHost,
+		User:           dbUser,
+		Port:           dbPort,
+		Pass:           dbPass,
+		DatabaseName:   dbName,
+		SSLMode:        sslMode,
+		SSLRootCert:    sslRootCert,
+		ConnectTimeout: defaultValue.ConnectTimeout,
 	}, nil
 }
 
-func printSummary(settings GenerateSettings) {
-	fmt.Println("\n Summary:\n")
+func AskSQLAdminCredentials(ui *input.UI, defaultValue types.SQLCredentials) (types.SQLCredentials, error) {
+	adminUser, err := ui.Ask("PostgreSQL admin user name used to create the database", &input.Options{
+		Default:  defaultValue.User,
+		Required: true,
+		Loop:     true,
+	})
+	if err != nil {
+		return types.SQLCredentials{}, fmt.Errorf("failed to ask for admin user name: %w", err)
+	}
+
+	adminPass, err := uilib.AskPassword(ui, "PostgreSQL admin password", defaultValue.Pass)
+	if err != nil {
+		return types.SQLCredentials{}, fmt.Errorf("failed to ask for admin password: %w", err)
+	}
+
+	return types.SQLCredentials{
+		User: adminUser,
+		Pass: adminPass,
+	}, nil
+}
+
+func askSSLMode(ui *input.UI, defaultValue string) (string, error) {
+	if defaultValue == "" {
+		defaultValue = SSLModeDisable
+	}
+
+	return ui.Ask(fmt.Sprintf("PostgreSQL SSL mode. Possible values: %s", strings.Join(AvailableSSLModes(), ", ")), &input.Options{
+		Default:  defaultValue,
+		Required: true,
+		Loop:     true,
+		ValidateFunc: func(s string) error {
+			if !slices.Contains(AvailableSSLModes(), s) {
+				return fmt.Errorf("invalid ssl mode; got %s, expected one of %v", s, AvailableSSLModes())
+			}
+
+			return nil
+		},
+	})
+}
+
+func printSummary(settings GenerateSettings, networkConfig network.NetworkConfig) {
+	fmt.Print("\n Summary:\n\n")
 	headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
 	columnFmt := color.New(color.FgYellow).SprintfFunc()
 
@@ -214,26 +307,180 @@ func printSummary(settings GenerateSettings) {
 		tbl.AddRow("Mode", "Start from Network History")
 	}
 	tbl.AddRow("Retention policy", settings.DataRetention)
+	tbl.AddRow("Node Type", settings.NodeType)
 	tbl.AddRow("Visor Home", settings.VisorHome)
 	tbl.AddRow("Vega Home", settings.VegaHome)
+	if settings.DataNodeHome != "" && settings.DataNodeHome != settings.VegaHome {
+		tbl.AddRow("Data-Node Home", settings.DataNodeHome)
+	}
 	tbl.AddRow("Tendermint Home", settings.TendermintHome)
+	if len(settings.HomesToRemove) > 0 {
+		tbl.AddRow("Homes To Remove", strings.Join(settings.HomesToRemove, ", "))
+	}
+	if len(settings.HomesToClean) > 0 {
+		tbl.AddRow("Homes To Clean", strings.Join(settings.HomesToClean, ", "))
+	}
 	tbl.AddRow("SQL Host", settings.SQLCredentials.Host)
 	tbl.AddRow("SQL Port", settings.SQLCredentials.Port)
 	tbl.AddRow("SQL User", settings.SQLCredentials.User)
-	tbl.AddRow(
-		"SQL Password",
-		fmt.Sprintf(
-			"%c***%c",
-			settings.SQLCredentials.Pass[0],
-			settings.SQLCredentials.Pass[len(settings.SQLCredentials.Pass)-1],
-		),
-	)
+	tbl.AddRow("SQL Password", maskPassword(settings.SQLCredentials.Pass))
 	tbl.AddRow("SQL Database Name", settings.SQLCredentials.DatabaseName)
+	tbl.AddRow("SQL SSL Mode", settings.SQLCredentials.SSLMode)
+	if settings.SQLCredentials.SSLRootCert != "" {
+		tbl.AddRow("SQL SSL Root Certificate", settings.SQLCredentials.SSLRootCert)
+	}
+	tbl.AddRow("Wipe Database On Startup", settings.WipeOnStartup)
+	tbl.AddRow("Network History Timeout", settings.NetworkHistoryTimeout)
+	if segment := settings.NetworkHistorySegment; segment != nil {
+		tbl.AddRow("Network History Segment", fmt.Sprintf("%s(blocks %s-%s)", segment.HistorySegmentId, segment.FromHeight, segment.ToHeight))
+	}
+	tbl.AddRow("Broker Dial Timeout", settings.BrokerDialTimeout)
+	tbl.AddRow("Visor First Connection Retries", fmt.Sprintf(
+		"%d(visor waits up to %s, retries every %s)",
+		settings.VisorFirstConnectionRetries,
+		time.Duration(settings.VisorFirstConnectionRetries)*VisorFirstConnectionRetryDelay,
+		VisorFirstConnectionRetryDelay,
+	))
+	tbl.AddRow("Visor Auto Upgrade", !settings.NoAutoUpgrade)
+	if settings.Owner != "" {
+		tbl.AddRow("Homes Owner", settings.Owner)
+	}
+	tbl.AddRow("Node Ports", settings.formatPorts())
+	if settings.PostSetupHook != "" {
+		tbl.AddRow("Post-Setup Hook", settings.PostSetupHook)
+	}
+	switch {
+	case settings.TrustPeriod > 0:
+		tbl.AddRow("Statesync Trust Period", settings.TrustPeriod)
+	case settings.DeriveTrustPeriod:
+		tbl.AddRow("Statesync Trust Period", "Derived from the chain evidence max age")
+	default:
+		tbl.AddRow("Statesync Trust Period", DefaultTrustPeriod)
+	}
+	if settings.hasTrustedBlock() {
+		tbl.AddRow("Statesync Trusted Block", fmt.Sprintf("%d(%s)", settings.TrustHeight, settings.TrustHash))
+	}
+	tbl.AddRow("Pruning", settings.pruningSummary())
+	tbl.AddRow("Snapshots", settings.snapshotSummary())
+	tbl.AddRow("Target Platform", settings.TargetPlatform())
 	tbl.AddRow("Vega Version", settings.VegaBinaryVersion)
+	tbl.AddRow("Visor Version", settings.VisorBinaryVersion)
 	tbl.AddRow("Vega Chain ID", settings.VegaChainId)
+	tbl.AddRow("Network Repository", networkConfig.Repository)
+	tbl.AddRow("Network Data-nodes", strings.Join(networkConfig.DataNodesRESTUrls, ", "))
+	tbl.AddRow("Tendermint Seeds", len(networkConfig.TendermintSeeds))
+	tbl.AddRow("Tendermint RPC Servers", len(networkConfig.TendermintRPCServers))
+	tbl.AddRow("Bootstrap Peers", len(networkConfig.BootstrapPeers))
+	if settings.DryRun {
+		tbl.AddRow("Dry Run", "Yes - no changes will be applied")
+	}
+
+	tbl.Print()
+	fmt.Println("")
+}
+
+// maskPassword reveals the first and the last character only when the password is long enough
+func maskPassword(pass string) string {
+	const minRevealLength = 3
+
+	passRunes := []rune(pass)
+	if len(passRunes) < minRevealLength {
+		return "***"
+	}
+
+	return fmt.Sprintf("%c***%c", passRunes[0], passRunes[len(passRunes)-1])
+}
+
+// redactPassword replaces the whole password with the placeholder. Unlike maskPassword, it reveals no
+// character, so it is used for the output kept by the scripts, e.g. in the CI logs.
+func redactPassword(pass string) string {
+	if pass == "" {
+		return ""
+	}
+
+	return "***"
+}
+
+// RedactSettings returns copy of the settings without secrets, so they can be printed
+func RedactSettings(settings GenerateSettings) GenerateSettings {
+	settings.SQLCredentials.Pass = redactPassword(settings.SQLCredentials.Pass)
+	settings.SQLAdminPassword = redactPassword(settings.SQLAdminPassword)
+	if databaseURL, err := url.Parse(settings.DatabaseURL); err == nil && settings.DatabaseURL != "" {
+		settings.DatabaseURL = databaseURL.Redacted()
+	} else {
+		settings.DatabaseURL = ""
+	}
+
+	return settings
+}
+
+// PrintJSONSummary prints redacted settings as a single line JSON, so it can be parsed by scripts
+func PrintJSONSummary(settings GenerateSettings) error {
+	result, err := json.Marshal(RedactSettings(settings))
+	if err != nil {
+		return fmt.Errorf("failed to marshal settings: %w", err)
+	}
+	fmt.Println(string(result))
+
+	return nil
+}
+
+// PrintSQLCheckReport prints results of the database checks and returns true when all checks passed
+func PrintSQLCheckReport(results []SQLCheckResult) bool {
+	fmt.Print("\n Database check:\n\n")
+	headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
+	columnFmt := color.New(color.FgYellow).SprintfFunc()
+
+	passed := true
+	tbl := table.New("Check", "Result")
+	tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+	for _, result := range results {
+		if result.Err != nil && result.Warning {
+			tbl.AddRow(result.Name, color.YellowString("WARN: %s", result.Err.Error()))
+			continue
+		}
+
+		if result.Err != nil {
+			passed = false
+			tbl.AddRow(result.Name, color.RedString("FAIL: %s", result.Err.Error()))
+			continue
+		}
+
+		tbl.AddRow(result.Name, color.GreenString("PASS"))
+	}
+
+	tbl.Print()
+	fmt.Println("")
+
+	return passed
+}
+
+// PrintDoctorReport prints the checklist and returns false when any check failed. Warnings do not fail
+// the report.
+func PrintDoctorReport(checks []DoctorCheck) bool {
+	fmt.Print("\n Installation check:\n\n")
+	headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
+	columnFmt := color.New(color.FgYellow).SprintfFunc()
+
+	passed := true
+	tbl := table.New("Check", "Result", "Details")
+	tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+	for _, check := range checks {
+		switch check.Status {
+		case DoctorFail:
+			passed = false
+			tbl.AddRow(check.Name, color.RedString(string(check.Status)), check.Details)
+		case DoctorWarn:
+			tbl.AddRow(check.Name, color.YellowString(string(check.Status)), check.Details)
+		default:
+			tbl.AddRow(check.Name, color.GreenString(string(check.Status)), check.Details)
+		}
+	}
 
 	tbl.Print()
 	fmt.Println("")
+
+	return passed
 }
 
 func PrintInstructions(visorHome string) {
@@ -252,3 +499,18 @@ func PrintInstructions(visorHome string) {
 
     You must call the above command as a root user otherwise you will get instructions for manual systemd setup.`, visorHome, visorHome, visorHome)
 }
+
+func PrintValidatorInstructions(visorHome string) {
+	fmt.Printf(`
+    The validator node is initialized. You can now start it with the following command:
+
+      %s/visor run --home %s
+
+    The node replays the chain from block 0. Announce your node to the network only after it catches up with the network head.
+
+    You can also setup systemd service if you running your node on LINUX with the following command:
+
+      sudo vega-assistant setup systemd --visor-home %s
+
+    You must call the above command as a root user otherwise you will get instructions for manual systemd setup.`, visorHome, visorHome, visorHome)
+}
@@ -0,0 +1,97 @@
+package datanode
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	input "github.com/tcnksm/go-input"
+
+	"github.com/daniel1302/vega-assistant/types"
+)
+
+func TestMaskPassword(t *testing.T) {
+	tests := []struct {
+		name     string
+		pass     string
+		expected string
+	}{
+		{name: "empty", pass: "", expected: "***"},
+		{name: "1 character", pass: "a", expected: "***"},
+		{name: "2 characters", pass: "ab", expected: "***"},
+		{name: "3 characters", pass: "abc", expected: "a***c"},
+		{name: "10 characters", pass: "abcdefghij", expected: "a***j"},
+		{name: "multibyte characters", pass: "ąbcdę", expected: "ą***ę"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := maskPassword(tt.pass); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestAskSQLCredentialsKeepsConnectTimeout(t *testing.T) {
+	defaultValue := types.SQLCredentials{
+		Host:           "localhost",
+		Port:           5432,
+		User:           "vega",
+		Pass:           "vega",
+		DatabaseName:   "vega",
+		SSLMode:        SSLModeDisable,
+		ConnectTimeout: 42 * time.Second,
+	}
+	ui := &input.UI{Writer: io.Discard, Reader: strings.NewReader(strings.Repeat("\n", 5))}
+
+	var checked types.SQLCredentials
+	creds, err := AskSQLCredentials(ui, defaultValue, false, "", func(c types.SQLCredentials) error {
+		checked = c
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if checked.ConnectTimeout != defaultValue.ConnectTimeout {
+		t.Errorf("expected checkFunc to get connect timeout %s, got %s", defaultValue.ConnectTimeout, checked.ConnectTimeout)
+	}
+	if creds.ConnectTimeout != defaultValue.ConnectTimeout {
+		t.Errorf("expected returned connect timeout %s, got %s", defaultValue.ConnectTimeout, creds.ConnectTimeout)
+	}
+}
+
+func TestRedactSettingsRevealsNoPasswordCharacter(t *testing.T) {
+	const (
+		sqlPass   = "Zq7xK"
+		adminPass = "Wj4vR"
+	)
+	settings := DefaultGenerateSettings()
+	settings.SQLCredentials.Pass = sqlPass
+	settings.SQLAdminPassword = adminPass
+
+	result, err := json.Marshal(RedactSettings(*settings))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var redacted GenerateSettings
+	if err := json.Unmarshal(result, &redacted); err != nil {
+		t.Fatalf("failed to unmarshal redacted settings: %s", err)
+	}
+
+	for _, tt := range []struct{ pass, redacted string }{
+		{pass: sqlPass, redacted: redacted.SQLCredentials.Pass},
+		{pass: adminPass, redacted: redacted.SQLAdminPassword},
+	} {
+		if strings.ContainsAny(tt.redacted, tt.pass) {
+			t.Errorf("redacted password %q reveals characters of %q", tt.redacted, tt.pass)
+		}
+		if strings.Contains(string(result), tt.pass) {
+			t.Errorf("json output contains the %q password:\n%s", tt.pass, result)
+		}
+	}
+}
@@ -0,0 +1,311 @@
+package datanode
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"go.uber.org/zap"
+
+	"github.com/daniel1302/vega-assistant/network"
+	"github.com/daniel1302/vega-assistant/utils"
+	"github.com/daniel1302/vega-assistant/vegaapi"
+	"github.com/daniel1302/vega-assistant/vegacmd"
+)
+
+// ValidatorSettings are the validator specific settings. The node wallets are generated, unless the
+// existing wallet is given.
+type ValidatorSettings struct {
+	NodeWalletPassphraseFile     string `toml:"nodewallet-passphrase-file" json:"nodewallet-passphrase-file"`
+	WalletPassphraseFile         string `toml:"wallet-passphrase-file" json:"wallet-passphrase-file"`
+	VegaWalletPath               string `toml:"vega-wallet" json:"vega-wallet"`
+	VegaWalletPassphraseFile     string `toml:"vega-wallet-passphrase-file" json:"vega-wallet-passphrase-file"`
+	EthereumClefAddress          string `toml:"ethereum-clef-address" json:"ethereum-clef-address"`
+	EthereumWalletPath           string `toml:"ethereum-wallet" json:"ethereum-wallet"`
+	EthereumWalletPassphraseFile string `toml:"ethereum-wallet-passphrase-file" json:"ethereum-wallet-passphrase-file"`
+	EthereumRPCEndpoint          string `toml:"ethereum-rpc-endpoint" json:"ethereum-rpc-endpoint"`
+	TendermintPrivValidatorKey   string `toml:"tendermint-priv-validator-key" json:"tendermint-priv-validator-key"`
+}
+
+func (settings ValidatorSettings) Validate() error {
+	if settings.NodeWalletPassphraseFile == "" {
+		return fmt.Errorf("the node wallet passphrase file is required")
+	}
+
+	if settings.EthereumRPCEndpoint == "" {
+		return fmt.Errorf("the ethereum rpc endpoint is required")
+	}
+
+	if settings.EthereumClefAddress != "" && settings.EthereumWalletPath != "" {
+		return fmt.Errorf("the ethereum clef address and the ethereum wallet are mutually exclusive")
+	}
+
+	files := []struct {
+		name string
+		path string
+	}{
+		{name: "node wallet passphrase file", path: settings.NodeWalletPassphraseFile},
+		{name: "wallet passphrase file", path: settings.WalletPassphraseFile},
+		{name: "vega wallet", path: settings.VegaWalletPath},
+		{name: "vega wallet passphrase file", path: settings.VegaWalletPassphraseFile},
+		{name: "ethereum wallet", path: settings.EthereumWalletPath},
+		{name: "ethereum wallet passphrase file", path: settings.EthereumWalletPassphraseFile},
+		{name: "tendermint priv validator key", path: settings.TendermintPrivValidatorKey},
+	}
+	for _, file := range files {
+		if file.path != "" && !utils.FileExists(file.path) {
+			return fmt.Errorf("the %s %s does not exist", file.name, file.path)
+		}
+	}
+
+	if settings.VegaWalletPath != "" && settings.VegaWalletPassphraseFile == "" {
+		return fmt.Errorf("the vega wallet passphrase file is required to import the vega wallet")
+	}
+
+	if settings.EthereumWalletPath != "" && settings.EthereumWalletPassphraseFile == "" {
+		return fmt.Errorf("the ethereum wallet passphrase file is required to import the ethereum wallet")
+	}
+
+	return nil
+}
+
+// walletPassphraseFile returns passphrase file for the generated wallets. When not set, the node
+// wallet passphrase is used.
+func (settings ValidatorSettings) walletPassphraseFile() string {
+	if settings.WalletPassphraseFile != "" {
+		return settings.WalletPassphraseFile
+	}
+
+	return settings.NodeWalletPassphraseFile
+}
+
+// ValidatorGenerator sets up the validator node. It shares binaries, visor home and genesis steps
+// with the DataNodeGenerator, but the visor does not run the data-node.
+type ValidatorGenerator struct {
+	*nodeGenerator
+
+	validatorSettings ValidatorSettings
+}
+
+func NewValidatorGenerator(
+	vegaApi *vegaapi.NetworkAPI,
+	settings GenerateSettings,
+	validatorSettings ValidatorSettings,
+	networkConfig network.NetworkConfig,
+) (*ValidatorGenerator, error) {
+	if err := settings.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid settings: %w", err)
+	}
+
+	if err := validatorSettings.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid validator settings: %w", err)
+	}
+
+	if err := validateHomes(settings); err != nil {
+		return nil, fmt.Errorf("invalid homes: %w", err)
+	}
+
+	for _, home := range []string{settings.VisorHome, settings.VegaHome, settings.TendermintHome} {
+		if utils.FileExists(home) {
+			return nil, fmt.Errorf("home %s already exists: provide different home or remove it manually", home)
+		}
+	}
+
+	return &ValidatorGenerator{
+		nodeGenerator: &nodeGenerator{
+			vegaApi:       vegaApi,
+			userSettings:  settings,
+			networkConfig: networkConfig,
+		},
+		validatorSettings: validatorSettings,
+	}, nil
+}
+
+func (gen *ValidatorGenerator) Run(ctx context.Context, logger *zap.SugaredLogger) error {
+	return gen.run(ctx, logger, nodeSetup{
+		initNode:      gen.initNode,
+		updateConfigs: gen.updateConfigs,
+	})
+}
+
+func (gen *ValidatorGenerator) initNode(
+	ctx context.Context,
+	logger *zap.SugaredLogger,
+	visorBinary, vegaBinary string,
+) error {
+	if gen.userSettings.DryRun {
+		logger.Infof("Dry run: would initialize vegavisor in the %s", gen.userSettings.VisorHome)
+		logger.Infof("Dry run: would initialize tendermint in the %s", gen.userSettings.TendermintHome)
+		logger.Infof("Dry run: would initialize vega validator in the %s", gen.userSettings.VegaHome)
+		logger.Infof("Dry run: would set up node wallets in the %s", gen.userSettings.VegaHome)
+
+		return nil
+	}
+
+	logger.Infof("Initializing vegavisor in the %s", gen.userSettings.VisorHome)
+	if err := vegacmd.InitVisor(ctx, logger, visorBinary, gen.userSettings.VisorHome); err != nil {
+		return fmt.Errorf("failed to initialize vegavisor in %s: %w", gen.userSettings.VisorHome, err)
+	}
+	logger.Info("Visor successfully initialized")
+
+	logger.Infof("Initializing tendermint in the %s", gen.userSettings.TendermintHome)
+	if err := vegacmd.InitTendermint(ctx, logger, vegaBinary, gen.userSettings.TendermintHome); err != nil {
+		return fmt.Errorf("failed to initialize tendermint in %s: %w", gen.userSettings.TendermintHome, err)
+	}
+	logger.Info("Tendermint successfully initialized")
+
+	if keyPath := gen.validatorSettings.TendermintPrivValidatorKey; keyPath != "" {
+		keyDestination := filepath.Join(gen.userSettings.TendermintHome, vegacmd.TendermintPrivValidatorKeyPath)
+		logger.Infof("Copying tendermint validator key from %s to %s", keyPath, keyDestination)
+		if err := utils.CopyFile(keyPath, keyDestination); err != nil {
+			return fmt.Errorf("failed to copy tendermint validator key: %w", err)
+		}
+	}
+
+	logger.Infof("Initializing vega validator in the %s", gen.userSettings.VegaHome)
+	if err := vegacmd.InitVegaValidator(
+		ctx,
+		vegaBinary,
+		gen.userSettings.VegaHome,
+		gen.validatorSettings.NodeWalletPassphraseFile,
+	); err != nil {
+		return fmt.Errorf("failed to initialize vega in %s: %w", gen.userSettings.VegaHome, err)
+	}
+	logger.Info("Vega successfully initialized")
+
+	if err := gen.setupNodeWallets(ctx, logger, vegaBinary); err != nil {
+		return fmt.Errorf("failed to set up node wallets: %w", err)
+	}
+
+	return nil
+}
+
+// setupNodeWallets imports or generates the vega and the ethereum wallets, and registers the
+// tendermint validator key in the node wallets
+func (gen *ValidatorGenerator) setupNodeWallets(ctx context.Context, logger *zap.SugaredLogger, vegaBinary string) error {
+	vegaHome := gen.userSettings.VegaHome
+	passphraseFile := gen.validatorSettings.NodeWalletPassphraseFile
+
+	if gen.validatorSettings.VegaWalletPath != "" {
+		logger.Infof("Importing vega wallet %s", gen.validatorSettings.VegaWalletPath)
+		if err := vegacmd.ImportNodeWallet(
+			ctx,
+			vegaBinary,
+			vegaHome,
+			passphraseFile,
+			vegacmd.NodeWalletChainVega,
+			gen.validatorSettings.VegaWalletPath,
+			gen.validatorSettings.VegaWalletPassphraseFile,
+		); err != nil {
+			return err
+		}
+	} else {
+		logger.Info("Generating vega wallet")
+		if err := vegacmd.GenerateNodeWallet(
+			ctx,
+			vegaBinary,
+			vegaHome,
+			passphraseFile,
+			vegacmd.NodeWalletChainVega,
+			gen.validatorSettings.walletPassphraseFile(),
+		); err != nil {
+			return err
+		}
+	}
+	logger.Info("Vega wallet added to the node wallets")
+
+	switch {
+	case gen.validatorSettings.EthereumClefAddress != "":
+		logger.Infof("Importing ethereum clef wallet %s", gen.validatorSettings.EthereumClefAddress)
+		if err := vegacmd.ImportEthereumClefWallet(
+			ctx,
+			vegaBinary,
+			vegaHome,
+			passphraseFile,
+			gen.validatorSettings.EthereumClefAddress,
+		); err != nil {
+			return err
+		}
+	case gen.validatorSettings.EthereumWalletPath != "":
+		logger.Infof("Importing ethereum wallet %s", gen.validatorSettings.EthereumWalletPath)
+		if err := vegacmd.ImportNodeWallet(
+			ctx,
+			vegaBinary,
+			vegaHome,
+			passphraseFile,
+			vegacmd.NodeWalletChainEthereum,
+			gen.validatorSettings.EthereumWalletPath,
+			gen.validatorSettings.EthereumWalletPassphraseFile,
+		); err != nil {
+			return err
+		}
+	default:
+		logger.Warn("Generating ethereum wallet: use the clef signer for the production validator")
+		if err := vegacmd.GenerateNodeWallet(
+			ctx,
+			vegaBinary,
+			vegaHome,
+			passphraseFile,
+			vegacmd.NodeWalletChainEthereum,
+			gen.validatorSettings.walletPassphraseFile(),
+		); err != nil {
+			return err
+		}
+	}
+	logger.Info("Ethereum wallet added to the node wallets")
+
+	logger.Infof("Importing tendermint validator key from %s", gen.userSettings.TendermintHome)
+	if err := vegacmd.ImportTendermintNodeWallet(
+		ctx,
+		vegaBinary,
+		vegaHome,
+		passphraseFile,
+		gen.userSettings.TendermintHome,
+	); err != nil {
+		return err
+	}
+	logger.Info("Tendermint validator key added to the node wallets")
+
+	return nil
+}
+
+func (gen *ValidatorGenerator) updateConfigs(_ context.Context, logger *zap.SugaredLogger) error {
+	vegaConfig := map[string]interface{}{
+		"Ethereum.RPCEndpoint": gen.validatorSettings.EthereumRPCEndpoint,
+	}
+	tendermintConfig := gen.tendermintPeersConfig()
+	vegavisorConfig := gen.vegavisorConfig()
+
+	mergeConfig(vegaConfig, gen.userSettings.snapshotConfig())
+	vegaPruningConfig, tendermintPruningConfig := gen.userSettings.pruningConfig()
+	mergeConfig(vegaConfig, vegaPruningConfig)
+	mergeConfig(tendermintConfig, tendermintPruningConfig)
+	mergeConfig(tendermintConfig, gen.userSettings.tendermintPortsConfig())
+
+	vegaConfigPath := filepath.Join(gen.userSettings.VegaHome, vegacmd.CoreConfigPath)
+	logger.Infof("Updating vega-core config(%s). New parameters: %v", vegaConfigPath, utils.RedactSecrets(vegaConfig))
+
+	tendermintConfigPath := filepath.Join(gen.userSettings.TendermintHome, vegacmd.TenderminConfigPath)
+	logger.Infof(
+		"Updating tendermint config(%s). New parameters: %v",
+		tendermintConfigPath,
+		utils.RedactSecrets(tendermintConfig),
+	)
+
+	vegavisorConfigPath := filepath.Join(gen.userSettings.VisorHome, vegacmd.VegavisorConfigPath)
+	logger.Infof(
+		"Updating vegavisor config(%s). New parameters: %v",
+		vegavisorConfigPath,
+		utils.RedactSecrets(vegavisorConfig),
+	)
+
+	if err := gen.updateConfigFiles(logger, []configUpdate{
+		{name: "vega-core", path: vegaConfigPath, values: vegaConfig},
+		{name: "tendermint", path: tendermintConfigPath, values: tendermintConfig},
+		{name: "vegavisor", path: vegavisorConfigPath, values: vegavisorConfig},
+	}); err != nil {
+		return err
+	}
+
+	return gen.applyConfigOverrides(logger)
+}
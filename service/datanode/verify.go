@@ -0,0 +1,115 @@
+package datanode
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml"
+	"go.uber.org/zap"
+
+	"github.com/daniel1302/vega-assistant/network"
+	"github.com/daniel1302/vega-assistant/utils"
+	"github.com/daniel1302/vega-assistant/vegacmd"
+)
+
+const (
+	DefaultVerifyStartTimeout = 10 * time.Minute
+
+	verifyStartPollInterval  = 5 * time.Second
+	verifyStartStopTimeout   = 30 * time.Second
+	defaultTendermintRPCAddr = "127.0.0.1:26657"
+)
+
+// VerifyStart runs the node with the vegavisor in the background and waits until the local tendermint RPC
+// reports the increasing block height. The node is stopped afterwards. The error contains the tail of the
+// vegavisor stderr, so the operator can see why the node did not start.
+func VerifyStart(logger *zap.SugaredLogger, visorHome, tendermintHome string, timeout time.Duration) error {
+	rpcAddr, err := localTendermintRPCAddr(tendermintHome)
+	if err != nil {
+		return fmt.Errorf("failed to get the local tendermint rpc address: %w", err)
+	}
+
+	visorBinary := filepath.Join(visorHome, "visor")
+	logger.Infof("Starting the node with %s run --home %s to verify the setup", visorBinary, visorHome)
+	process, err := utils.StartBinary(visorBinary, []string{"run", "--home", visorHome})
+	if err != nil {
+		return fmt.Errorf("failed to start the node: %w", err)
+	}
+	defer func() {
+		logger.Info("Stopping the node")
+		if err := process.Stop(verifyStartStopTimeout); err != nil {
+			logger.Errorf("Failed to stop the node: %s", err.Error())
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(verifyStartPollInterval)
+	defer ticker.Stop()
+
+	var firstHeight uint64
+	for {
+		select {
+		case <-process.Done():
+			return fmt.Errorf("node exited before producing blocks(%v), stderr: %s", process.Err(), process.StdErr())
+		case <-ctx.Done():
+			return fmt.Errorf(
+				"tendermint rpc(%s) did not report increasing block height within %s, stderr: %s",
+				rpcAddr,
+				timeout,
+				process.StdErr(),
+			)
+		case <-ticker.C:
+		}
+
+		height, err := network.LatestBlockHeight(ctx, rpcAddr)
+		if err != nil {
+			logger.Debugf("Tendermint rpc is not ready yet: %s", err.Error())
+			continue
+		}
+
+		if firstHeight == 0 {
+			firstHeight = height
+			logger.Infof("Tendermint rpc responded with block height %d, waiting for the next blocks", height)
+			continue
+		}
+
+		if height > firstHeight {
+			logger.Infof("Node started correctly: block height increased from %d to %d", firstHeight, height)
+
+			return nil
+		}
+	}
+}
+
+// localTendermintRPCAddr returns the address the tendermint RPC listens on. The unspecified listen address
+// is replaced with the loopback address.
+func localTendermintRPCAddr(tendermintHome string) (string, error) {
+	configPath := filepath.Join(tendermintHome, vegacmd.TenderminConfigPath)
+	config, err := toml.LoadFile(configPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to load tendermint config(%s): %w", configPath, err)
+	}
+
+	laddr, ok := config.Get("rpc.laddr").(string)
+	if !ok || laddr == "" {
+		return defaultTendermintRPCAddr, nil
+	}
+
+	laddr = strings.TrimPrefix(laddr, "tcp://")
+	host, port, err := net.SplitHostPort(laddr)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse tendermint rpc.laddr(%s): %w", laddr, err)
+	}
+
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		host = "127.0.0.1"
+	}
+
+	return net.JoinHostPort(host, port), nil
+}
@@ -0,0 +1,57 @@
+package datanode
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"go.uber.org/zap"
+	"golang.org/x/mod/semver"
+
+	"github.com/daniel1302/vega-assistant/utils"
+	"github.com/daniel1302/vega-assistant/vegacmd"
+)
+
+// genesisRunConfigVersion is the visor run config directory used when the node starts from block 0
+const genesisRunConfigVersion = "genesis"
+
+// RegenerateVisorRunConfig templates the run-config.toml for the given version again. Existing file is
+// backed up before it is overwritten. The data-node process is added only when dataNodeHome is not empty.
+func RegenerateVisorRunConfig(
+	logger *zap.SugaredLogger,
+	visorHome, version, vegaHome, tendermintHome, dataNodeHome string,
+) error {
+	if version != genesisRunConfigVersion && !semver.IsValid(version) {
+		return fmt.Errorf("invalid version(%s): expected %s or the semver version, e.g. v0.73.4", version, genesisRunConfigVersion)
+	}
+
+	if !utils.IsDir(visorHome) {
+		return fmt.Errorf("visor home %s does not exist", visorHome)
+	}
+
+	runConfigContent, err := vegacmd.TemplateVisorRunConfig(version, vegaHome, tendermintHome, dataNodeHome)
+	if err != nil {
+		return fmt.Errorf("failed to generate run-config.toml from template: %w", err)
+	}
+
+	runConfigDirPath := filepath.Join(visorHome, version)
+	if err := os.MkdirAll(runConfigDirPath, utils.DirMode); err != nil {
+		return fmt.Errorf("failed to make directory: %w", err)
+	}
+
+	runConfigPath := filepath.Join(runConfigDirPath, "run-config.toml")
+	if utils.FileExists(runConfigPath) {
+		backupPath, err := utils.BackupConfig(runConfigPath)
+		if err != nil {
+			return err
+		}
+		logger.Infof("The run-config.toml file backed up to %s", backupPath)
+	}
+
+	if err := os.WriteFile(runConfigPath, []byte(runConfigContent), utils.ConfigFileMode); err != nil {
+		return fmt.Errorf("failed to write run-config.toml in %s: %w", runConfigPath, err)
+	}
+	logger.Infof("The run-config.toml file saved in %s", runConfigPath)
+
+	return nil
+}
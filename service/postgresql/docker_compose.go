@@ -8,6 +8,8 @@ import (
 	"path/filepath"
 
 	"go.uber.org/zap"
+
+	"github.com/daniel1302/vega-assistant/utils"
 )
 
 const postgresqlTemplate = `version: '3.1'
@@ -62,7 +64,7 @@ func PrepareDockerComposeFile(logger *zap.SugaredLogger, settings GeneratorSetti
 	logger.Info("Content for docker-compose.yaml generated")
 
 	logger.Infof("Creating home for docker-compose.yaml(%s)", settings.Home)
-	if err := os.MkdirAll(settings.Home, os.ModePerm); err != nil {
+	if err := os.MkdirAll(settings.Home, utils.DirMode); err != nil {
 		return fmt.Errorf(
 			"failed to create home dir for postgresql docker-compose(%s): %w",
 			settings.Home,
@@ -74,7 +76,7 @@ func PrepareDockerComposeFile(logger *zap.SugaredLogger, settings GeneratorSetti
 	dockerComposeFilePath := filepath.Join(settings.Home, "docker-compose.yaml")
 	logger.Infof("Writing docker-compose file to %s", dockerComposeFilePath)
 
-	if err := os.WriteFile(dockerComposeFilePath, []byte(composerContent), os.ModePerm); err != nil {
+	if err := os.WriteFile(dockerComposeFilePath, []byte(composerContent), utils.ConfigFileMode); err != nil {
 		return fmt.Errorf(
 			"failed to write docker compose file to %s: %w",
 			dockerComposeFilePath,
@@ -107,6 +109,4 @@ func templatePostgresqlDockerCompose(
 	}
 
 	return buff.String(), nil
-
-	return "", nil
 }
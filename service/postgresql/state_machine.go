@@ -7,6 +7,7 @@ import (
 	"regexp"
 
 	"github.com/tcnksm/go-input"
+	"go.uber.org/zap"
 
 	"github.com/daniel1302/vega-assistant/uilib"
 	"github.com/daniel1302/vega-assistant/utils"
@@ -30,11 +31,15 @@ type GeneratorSettings struct {
 	PostgresqlPassword string
 	PostgresqlDatabase string
 	PostgresqlPort     int
+	// Force keeps the existing home and removes everything inside it
+	Force bool
 }
 
 type StateMachine struct {
 	Settings     GeneratorSettings
 	CurrentState State
+
+	logger *zap.SugaredLogger
 }
 
 func DefaultGeneratorSettings() GeneratorSettings {
@@ -47,10 +52,14 @@ func DefaultGeneratorSettings() GeneratorSettings {
 	}
 }
 
-func NewStateMachine() StateMachine {
+func NewStateMachine(logger *zap.SugaredLogger, force bool) StateMachine {
+	settings := DefaultGeneratorSettings()
+	settings.Force = force
+
 	return StateMachine{
 		CurrentState: StateGetHome,
-		Settings:     DefaultGeneratorSettings(),
+		Settings:     settings,
+		logger:       logger,
 	}
 }
 
@@ -72,6 +81,25 @@ STATE_RUN:
 			}
 
 		case StateExistingHome:
+			if state.Settings.Force && utils.IsDir(state.Settings.Home) {
+				cleanAnswer, err := uilib.AskCleanExistingDir(ui, state.Settings.Home, uilib.AnswerNo)
+				if err != nil {
+					return fmt.Errorf("failed to get answer for clean existing home: %w", err)
+				}
+
+				if cleanAnswer == uilib.AnswerNo {
+					return fmt.Errorf("the home dir exists. You must provide different home or remove it")
+				}
+
+				state.logger.Warnf("Force: removing everything inside home %s", state.Settings.Home)
+				if err := utils.RemoveDirContents(state.logger, state.Settings.Home); err != nil {
+					return fmt.Errorf("failed to clean home: %w", err)
+				}
+
+				state.CurrentState = StateGetPostgresqlUsername
+				break
+			}
+
 			removeAnswer, err := uilib.AskRemoveExistingFile(ui, state.Settings.Home, uilib.AnswerYes)
 			if err != nil {
 				return fmt.Errorf("failed to get answer for remove existing home: %w", err)
@@ -144,6 +172,7 @@ func validatePostgreSQLCredentialsString(s string) error {
 	if !strRegex.MatchString(s) {
 		return fmt.Errorf(
 			"string '%s' must contains ony digits, characters and the following chars: _.-",
+			s,
 		)
 	}
 	return nil
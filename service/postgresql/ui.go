@@ -18,7 +18,7 @@ func PrintInstructions(homePath string) {
 }
 
 func printSummary(settings GeneratorSettings) {
-	fmt.Println("\n Summary:\n")
+	fmt.Print("\n Summary:\n\n")
 	headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
 	columnFmt := color.New(color.FgYellow).SprintfFunc()
 
@@ -42,26 +42,29 @@ func UpdateConfig(logger *zap.SugaredLogger, vegaHome, tendermintHome string) er
 		dataNodeConfigPath,
 		dataNodeConfig,
 	)
-	if err := utils.UpdateConfig(dataNodeConfigPath, "toml", dataNodeConfig); err != nil {
+	changes, err := utils.UpdateConfig(dataNodeConfigPath, "toml", dataNodeConfig)
+	if err != nil {
 		return fmt.Errorf("failed to update data node config(%s): %w", dataNodeConfigPath, err)
 	}
-	logger.Info("Data node config updated")
+	logger.Infof("Data node config updated:\n%s", utils.FormatConfigChanges(changes))
 
 	logger.Infof("Updating core config(%s). New values: %v", coreConfigPath, coreConfig)
-	if err := utils.UpdateConfig(coreConfigPath, "toml", coreConfig); err != nil {
-		return fmt.Errorf("failed to update core config(%s): %w", coreConfigPath)
+	changes, err = utils.UpdateConfig(coreConfigPath, "toml", coreConfig)
+	if err != nil {
+		return fmt.Errorf("failed to update core config(%s): %w", coreConfigPath, err)
 	}
-	logger.Info("Core config updated")
+	logger.Infof("Core config updated:\n%s", utils.FormatConfigChanges(changes))
 
 	logger.Infof(
 		"Updating tendermint config(%s). New values: %v",
 		tendermintConfigPath,
 		dataNodeConfig,
 	)
-	if err := utils.UpdateConfig(tendermintConfigPath, "toml", tendermintConfig); err != nil {
+	changes, err = utils.UpdateConfig(tendermintConfigPath, "toml", tendermintConfig)
+	if err != nil {
 		return fmt.Errorf("failed to update tendermitn config(%s): %w", tendermintConfigPath, err)
 	}
-	logger.Info("Tendermint config updated")
+	logger.Infof("Tendermint config updated:\n%s", utils.FormatConfigChanges(changes))
 
 	return nil
 }
@@ -60,7 +60,7 @@ func PrepareSystemd(logger *zap.SugaredLogger, visorHome string) error {
 	}
 
 	logger.Infof("Updating content of the service file in %s", serviceFilePath)
-	if err := os.WriteFile(serviceFilePath, []byte(systemdServiceContent), os.ModePerm); err != nil {
+	if err := os.WriteFile(serviceFilePath, []byte(systemdServiceContent), utils.ConfigFileMode); err != nil {
 		return fmt.Errorf("failed to update %s file: %w", serviceFilePath, err)
 	}
 
@@ -9,3 +9,59 @@ var InputError = errors.New("input error")
 func NewInputError(err error) error {
 	return errors.Join(InputError, err)
 }
+
+// setupError wraps the cause of the failed setup stage. Stage is recognized with the errors.As and the
+// stage specific type, e.g. *DownloadError.
+type setupError struct {
+	Err error
+}
+
+func (e setupError) Error() string {
+	return e.Err.Error()
+}
+
+func (e setupError) Unwrap() error {
+	return e.Err
+}
+
+// DownloadError is returned when the binaries cannot be downloaded or prepared
+type DownloadError struct{ setupError }
+
+// InitError is returned when the node homes cannot be initialized
+type InitError struct{ setupError }
+
+// ConfigError is returned when the node config files cannot be updated
+type ConfigError struct{ setupError }
+
+// GenesisError is returned when the genesis cannot be downloaded or verified
+type GenesisError struct{ setupError }
+
+// DBError is returned when the database cannot be used by the data-node
+type DBError struct{ setupError }
+
+// HookError is returned when the post-setup hook fails. The node is already set up then.
+type HookError struct{ setupError }
+
+func NewDownloadError(err error) error {
+	return &DownloadError{setupError{Err: err}}
+}
+
+func NewInitError(err error) error {
+	return &InitError{setupError{Err: err}}
+}
+
+func NewConfigError(err error) error {
+	return &ConfigError{setupError{Err: err}}
+}
+
+func NewGenesisError(err error) error {
+	return &GenesisError{setupError{Err: err}}
+}
+
+func NewDBError(err error) error {
+	return &DBError{setupError{Err: err}}
+}
+
+func NewHookError(err error) error {
+	return &HookError{setupError{Err: err}}
+}
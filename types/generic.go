@@ -1,9 +1,15 @@
 package types
 
+import "time"
+
 type SQLCredentials struct {
-	Host         string `toml:"host"`
-	User         string `toml:"user"`
-	Port         int    `toml:"port"`
-	Pass         string `toml:"pass"`
-	DatabaseName string `toml:"db-name"`
+	Host         string `toml:"host" json:"host"`
+	User         string `toml:"user" json:"user"`
+	Port         int    `toml:"port" json:"port"`
+	Pass         string `toml:"pass" json:"pass"`
+	DatabaseName string `toml:"db-name" json:"db-name"`
+	SSLMode      string `toml:"ssl-mode" json:"ssl-mode"`
+	SSLRootCert  string `toml:"ssl-root-cert" json:"ssl-root-cert"`
+
+	ConnectTimeout time.Duration `toml:"connect-timeout" json:"connect-timeout"`
 }
@@ -46,3 +46,14 @@ type NetworkHistorySegment struct {
 type NetworkHistorySegments struct {
 	Segments []NetworkHistorySegment `json:"segments"`
 }
+
+// TendermintConsensusParams is the response of the tendermint `/consensus_params` RPC endpoint
+type TendermintConsensusParams struct {
+	Result struct {
+		ConsensusParams struct {
+			Evidence struct {
+				MaxAgeDuration string `json:"max_age_duration"`
+			} `json:"evidence"`
+		} `json:"consensus_params"`
+	} `json:"result"`
+}
@@ -2,6 +2,7 @@ package uilib
 
 import (
 	"fmt"
+	"os"
 	"strconv"
 	"strings"
 
@@ -45,6 +46,18 @@ func AskRemoveExistingFile(
 	)
 }
 
+func AskCleanExistingDir(
+	ui *input.UI,
+	dirPath string,
+	defaultAnswer YesNoAnswer,
+) (YesNoAnswer, error) {
+	return AskYesNo(
+		ui,
+		fmt.Sprintf("Directory %s exists. Do you want to remove everything inside it?", dirPath),
+		defaultAnswer,
+	)
+}
+
 func AskString(
 	ui *input.UI,
 	question string,
@@ -89,6 +102,18 @@ func AskInt(ui *input.UI, question string, defaultAnswer int) (int, error) {
 	return answerInt, nil
 }
 
+// parseYesNo accepts the y, yes, n and no answers in any letter case
+func parseYesNo(answer string) (YesNoAnswer, bool) {
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return AnswerYes, true
+	case "n", "no":
+		return AnswerNo, true
+	default:
+		return "", false
+	}
+}
+
 func AskYesNo(ui *input.UI, question string, defaultAnswer YesNoAnswer) (YesNoAnswer, error) {
 	answer, err := ui.Ask(question,
 		&input.Options{
@@ -96,9 +121,8 @@ func AskYesNo(ui *input.UI, question string, defaultAnswer YesNoAnswer) (YesNoAn
 			Required: true,
 			Loop:     true,
 			ValidateFunc: func(s string) error {
-				normalizedResponse := strings.ToLower(s)
-				if normalizedResponse != "yes" && normalizedResponse != "no" {
-					return fmt.Errorf("invalid response; got %s, expected Yes or No", s)
+				if _, ok := parseYesNo(s); !ok {
+					return fmt.Errorf("invalid response; got %s, expected Yes(y) or No(n)", s)
 				}
 				return nil
 			},
@@ -108,9 +132,36 @@ func AskYesNo(ui *input.UI, question string, defaultAnswer YesNoAnswer) (YesNoAn
 		return defaultAnswer, fmt.Errorf("failed to ask for yes/no: %w", err)
 	}
 
-	if strings.ToLower(answer) == "yes" {
-		return AnswerYes, nil
+	parsedAnswer, _ := parseYesNo(answer)
+
+	return parsedAnswer, nil
+}
+
+// fileReader is implemented by the readers wrapping the terminal file
+type fileReader interface {
+	File() *os.File
+}
+
+// AskPassword asks for the masked answer. The go-input masks only the answer read directly from the file,
+// so the wrapped terminal file is used for the question.
+func AskPassword(ui *input.UI, question string, defaultAnswer string) (string, error) {
+	maskedUI := ui
+	if reader, ok := ui.Reader.(fileReader); ok {
+		maskedUI = &input.UI{
+			Writer: ui.Writer,
+			Reader: reader.File(),
+		}
+	}
+
+	answer, err := maskedUI.Ask(question, &input.Options{
+		Default:  defaultAnswer,
+		Required: true,
+		Loop:     true,
+		Mask:     true,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to ask for '%s': %w", question, err)
 	}
 
-	return AnswerNo, nil
+	return answer, nil
 }
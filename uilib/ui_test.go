@@ -0,0 +1,68 @@
+package uilib
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/tcnksm/go-input"
+)
+
+func TestParseYesNo(t *testing.T) {
+	tests := []struct {
+		answer   string
+		expected YesNoAnswer
+		ok       bool
+	}{
+		{answer: "y", expected: AnswerYes, ok: true},
+		{answer: "Y", expected: AnswerYes, ok: true},
+		{answer: "yes", expected: AnswerYes, ok: true},
+		{answer: "YES", expected: AnswerYes, ok: true},
+		{answer: " Yes ", expected: AnswerYes, ok: true},
+		{answer: "n", expected: AnswerNo, ok: true},
+		{answer: "N", expected: AnswerNo, ok: true},
+		{answer: "no", expected: AnswerNo, ok: true},
+		{answer: "No", expected: AnswerNo, ok: true},
+		{answer: "", ok: false},
+		{answer: "ye", ok: false},
+		{answer: "yess", ok: false},
+		{answer: "nope", ok: false},
+		{answer: "yes no", ok: false},
+		{answer: "1", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.answer, func(t *testing.T) {
+			answer, ok := parseYesNo(tt.answer)
+			if ok != tt.ok || answer != tt.expected {
+				t.Errorf("expected (%q, %t), got (%q, %t)", tt.expected, tt.ok, answer, ok)
+			}
+		})
+	}
+}
+
+func TestAskYesNo(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		defaultAnswer YesNoAnswer
+		expected      YesNoAnswer
+	}{
+		{name: "short answer", input: "n\n", defaultAnswer: AnswerYes, expected: AnswerNo},
+		{name: "default answer", input: "\n", defaultAnswer: AnswerNo, expected: AnswerNo},
+		{name: "invalid answer is asked again", input: "maybe\nY\n", defaultAnswer: AnswerNo, expected: AnswerYes},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ui := &input.UI{Writer: io.Discard, Reader: strings.NewReader(tt.input)}
+			answer, err := AskYesNo(ui, "Continue?", tt.defaultAnswer)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if answer != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, answer)
+			}
+		})
+	}
+}
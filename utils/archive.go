@@ -0,0 +1,126 @@
+package utils
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+)
+
+// ErrArchiveEntryNotFound is returned when the archive does not contain the requested entry
+var ErrArchiveEntryNotFound = errors.New("archive entry not found")
+
+// IsSupportedArchive returns true for the archives the ExtractArchiveEntry can read
+func IsSupportedArchive(fileName string) bool {
+	return strings.HasSuffix(fileName, ".zip") || strings.HasSuffix(fileName, ".tar.gz") || strings.HasSuffix(fileName, ".tgz")
+}
+
+// ExtractArchiveEntry extracts the single file from the .zip or the .tar.gz archive into the dst file.
+// The entry is matched by its path in the archive or by its base name, so the binary is found in the
+// archive bundling multiple binaries in directories. The archive format is selected by the extension.
+func ExtractArchiveEntry(archiveFilePath, entryName, dst string) error {
+	switch {
+	case strings.HasSuffix(archiveFilePath, ".zip"):
+		return extractZipEntry(archiveFilePath, entryName, dst)
+	case strings.HasSuffix(archiveFilePath, ".tar.gz"), strings.HasSuffix(archiveFilePath, ".tgz"):
+		return extractTarGzEntry(archiveFilePath, entryName, dst)
+	default:
+		return fmt.Errorf("unsupported archive %s: expected .zip, .tar.gz or .tgz file", archiveFilePath)
+	}
+}
+
+func archiveEntryMatches(entryPath, entryName string) bool {
+	entryPath = strings.TrimPrefix(path.Clean(entryPath), "./")
+
+	return entryPath == entryName || path.Base(entryPath) == entryName
+}
+
+func entryNotFoundError(entryName string, archiveContent []string) error {
+	return fmt.Errorf("%w: %s is not in the archive, archive contains %v", ErrArchiveEntryNotFound, entryName, archiveContent)
+}
+
+func extractZipEntry(archiveFilePath, entryName, dst string) error {
+	archive, err := zip.OpenReader(archiveFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to open zip archive: %w", err)
+	}
+	defer archive.Close()
+
+	archiveContent := []string{}
+	for _, f := range archive.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		archiveContent = append(archiveContent, f.Name)
+
+		if !archiveEntryMatches(f.Name, entryName) {
+			continue
+		}
+
+		fileInArchive, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("failed to open file in the archive: %w", err)
+		}
+		defer fileInArchive.Close()
+
+		return writeArchiveEntry(fileInArchive, dst, f.Mode())
+	}
+
+	return entryNotFoundError(entryName, archiveContent)
+}
+
+func extractTarGzEntry(archiveFilePath, entryName, dst string) error {
+	archiveFile, err := os.Open(archiveFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to open tar.gz archive: %w", err)
+	}
+	defer archiveFile.Close()
+
+	gzipReader, err := gzip.NewReader(archiveFile)
+	if err != nil {
+		return fmt.Errorf("failed to open gzip stream: %w", err)
+	}
+	defer gzipReader.Close()
+
+	archiveContent := []string{}
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read tar archive: %w", err)
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		archiveContent = append(archiveContent, header.Name)
+
+		if archiveEntryMatches(header.Name, entryName) {
+			return writeArchiveEntry(tarReader, dst, header.FileInfo().Mode())
+		}
+	}
+
+	return entryNotFoundError(entryName, archiveContent)
+}
+
+func writeArchiveEntry(entry io.Reader, dst string, mode os.FileMode) error {
+	dstFile, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return fmt.Errorf("failed to open buffer for file %s: %w", dst, err)
+	}
+	defer dstFile.Close()
+
+	if _, err := io.Copy(dstFile, entry); err != nil {
+		return fmt.Errorf("failed to copy file content from archive to output file: %w", err)
+	}
+
+	return dstFile.Close()
+}
@@ -0,0 +1,134 @@
+package utils
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type archiveFile struct {
+	name    string
+	content string
+}
+
+var multiBinaryArchive = []archiveFile{
+	{name: "dist/vegawallet", content: "wallet binary"},
+	{name: "dist/vega", content: "vega binary"},
+	{name: "README.md", content: "readme"},
+}
+
+func writeZipArchive(t *testing.T, archivePath string, files []archiveFile) {
+	t.Helper()
+
+	archive, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archive.Close()
+
+	writer := zip.NewWriter(archive)
+	for _, file := range files {
+		entry, err := writer.Create(file.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := entry.Write([]byte(file.content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeTarGzArchive(t *testing.T, archivePath string, files []archiveFile) {
+	t.Helper()
+
+	archive, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archive.Close()
+
+	gzipWriter := gzip.NewWriter(archive)
+	writer := tar.NewWriter(gzipWriter)
+	if err := writer.WriteHeader(&tar.Header{Name: "dist/", Typeflag: tar.TypeDir, Mode: 0o755}); err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		header := &tar.Header{Name: "./" + file.name, Typeflag: tar.TypeReg, Mode: 0o755, Size: int64(len(file.content))}
+		if err := writer.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := writer.Write([]byte(file.content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExtractArchiveEntry(t *testing.T) {
+	tests := []struct {
+		name        string
+		archiveName string
+		write       func(t *testing.T, archivePath string, files []archiveFile)
+		entryName   string
+		expected    string
+		wantErr     error
+	}{
+		{name: "zip entry by base name", archiveName: "vega.zip", write: writeZipArchive, entryName: "vega", expected: "vega binary"},
+		{name: "zip entry by path", archiveName: "vega.zip", write: writeZipArchive, entryName: "dist/vegawallet", expected: "wallet binary"},
+		{name: "zip missing entry", archiveName: "vega.zip", write: writeZipArchive, entryName: "visor", wantErr: ErrArchiveEntryNotFound},
+		{name: "tar.gz entry by base name", archiveName: "vega.tar.gz", write: writeTarGzArchive, entryName: "vega", expected: "vega binary"},
+		{name: "tgz entry by path", archiveName: "vega.tgz", write: writeTarGzArchive, entryName: "dist/vegawallet", expected: "wallet binary"},
+		{name: "tar.gz missing entry", archiveName: "vega.tar.gz", write: writeTarGzArchive, entryName: "visor", wantErr: ErrArchiveEntryNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			archivePath := filepath.Join(dir, tt.archiveName)
+			tt.write(t, archivePath, multiBinaryArchive)
+
+			dst := filepath.Join(dir, "binary")
+			err := ExtractArchiveEntry(archivePath, tt.entryName, dst)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v error, got %v", tt.wantErr, err)
+				}
+				// The error lists the archive content, so the user can select the right binary name
+				for _, file := range multiBinaryArchive {
+					if !strings.Contains(err.Error(), file.name) {
+						t.Errorf("expected error to list %s, got %s", file.name, err)
+					}
+				}
+
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			content, err := os.ReadFile(dst)
+			if err != nil || string(content) != tt.expected {
+				t.Errorf("expected %q, got %q(%v)", tt.expected, content, err)
+			}
+		})
+	}
+}
+
+func TestExtractArchiveEntryUnsupportedArchive(t *testing.T) {
+	if err := ExtractArchiveEntry(filepath.Join(t.TempDir(), "vega.rar"), "vega", "vega"); err == nil {
+		t.Error("expected error for the unsupported archive")
+	}
+}
@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+)
+
+var sha256Regex = regexp.MustCompile(`^[0-9a-fA-F]{64}$`)
+
+// IsSHA256 checks the value is hex encoded SHA256 checksum
+func IsSHA256(value string) bool {
+	return sha256Regex.MatchString(value)
+}
+
+func FileSHA256(filePath string) (string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file(%s): %w", filePath, err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("failed to read file(%s): %w", filePath, err)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -2,32 +2,185 @@ package utils
 
 import (
 	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/tomwright/dasel"
 	"github.com/tomwright/dasel/storage"
 )
 
-func UpdateConfig(filePath, configType string, newValues map[string]interface{}) error {
+type ConfigChange struct {
+	Key      string
+	OldValue interface{}
+	NewValue interface{}
+	// Created is true when key did not exist in the config before the update
+	Created bool
+}
+
+// ConfigArrayAppend is the UpdateConfig value, which adds items to the existing array instead of replacing it.
+// Items already present in the array are skipped. It is supported only for the TOML configs.
+type ConfigArrayAppend []interface{}
+
+func AppendToArray(items ...interface{}) ConfigArrayAppend {
+	return ConfigArrayAppend(items)
+}
+
+func (c ConfigChange) Modified() bool {
+	return c.Created || fmt.Sprint(c.OldValue) != fmt.Sprint(c.NewValue)
+}
+
+// BackupConfig copies the config file to the timestamped `.bak` file next to the original file. The counter
+// is added to the name when the backup with the same timestamp already exists, so backups are never
+// overwritten.
+func BackupConfig(filePath string) (string, error) {
+	backupPath, err := reserveBackupPath(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to backup config file %s: %w", filePath, err)
+	}
+
+	if err := CopyFile(filePath, backupPath); err != nil {
+		os.Remove(backupPath)
+
+		return "", fmt.Errorf("failed to backup config file %s: %w", filePath, err)
+	}
+
+	return backupPath, nil
+}
+
+// reserveBackupPath creates the empty backup file with the name not used by any other backup
+func reserveBackupPath(filePath string) (string, error) {
+	timestamp := time.Now().Format("20060102150405")
+	for idx := 0; ; idx++ {
+		backupPath := fmt.Sprintf("%s.%s.bak", filePath, timestamp)
+		if idx > 0 {
+			backupPath = fmt.Sprintf("%s.%s.%d.bak", filePath, timestamp, idx)
+		}
+
+		file, err := os.OpenFile(backupPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, ConfigFileMode)
+		if err == nil {
+			return backupPath, file.Close()
+		}
+		if !os.IsExist(err) {
+			return "", fmt.Errorf("failed to create backup file %s: %w", backupPath, err)
+		}
+	}
+}
+
+// UpdateConfig puts new values into the config file. Returned changes are sorted by key.
+// TOML files are edited in place, so comments and formatting of the untouched keys are preserved.
+// Slices are written as arrays. Use AppendToArray to add items to the existing array.
+func UpdateConfig(filePath, configType string, newValues map[string]interface{}) ([]ConfigChange, error) {
+	var (
+		changes []ConfigChange
+		err     error
+	)
+	if configType == "toml" {
+		changes, err = updateTOMLConfig(filePath, newValues)
+	} else {
+		changes, err = updateConfigWithDasel(filePath, configType, newValues)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+
+	return changes, nil
+}
+
+func updateConfigWithDasel(filePath, configType string, newValues map[string]interface{}) ([]ConfigChange, error) {
 	root, err := dasel.NewFromFile(filePath, configType)
 	if err != nil {
-		return fmt.Errorf("failed to open %s config file with dasel: %w", filePath, err)
+		return nil, fmt.Errorf("failed to open %s config file with dasel: %w", filePath, err)
 	}
+
+	changes := []ConfigChange{}
 	for k, v := range newValues {
-		if err := root.Put(fmt.Sprintf(".%s", k), v); err != nil {
-			return fmt.Errorf(
+		if _, isAppend := v.(ConfigArrayAppend); isAppend {
+			return nil, fmt.Errorf("failed to update %s parameter: append is supported only for toml configs", k)
+		}
+
+		selector := fmt.Sprintf(".%s", k)
+		change := ConfigChange{
+			Key:      k,
+			NewValue: v,
+			Created:  true,
+		}
+		if oldNode, err := root.Query(selector); err == nil {
+			change.OldValue = oldNode.InterfaceValue()
+			change.Created = false
+		}
+
+		if err := root.Put(selector, v); err != nil {
+			return nil, fmt.Errorf(
 				"failed to update value for %s parameter in the %s file: %w",
 				k,
 				filePath,
 				err,
 			)
 		}
+		changes = append(changes, change)
 	}
 
-	if err := root.WriteToFile(filePath, "toml", []storage.ReadWriteOption{
+	if err := root.WriteToFile(filePath, configType, []storage.ReadWriteOption{
 		storage.IndentOption("  "),
 		storage.PrettyPrintOption(true),
 	}); err != nil {
-		return fmt.Errorf("failed to write updated config to file %s: %w", filePath, err)
+		return nil, fmt.Errorf("failed to write updated config to file %s: %w", filePath, err)
+	}
+
+	return changes, nil
+}
+
+// isSecretConfigKey returns true for keys which values must not be logged, e.g. SQLStore.ConnectionConfig.Password
+func isSecretConfigKey(key string) bool {
+	key = strings.ToLower(key)
+
+	return strings.Contains(key, "pass") || strings.Contains(key, "secret")
+}
+
+// RedactSecrets returns copy of the config values with secrets masked, so they can be logged
+func RedactSecrets(values map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		if isSecretConfigKey(key) {
+			value = "***"
+		}
+		result[key] = value
+	}
+
+	return result
+}
+
+// FormatConfigChanges returns human readable diff for the config changes. Created
+// keys are prefixed with `+`, modified keys with `~`, unchanged keys are skipped.
+func FormatConfigChanges(changes []ConfigChange) string {
+	lines := []string{}
+	for _, change := range changes {
+		if !change.Modified() {
+			continue
+		}
+
+		oldValue, newValue := change.OldValue, change.NewValue
+		if isSecretConfigKey(change.Key) {
+			oldValue, newValue = "***", "***"
+		}
+
+		if change.Created {
+			lines = append(lines, fmt.Sprintf("  + %s = %v", change.Key, newValue))
+			continue
+		}
+
+		lines = append(lines, fmt.Sprintf("  ~ %s: %v -> %v", change.Key, oldValue, newValue))
 	}
-	return nil
+
+	if len(lines) < 1 {
+		return "  no changes"
+	}
+
+	return strings.Join(lines, "\n")
 }
@@ -0,0 +1,120 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIsSecretConfigKey(t *testing.T) {
+	tests := []struct {
+		key      string
+		expected bool
+	}{
+		{key: "SQLStore.ConnectionConfig.Password", expected: true},
+		{key: "SQLStore.ConnectionConfig.Pass", expected: true},
+		{key: "Ethereum.ClientSecret", expected: true},
+		{key: "passphrase", expected: true},
+		{key: "SQLStore.ConnectionConfig.Username", expected: false},
+		{key: "p2p.seeds", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := isSecretConfigKey(tt.key); got != tt.expected {
+				t.Errorf("expected isSecretConfigKey(%s) = %t, got %t", tt.key, tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRedactSecretsInLogOutput(t *testing.T) {
+	values := map[string]interface{}{
+		"SQLStore.ConnectionConfig.Password": "sql-secret",
+		"SQLStore.ConnectionConfig.Username": "vega",
+		"API.ClientSecret":                   "client-secret",
+	}
+
+	logged := fmt.Sprintf("New parameters: %v", RedactSecrets(values))
+	for _, secret := range []string{"sql-secret", "client-secret"} {
+		if strings.Contains(logged, secret) {
+			t.Errorf("log output contains the %s secret: %s", secret, logged)
+		}
+	}
+	if !strings.Contains(logged, "SQLStore.ConnectionConfig.Username:vega") {
+		t.Errorf("log output does not contain the non secret values: %s", logged)
+	}
+
+	if values["SQLStore.ConnectionConfig.Password"] != "sql-secret" {
+		t.Errorf("redacted the original values: %v", values)
+	}
+}
+
+func TestFormatConfigChangesRedactsSecrets(t *testing.T) {
+	formatted := FormatConfigChanges([]ConfigChange{
+		{Key: "SQLStore.ConnectionConfig.Password", OldValue: "old-secret", NewValue: "new-secret"},
+		{Key: "SQLStore.ConnectionConfig.Port", OldValue: int64(5432), NewValue: 5433},
+	})
+
+	for _, secret := range []string{"old-secret", "new-secret"} {
+		if strings.Contains(formatted, secret) {
+			t.Errorf("formatted changes contain the %s secret:\n%s", secret, formatted)
+		}
+	}
+	if !strings.Contains(formatted, "~ SQLStore.ConnectionConfig.Port: 5432 -> 5433") {
+		t.Errorf("formatted changes do not contain the port change:\n%s", formatted)
+	}
+}
+
+func TestBackupConfigDoesNotOverwriteBackups(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	backups := map[string]string{}
+	for _, content := range []string{"version = 1\n", "version = 2\n", "version = 3\n"} {
+		if err := os.WriteFile(configPath, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		backupPath, err := BackupConfig(configPath)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		backups[backupPath] = content
+	}
+
+	if len(backups) != 3 {
+		t.Fatalf("expected 3 distinct backups, got %v", backups)
+	}
+	for backupPath, expected := range backups {
+		content, err := os.ReadFile(backupPath)
+		if err != nil || string(content) != expected {
+			t.Errorf("expected %q in %s, got %q(%v)", expected, backupPath, content, err)
+		}
+	}
+}
+
+func TestBackupConfigKeepsOriginalContent(t *testing.T) {
+	const original = "# Admin config\n[Admin]\n  Enabled = true\n"
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(configPath, []byte(original), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	backupPath, err := BackupConfig(configPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if filepath.Dir(backupPath) != filepath.Dir(configPath) || !strings.HasSuffix(backupPath, ".bak") {
+		t.Errorf("expected .bak file next to the config, got %s", backupPath)
+	}
+
+	if _, err := UpdateConfig(configPath, "toml", map[string]interface{}{"Admin.Enabled": false}); err != nil {
+		t.Fatalf("failed to update config: %s", err)
+	}
+
+	content, err := os.ReadFile(backupPath)
+	if err != nil || string(content) != original {
+		t.Errorf("expected pre-modification content %q in the backup, got %q(%v)", original, content, err)
+	}
+}
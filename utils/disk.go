@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+var sizeUnits = []struct {
+	suffix     string
+	multiplier uint64
+}{
+	{suffix: "TB", multiplier: 1 << 40},
+	{suffix: "GB", multiplier: 1 << 30},
+	{suffix: "MB", multiplier: 1 << 20},
+	{suffix: "KB", multiplier: 1 << 10},
+	{suffix: "B", multiplier: 1},
+}
+
+// FreeSpace returns free space available for the unprivileged user on the
+// filesystem of the given path. When path does not exist, the closest existing
+// parent is checked.
+func FreeSpace(path string) (uint64, error) {
+	path = filepath.Clean(path)
+	for !FileExists(path) {
+		parent := filepath.Dir(path)
+		if parent == path {
+			break
+		}
+		path = parent
+	}
+
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, fmt.Errorf("failed to get filesystem stats for %s: %w", path, err)
+	}
+
+	return uint64(stat.Bavail) * uint64(stat.Bsize), nil
+}
+
+func CheckFreeSpace(path string, requiredBytes uint64) error {
+	freeSpace, err := FreeSpace(path)
+	if err != nil {
+		return err
+	}
+
+	if freeSpace < requiredBytes {
+		return fmt.Errorf(
+			"not enough free space for %s: required %s, available %s",
+			path,
+			FormatSize(requiredBytes),
+			FormatSize(freeSpace),
+		)
+	}
+
+	return nil
+}
+
+// ParseSize parses size with optional unit, e.g. 512MB or 100GB. Units are 1024-based
+func ParseSize(size string) (uint64, error) {
+	normalizedSize := strings.ToUpper(strings.TrimSpace(size))
+	for _, unit := range sizeUnits {
+		if !strings.HasSuffix(normalizedSize, unit.suffix) {
+			continue
+		}
+
+		value, err := strconv.ParseUint(strings.TrimSpace(strings.TrimSuffix(normalizedSize, unit.suffix)), 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid size %s: %w", size, err)
+		}
+
+		return value * unit.multiplier, nil
+	}
+
+	value, err := strconv.ParseUint(normalizedSize, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %s: %w", size, err)
+	}
+
+	return value, nil
+}
+
+func FormatSize(size uint64) string {
+	for _, unit := range sizeUnits {
+		if size >= unit.multiplier && unit.multiplier > 1 {
+			return fmt.Sprintf("%.1f%s", float64(size)/float64(unit.multiplier), unit.suffix)
+		}
+	}
+
+	return fmt.Sprintf("%dB", size)
+}
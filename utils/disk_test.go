@@ -0,0 +1,33 @@
+package utils
+
+import "testing"
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		size     string
+		expected uint64
+		wantErr  bool
+	}{
+		{size: "512", expected: 512},
+		{size: "512B", expected: 512},
+		{size: "10KB", expected: 10 * 1024},
+		{size: "10MB", expected: 10 * 1024 * 1024},
+		{size: " 1 gb ", expected: 1024 * 1024 * 1024},
+		{size: "2TB", expected: 2 * 1024 * 1024 * 1024 * 1024},
+		{size: "1.5MB", wantErr: true},
+		{size: "-1MB", wantErr: true},
+		{size: "MB", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.size, func(t *testing.T) {
+			got, err := ParseSize(tt.size)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error: %t, got %v", tt.wantErr, err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %d, got %d", tt.expected, got)
+			}
+		})
+	}
+}
@@ -5,7 +5,21 @@ import (
 	"io"
 	"os"
 	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"syscall"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// ConfigFileMode is used for the config files written by the assistant
+	ConfigFileMode os.FileMode = 0o644
+	// DirMode is used for the directories created by the assistant
+	DirMode os.FileMode = 0o755
+	// ExecutableFileMode is used for the vega and the visor binaries
+	ExecutableFileMode os.FileMode = 0o755
 )
 
 func FileExists(filePath string) bool {
@@ -26,6 +40,37 @@ func IsDir(filePath string) bool {
 	return stat.IsDir()
 }
 
+// PathsOverlap returns true when paths are equal, or one path is nested in the other
+func PathsOverlap(path1, path2 string) bool {
+	absPath1, err1 := filepath.Abs(path1)
+	absPath2, err2 := filepath.Abs(path2)
+	if err1 != nil || err2 != nil {
+		absPath1, absPath2 = filepath.Clean(path1), filepath.Clean(path2)
+	}
+
+	isNested := func(parent, child string) bool {
+		relPath, err := filepath.Rel(parent, child)
+		if err != nil {
+			return false
+		}
+
+		return relPath == "." || (relPath != ".." && !strings.HasPrefix(relPath, ".."+string(filepath.Separator)))
+	}
+
+	return isNested(absPath1, absPath2) || isNested(absPath2, absPath1)
+}
+
+func IsExecutable(filePath string) bool {
+	stat, err := os.Stat(filePath)
+	if err != nil {
+		return false
+	}
+
+	return !stat.IsDir() && stat.Mode().Perm()&0o111 != 0
+}
+
+// CopyFile copies the srcFile content to the dstFile. The dstFile gets the srcFile permissions, so
+// copied binaries stay executable.
 func CopyFile(srcFile, dstFile string) error {
 	src, err := os.Open(srcFile)
 	if err != nil {
@@ -33,15 +78,21 @@ func CopyFile(srcFile, dstFile string) error {
 	}
 	defer src.Close()
 
+	srcStat, err := src.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat source file(%s): %w", srcFile, err)
+	}
+
 	dst, err := os.Create(dstFile)
 	if err != nil {
 		return fmt.Errorf("failed to create destination file(%s): %w", dstFile, err)
 	}
 	defer dst.Close()
 
-	srcStat, _ := src.Stat()
-	if err := os.Chmod(dstFile, srcStat.Mode()); err != nil {
-		return fmt.Errorf("failed to change permissinos for destination file(%s): %w", dstFile, err)
+	// Chmod is required, because the mode is not changed for the existing file and the umask applies
+	// to the new file
+	if err := os.Chmod(dstFile, srcStat.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to change permissions for destination file(%s): %w", dstFile, err)
 	}
 
 	// Copy the content of srcFile to dstFile
@@ -54,6 +105,10 @@ func CopyFile(srcFile, dstFile string) error {
 		)
 	}
 
+	if err := dst.Close(); err != nil {
+		return fmt.Errorf("failed to close destination file(%s): %w", dstFile, err)
+	}
+
 	return nil
 }
 
@@ -88,3 +143,68 @@ func GetFileOwner(filepath string) (string, string, error) {
 
 	return userName.Username, groupName.Name, nil
 }
+
+// RemoveDirContents removes everything inside the dirPath and keeps the directory itself, so it may be
+// the mount point. Every removed path is logged before it is removed.
+func RemoveDirContents(logger *zap.SugaredLogger, dirPath string) error {
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", dirPath, err)
+	}
+
+	for _, entry := range entries {
+		entryPath := filepath.Join(dirPath, entry.Name())
+		logger.Warnf("Removing %s", entryPath)
+		if err := os.RemoveAll(entryPath); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", entryPath, err)
+		}
+	}
+
+	return nil
+}
+
+// LookupOwner resolves the `user[:group]` owner to the uid and the gid. The primary group of the user is
+// used when the group is not set.
+func LookupOwner(owner string) (int, int, error) {
+	userName, groupName, hasGroup := strings.Cut(owner, ":")
+	ownerUser, err := user.Lookup(userName)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to find user %s: %w", userName, err)
+	}
+
+	gid := ownerUser.Gid
+	if hasGroup {
+		ownerGroup, err := user.LookupGroup(groupName)
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to find group %s: %w", groupName, err)
+		}
+		gid = ownerGroup.Gid
+	}
+
+	uidInt, err := strconv.Atoi(ownerUser.Uid)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to convert uid(%s) of the %s user: %w", ownerUser.Uid, userName, err)
+	}
+	gidInt, err := strconv.Atoi(gid)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to convert gid(%s): %w", gid, err)
+	}
+
+	return uidInt, gidInt, nil
+}
+
+// ChownRecursive changes the owner of the path and everything inside it. Symlinks are not followed, the
+// symlink itself gets the owner.
+func ChownRecursive(rootPath string, uid, gid int) error {
+	return filepath.WalkDir(rootPath, func(path string, _ os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if err := os.Lchown(path, uid, gid); err != nil {
+			return fmt.Errorf("failed to change owner of %s: %w", path, err)
+		}
+
+		return nil
+	})
+}
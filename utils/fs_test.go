@@ -0,0 +1,90 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCopyFilePreservesMode(t *testing.T) {
+	tests := []struct {
+		name        string
+		srcMode     os.FileMode
+		existingDst bool
+	}{
+		{name: "executable to new file", srcMode: ExecutableFileMode},
+		{name: "executable over existing file", srcMode: ExecutableFileMode, existingDst: true},
+		{name: "config over existing file", srcMode: ConfigFileMode, existingDst: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			srcFile := filepath.Join(dir, "vega")
+			dstFile := filepath.Join(dir, "copy", "vega")
+			if err := os.WriteFile(srcFile, []byte("binary"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Chmod(srcFile, tt.srcMode); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.MkdirAll(filepath.Dir(dstFile), DirMode); err != nil {
+				t.Fatal(err)
+			}
+			if tt.existingDst {
+				if err := os.WriteFile(dstFile, []byte("old binary content"), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := CopyFile(srcFile, dstFile); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			stat, err := os.Stat(dstFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stat.Mode().Perm() != tt.srcMode {
+				t.Errorf("expected mode %s, got %s", tt.srcMode, stat.Mode().Perm())
+			}
+			if IsExecutable(dstFile) != (tt.srcMode&0o111 != 0) {
+				t.Errorf("expected executable: %t, got %t", tt.srcMode&0o111 != 0, IsExecutable(dstFile))
+			}
+
+			content, err := os.ReadFile(dstFile)
+			if err != nil || string(content) != "binary" {
+				t.Errorf("expected copied content, got %q(%v)", content, err)
+			}
+		})
+	}
+}
+
+func TestPathsOverlap(t *testing.T) {
+	tests := []struct {
+		name     string
+		path1    string
+		path2    string
+		expected bool
+	}{
+		{name: "identical", path1: "/opt/vega", path2: "/opt/vega", expected: true},
+		{name: "identical with trailing slash", path1: "/opt/vega/", path2: "/opt/vega", expected: true},
+		{name: "nested", path1: "/opt/vega", path2: "/opt/vega/data", expected: true},
+		{name: "parent", path1: "/opt/vega/data", path2: "/opt/vega", expected: true},
+		{name: "nested with trailing slash", path1: "/opt/vega/", path2: "/opt/vega/data/", expected: true},
+		{name: "nested via dot-dot", path1: "/opt/visor/../vega", path2: "/opt/vega/data", expected: true},
+		{name: "identical via dot-dot", path1: "/opt/vega/data/..", path2: "/opt/vega", expected: true},
+		{name: "siblings", path1: "/opt/vega", path2: "/opt/visor", expected: false},
+		{name: "siblings with common prefix", path1: "/opt/vega", path2: "/opt/vega-data", expected: false},
+		{name: "siblings via dot-dot", path1: "/opt/vega/../visor", path2: "/opt/vega", expected: false},
+		{name: "relative nested", path1: "vega", path2: "vega/data", expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PathsOverlap(tt.path1, tt.path2); got != tt.expected {
+				t.Errorf("expected PathsOverlap(%q, %q) = %t, got %t", tt.path1, tt.path2, tt.expected, got)
+			}
+		})
+	}
+}
@@ -1,35 +1,158 @@
 package utils
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
 )
 
+type HTTPStatusError struct {
+	StatusCode int
+	Status     string
+	Header     http.Header
+}
+
+func (e HTTPStatusError) Error() string {
+	return fmt.Sprintf("bad http status: %s", e.Status)
+}
+
+// DownloadFile downloads file to the `<dst>.part` file first, and renames it
+// to dst when download is completed. When the `.part` file already exists,
+// download is resumed with the HTTP Range and If-Range requests. The ETag or the
+// Last-Modified value of the partial download is kept in the `<dst>.part.validator`
+// file, so the server sends the whole file when the remote file has changed.
+// Download starts from scratch if server does not support range requests, or the
+// partial download has no validator.
 func DownloadFile(url, dst string) error {
-	resp, err := http.Get(url)
+	return DownloadFileWithHeaders(url, dst, nil)
+}
+
+// DownloadFileWithHeaders works like DownloadFile, but sends given headers with the request
+func DownloadFileWithHeaders(url, dst string, headers map[string]string) error {
+	return DownloadFileContext(context.Background(), url, dst, headers)
+}
+
+// DownloadFileContext works like DownloadFileWithHeaders, but the download is cancelled when ctx is done.
+// The `.part` file is kept, so the cancelled download can be resumed.
+func DownloadFileContext(ctx context.Context, url, dst string, headers map[string]string) error {
+	partFilePath := fmt.Sprintf("%s.part", dst)
+	validatorPath := fmt.Sprintf("%s.validator", partFilePath)
+
+	var offset int64
+	if partFileStat, err := os.Stat(partFilePath); err == nil && !partFileStat.IsDir() {
+		offset = partFileStat.Size()
+	}
+
+	// Without the validator we cannot tell the partial download comes from the same remote file
+	validator, err := os.ReadFile(validatorPath)
+	if err != nil || len(bytes.TrimSpace(validator)) == 0 {
+		offset = 0
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return fmt.Errorf("failed to download file: %w", err)
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		req.Header.Set("If-Range", string(bytes.TrimSpace(validator)))
 	}
 
+	resp, err := DoHTTPRequest(req)
+	if err != nil {
+		return fmt.Errorf("failed to download file: %w", err)
+	}
 	defer resp.Body.Close()
-	if resp.StatusCode != 200 {
-		return fmt.Errorf("status code is not 200")
+
+	fileFlags := os.O_WRONLY | os.O_CREATE
+	switch resp.StatusCode {
+	case http.StatusOK:
+		// Server sends the whole file, we cannot resume
+		fileFlags |= os.O_TRUNC
+		if err := saveDownloadValidator(validatorPath, resp.Header); err != nil {
+			return err
+		}
+	case http.StatusPartialContent:
+		fileFlags |= os.O_APPEND
+	case http.StatusRequestedRangeNotSatisfiable:
+		// The part file is broken or the remote file has changed
+		if err := os.Remove(partFilePath); err != nil {
+			return fmt.Errorf("failed to remove partially downloaded file: %w", err)
+		}
+		os.Remove(validatorPath)
+
+		return DownloadFileContext(ctx, url, dst, headers)
+	default:
+		return HTTPStatusError{
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			Header:     resp.Header,
+		}
 	}
 
 	// Create the file
-	out, err := os.Create(dst)
+	out, err := os.OpenFile(partFilePath, fileFlags, ConfigFileMode)
 	if err != nil {
 		return fmt.Errorf("failed to create destination file: %w", err)
 	}
 	defer out.Close()
 
+	// Content length is unknown(-1) when the server does not send it
+	total := resp.ContentLength
+	downloaded := int64(0)
+	if resp.StatusCode == http.StatusPartialContent {
+		downloaded = offset
+		if total >= 0 {
+			total += offset
+		}
+	}
+
 	// Write the body to file
-	_, err = io.Copy(out, resp.Body)
+	_, err = io.Copy(out, newProgressReader(newRateLimitedReader(ctx, resp.Body), filepath.Base(dst), downloaded, total))
 	if err != nil {
 		return fmt.Errorf("failed to copy downloaded body to dst file: %w", err)
 	}
 
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to close destination file: %w", err)
+	}
+
+	if err := os.Rename(partFilePath, dst); err != nil {
+		return fmt.Errorf("failed to move downloaded file to %s: %w", dst, err)
+	}
+	os.Remove(validatorPath)
+
+	return nil
+}
+
+// saveDownloadValidator writes the value for the If-Range header of the resumed download. Weak ETags
+// cannot be used in the If-Range header, the Last-Modified is used then. The old validator is removed
+// when the server sends neither.
+func saveDownloadValidator(validatorPath string, header http.Header) error {
+	validator := header.Get("ETag")
+	if validator == "" || strings.HasPrefix(validator, "W/") {
+		validator = header.Get("Last-Modified")
+	}
+
+	if validator == "" {
+		if err := os.Remove(validatorPath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove download validator: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := os.WriteFile(validatorPath, []byte(validator), ConfigFileMode); err != nil {
+		return fmt.Errorf("failed to write download validator: %w", err)
+	}
+
 	return nil
 }
@@ -0,0 +1,97 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDownloadFileContextResume(t *testing.T) {
+	content := []byte("the remote file content, large enough to be downloaded in parts")
+
+	tests := []struct {
+		name           string
+		partContent    []byte
+		validator      string
+		expectedStatus int
+	}{
+		{name: "resumed with 206", partContent: content[:10], validator: `"v1"`, expectedStatus: http.StatusPartialContent},
+		{name: "remote file changed, 200 fallback", partContent: []byte("stale file"), validator: `"v0"`, expectedStatus: http.StatusOK},
+		{name: "broken part file, 416", partContent: append(bytes.Clone(content), "garbage"...), validator: `"v1"`, expectedStatus: http.StatusRequestedRangeNotSatisfiable},
+		{name: "part file without validator", partContent: []byte("stale file"), expectedStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statuses := []int{}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Range") != "" && r.Header.Get("If-Range") == "" {
+					t.Error("expected If-Range header with the Range header")
+				}
+
+				recorder := httptest.NewRecorder()
+				recorder.Header().Set("ETag", `"v1"`)
+				http.ServeContent(recorder, r, "file", time.Time{}, bytes.NewReader(content))
+				statuses = append(statuses, recorder.Code)
+
+				for name, values := range recorder.Header() {
+					w.Header()[name] = values
+				}
+				w.WriteHeader(recorder.Code)
+				w.Write(recorder.Body.Bytes())
+			}))
+			defer server.Close()
+
+			dst := filepath.Join(t.TempDir(), "file")
+			if err := os.WriteFile(dst+".part", tt.partContent, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if tt.validator != "" {
+				if err := os.WriteFile(dst+".part.validator", []byte(tt.validator), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := DownloadFileContext(context.Background(), server.URL, dst, nil); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if len(statuses) == 0 || statuses[0] != tt.expectedStatus {
+				t.Errorf("expected first response with status %d, got %v", tt.expectedStatus, statuses)
+			}
+			downloaded, err := os.ReadFile(dst)
+			if err != nil || !bytes.Equal(downloaded, content) {
+				t.Errorf("expected downloaded content %q, got %q(%v)", content, downloaded, err)
+			}
+			for _, leftover := range []string{dst + ".part", dst + ".part.validator"} {
+				if FileExists(leftover) {
+					t.Errorf("expected %s to be removed after the download", leftover)
+				}
+			}
+		})
+	}
+}
+
+func TestDownloadFileContextKeepsValidatorOfInterruptedDownload(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Content-Length", "100")
+		w.Write([]byte("partial"))
+	}))
+	defer server.Close()
+
+	dst := filepath.Join(t.TempDir(), "file")
+	if err := DownloadFileContext(context.Background(), server.URL, dst, nil); err == nil {
+		t.Fatal("expected error for the interrupted download")
+	}
+
+	validator, err := os.ReadFile(dst + ".part.validator")
+	if err != nil || string(validator) != `"v1"` {
+		t.Errorf("expected the ETag to be kept for the resume, got %q(%v)", validator, err)
+	}
+}
@@ -0,0 +1,108 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// tcpListenState is the state of the listening socket in the /proc/net/tcp
+const tcpListenState = "0A"
+
+// CheckPortsFree checks nothing listens on the TCP ports. The error lists occupied ports with the
+// process using them, when it can be found. Processes are found only on Linux.
+func CheckPortsFree(ports []int) error {
+	occupied := []string{}
+	for _, port := range ports {
+		listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+		if err == nil {
+			listener.Close()
+			continue
+		}
+
+		if process := portProcess(port); process != "" {
+			occupied = append(occupied, fmt.Sprintf("%d(%s)", port, process))
+		} else {
+			occupied = append(occupied, strconv.Itoa(port))
+		}
+	}
+
+	if len(occupied) > 0 {
+		return fmt.Errorf("ports already in use: %s", strings.Join(occupied, ", "))
+	}
+
+	return nil
+}
+
+// portProcess returns the pid and the name of the process listening on the port, or empty string when
+// it cannot be found, e.g. the process belongs to other user
+func portProcess(port int) string {
+	inodes := map[string]bool{}
+	for _, tcpTable := range []string{"/proc/net/tcp", "/proc/net/tcp6"} {
+		for _, inode := range listeningSocketInodes(tcpTable, port) {
+			inodes[inode] = true
+		}
+	}
+	if len(inodes) == 0 {
+		return ""
+	}
+
+	fdPaths, _ := filepath.Glob("/proc/[0-9]*/fd/*")
+	sort.Strings(fdPaths)
+	for _, fdPath := range fdPaths {
+		link, err := os.Readlink(fdPath)
+		if err != nil || !strings.HasPrefix(link, "socket:[") {
+			continue
+		}
+
+		if !inodes[strings.TrimSuffix(strings.TrimPrefix(link, "socket:["), "]")] {
+			continue
+		}
+
+		pidDir := filepath.Dir(filepath.Dir(fdPath))
+		pid := filepath.Base(pidDir)
+		name, err := os.ReadFile(filepath.Join(pidDir, "comm"))
+		if err != nil {
+			return fmt.Sprintf("pid %s", pid)
+		}
+
+		return fmt.Sprintf("pid %s, %s", pid, strings.TrimSpace(string(name)))
+	}
+
+	return ""
+}
+
+// listeningSocketInodes returns inodes of the sockets listening on the port from the /proc/net/tcp table
+func listeningSocketInodes(tcpTable string, port int) []string {
+	file, err := os.Open(tcpTable)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	inodes := []string{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt uid timeout inode
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 || fields[3] != tcpListenState {
+			continue
+		}
+
+		_, localPort, found := strings.Cut(fields[1], ":")
+		if !found {
+			continue
+		}
+
+		if parsedPort, err := strconv.ParseInt(localPort, 16, 32); err == nil && int(parsedPort) == port {
+			inodes = append(inodes, fields[9])
+		}
+	}
+
+	return inodes
+}
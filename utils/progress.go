@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// downloadProgressInterval throttles the download progress logs
+const downloadProgressInterval = 5 * time.Second
+
+var (
+	downloadProgressLoggerMu sync.Mutex
+	downloadProgressLogger   *zap.SugaredLogger
+)
+
+// SetDownloadProgressLogger sets logger for the download progress. Progress is not reported when logger is nil.
+func SetDownloadProgressLogger(logger *zap.SugaredLogger) {
+	downloadProgressLoggerMu.Lock()
+	defer downloadProgressLoggerMu.Unlock()
+
+	downloadProgressLogger = logger
+}
+
+func currentDownloadProgressLogger() *zap.SugaredLogger {
+	downloadProgressLoggerMu.Lock()
+	defer downloadProgressLoggerMu.Unlock()
+
+	return downloadProgressLogger
+}
+
+// progressReader logs number of bytes read periodically. Total is negative when size is unknown.
+type progressReader struct {
+	reader     io.Reader
+	logger     *zap.SugaredLogger
+	name       string
+	total      int64
+	downloaded int64
+	lastReport time.Time
+}
+
+func newProgressReader(reader io.Reader, name string, downloaded, total int64) io.Reader {
+	logger := currentDownloadProgressLogger()
+	if logger == nil {
+		return reader
+	}
+
+	return &progressReader{
+		reader:     reader,
+		logger:     logger,
+		name:       name,
+		total:      total,
+		downloaded: downloaded,
+		lastReport: time.Now(),
+	}
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.downloaded += int64(n)
+
+	if err == io.EOF {
+		r.report()
+	} else if time.Since(r.lastReport) >= downloadProgressInterval {
+		r.report()
+		r.lastReport = time.Now()
+	}
+
+	return n, err
+}
+
+func (r *progressReader) report() {
+	if r.total <= 0 {
+		r.logger.Infof("Downloading %s: %s", r.name, FormatSize(uint64(r.downloaded)))
+
+		return
+	}
+
+	r.logger.Infof(
+		"Downloading %s: %d%% (%s/%s)",
+		r.name,
+		r.downloaded*100/r.total,
+		FormatSize(uint64(r.downloaded)),
+		FormatSize(uint64(r.total)),
+	)
+}
@@ -0,0 +1,129 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+var (
+	httpClientMu sync.Mutex
+	httpClient   = newHTTPClient(httpproxy.FromEnvironment())
+)
+
+// ProxyError is returned when the request fails on the proxy, so it can be told apart from the errors
+// returned by the origin server
+type ProxyError struct {
+	Proxy string
+	Err   error
+}
+
+func (e *ProxyError) Error() string {
+	return fmt.Sprintf("proxy %s error: %s", e.Proxy, e.Err.Error())
+}
+
+func (e *ProxyError) Unwrap() error {
+	return e.Err
+}
+
+// SetProxy configures the proxy for the downloads and the GitHub requests. The HTTP_PROXY, HTTPS_PROXY
+// and NO_PROXY environment variables are used when proxyURL is empty. The NO_PROXY is honored for the
+// proxyURL as well.
+func SetProxy(proxyURL string) error {
+	config := httpproxy.FromEnvironment()
+	if proxyURL != "" {
+		parsedURL, err := url.Parse(proxyURL)
+		if err != nil {
+			return fmt.Errorf("invalid proxy url: %w", err)
+		}
+
+		if !slices.Contains([]string{"http", "https", "socks5"}, parsedURL.Scheme) || parsedURL.Host == "" {
+			return fmt.Errorf(
+				"invalid proxy url(%s): expected <http|https|socks5>://<host>:<port>",
+				parsedURL.Redacted(),
+			)
+		}
+
+		config.HTTPProxy = proxyURL
+		config.HTTPSProxy = proxyURL
+	}
+
+	httpClientMu.Lock()
+	defer httpClientMu.Unlock()
+
+	httpClient = newHTTPClient(config)
+
+	return nil
+}
+
+func newHTTPClient(config *httpproxy.Config) *http.Client {
+	proxyFunc := config.ProxyFunc()
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = func(req *http.Request) (*url.URL, error) {
+		return proxyFunc(req.URL)
+	}
+	// Proxy rejecting the CONNECT request for the https origin returns plain error otherwise
+	transport.OnProxyConnectResponse = func(
+		_ context.Context,
+		proxyURL *url.URL,
+		_ *http.Request,
+		connectResp *http.Response,
+	) error {
+		if connectResp.StatusCode != http.StatusOK {
+			return &ProxyError{Proxy: proxyURL.Redacted(), Err: fmt.Errorf("bad http status: %s", connectResp.Status)}
+		}
+
+		return nil
+	}
+
+	return &http.Client{Transport: transport}
+}
+
+// HTTPClient returns the client with the proxy configured by the SetProxy
+func HTTPClient() *http.Client {
+	httpClientMu.Lock()
+	defer httpClientMu.Unlock()
+
+	return httpClient
+}
+
+// DoHTTPRequest sends the request with the HTTPClient. Errors caused by the proxy are returned as
+// the ProxyError.
+func DoHTTPRequest(req *http.Request) (*http.Response, error) {
+	client := HTTPClient()
+
+	proxyURL, err := client.Transport.(*http.Transport).Proxy(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select proxy for %s: %w", req.URL.Redacted(), err)
+	}
+
+	resp, err := client.Do(req)
+	if proxyURL == nil {
+		return resp, err
+	}
+
+	if err != nil {
+		opErr := &net.OpError{}
+		if errors.As(err, &opErr) && opErr.Op == "proxyconnect" {
+			return nil, &ProxyError{Proxy: proxyURL.Redacted(), Err: err}
+		}
+
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusProxyAuthRequired {
+		resp.Body.Close()
+
+		return nil, &ProxyError{Proxy: proxyURL.Redacted(), Err: fmt.Errorf("bad http status: %s", resp.Status)}
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDownloadFileContextUsesProxy(t *testing.T) {
+	proxied := []string{}
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.String())
+		w.Write([]byte("genesis content"))
+	}))
+	defer proxy.Close()
+
+	if err := SetProxy(proxy.URL); err != nil {
+		t.Fatalf("failed to set proxy: %s", err)
+	}
+	t.Cleanup(func() { SetProxy("") })
+
+	dst := filepath.Join(t.TempDir(), "genesis.json")
+	if err := DownloadFileContext(context.Background(), "http://downloads.vega.example/genesis.json", dst, nil); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(proxied) != 1 || proxied[0] != "http://downloads.vega.example/genesis.json" {
+		t.Errorf("expected download to be sent via proxy, got %v", proxied)
+	}
+	content, err := os.ReadFile(dst)
+	if err != nil || string(content) != "genesis content" {
+		t.Errorf("expected downloaded content from the proxy, got %q(%v)", content, err)
+	}
+}
+
+func TestDownloadFileContextProxyErrors(t *testing.T) {
+	// The port is free after the listener is closed, so the proxy connection is refused
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	deadProxyURL := "http://" + listener.Addr().String()
+	listener.Close()
+
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/auth" {
+			w.WriteHeader(http.StatusProxyAuthRequired)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer proxy.Close()
+
+	tests := []struct {
+		name          string
+		proxyURL      string
+		path          string
+		expectedProxy bool
+	}{
+		{name: "proxy connection refused", proxyURL: deadProxyURL, path: "/genesis.json", expectedProxy: true},
+		{name: "proxy authentication required", proxyURL: proxy.URL, path: "/auth", expectedProxy: true},
+		{name: "origin error", proxyURL: proxy.URL, path: "/genesis.json", expectedProxy: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := SetProxy(tt.proxyURL); err != nil {
+				t.Fatalf("failed to set proxy: %s", err)
+			}
+			t.Cleanup(func() { SetProxy("") })
+
+			dst := filepath.Join(t.TempDir(), "genesis.json")
+			err := DownloadFileContext(context.Background(), "http://downloads.vega.example"+tt.path, dst, nil)
+			if err == nil {
+				t.Fatal("expected download error")
+			}
+
+			proxyErr := &ProxyError{}
+			if isProxyErr := errors.As(err, &proxyErr); isProxyErr != tt.expectedProxy {
+				t.Errorf("expected ProxyError: %t, got %v", tt.expectedProxy, err)
+			}
+			statusErr := HTTPStatusError{}
+			if isStatusErr := errors.As(err, &statusErr); isStatusErr == tt.expectedProxy {
+				t.Errorf("expected origin HTTPStatusError: %t, got %v", !tt.expectedProxy, err)
+			}
+		})
+	}
+}
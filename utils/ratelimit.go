@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+var (
+	maxDownloadRateMu sync.Mutex
+	maxDownloadRate   uint64
+)
+
+// SetMaxDownloadRate limits all downloads to the bytesPerSecond. Zero disables the limit.
+func SetMaxDownloadRate(bytesPerSecond uint64) {
+	maxDownloadRateMu.Lock()
+	defer maxDownloadRateMu.Unlock()
+
+	maxDownloadRate = bytesPerSecond
+}
+
+func currentMaxDownloadRate() uint64 {
+	maxDownloadRateMu.Lock()
+	defer maxDownloadRateMu.Unlock()
+
+	return maxDownloadRate
+}
+
+// rateLimitedReader limits the read rate with the token bucket. The bucket fills with the rate tokens
+// per second and holds at most one second of tokens, so the idle reader does not burst.
+type rateLimitedReader struct {
+	ctx        context.Context
+	reader     io.Reader
+	rate       float64
+	tokens     float64
+	lastRefill time.Time
+}
+
+func newRateLimitedReader(ctx context.Context, reader io.Reader) io.Reader {
+	rate := currentMaxDownloadRate()
+	if rate == 0 {
+		return reader
+	}
+
+	return &rateLimitedReader{
+		ctx:        ctx,
+		reader:     reader,
+		rate:       float64(rate),
+		lastRefill: time.Now(),
+	}
+}
+
+func (r *rateLimitedReader) refill() {
+	now := time.Now()
+	r.tokens = min(r.tokens+now.Sub(r.lastRefill).Seconds()*r.rate, r.rate)
+	r.lastRefill = now
+}
+
+func (r *rateLimitedReader) Read(p []byte) (int, error) {
+	r.refill()
+	for r.tokens < 1 {
+		wait := time.Duration((1 - r.tokens) / r.rate * float64(time.Second))
+		select {
+		case <-r.ctx.Done():
+			return 0, r.ctx.Err()
+		case <-time.After(wait):
+		}
+		r.refill()
+	}
+
+	if len(p) > int(r.tokens) {
+		p = p[:int(r.tokens)]
+	}
+
+	n, err := r.reader.Read(p)
+	r.tokens -= float64(n)
+
+	return n, err
+}
@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRateLimitedReader(t *testing.T) {
+	tests := []struct {
+		name    string
+		rate    uint64
+		size    int
+		minTime time.Duration
+	}{
+		{name: "no limit", rate: 0, size: 1 << 20, minTime: 0},
+		{name: "1KB per second", rate: 1024, size: 512, minTime: 450 * time.Millisecond},
+		{name: "4KB per second", rate: 4096, size: 4096, minTime: 950 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetMaxDownloadRate(tt.rate)
+			t.Cleanup(func() { SetMaxDownloadRate(0) })
+
+			content := bytes.Repeat([]byte("v"), tt.size)
+			start := time.Now()
+			read, err := io.ReadAll(newRateLimitedReader(context.Background(), bytes.NewReader(content)))
+			elapsed := time.Since(start)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !bytes.Equal(read, content) {
+				t.Errorf("expected %d bytes, got %d", len(content), len(read))
+			}
+			if elapsed < tt.minTime {
+				t.Errorf("expected read to take at least %s, took %s", tt.minTime, elapsed)
+			}
+		})
+	}
+}
+
+func TestRateLimitedReaderCancelled(t *testing.T) {
+	SetMaxDownloadRate(1)
+	t.Cleanup(func() { SetMaxDownloadRate(0) })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := io.ReadAll(newRateLimitedReader(ctx, bytes.NewReader(make([]byte, 1024))))
+	if err != context.DeadlineExceeded {
+		t.Errorf("expected deadline exceeded error, got %v", err)
+	}
+}
+
+func TestThrottledDownloadTakesMinimumTime(t *testing.T) {
+	content := bytes.Repeat([]byte("v"), 1024)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(content)
+	}))
+	defer server.Close()
+
+	SetMaxDownloadRate(2048)
+	t.Cleanup(func() { SetMaxDownloadRate(0) })
+
+	dst := filepath.Join(t.TempDir(), "genesis.json")
+	start := time.Now()
+	if err := DownloadFileContext(context.Background(), server.URL, dst, nil); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if elapsed := time.Since(start); elapsed < 450*time.Millisecond {
+		t.Errorf("expected download to take at least 500ms, took %s", elapsed)
+	}
+
+	downloaded, err := os.ReadFile(dst)
+	if err != nil || !bytes.Equal(downloaded, content) {
+		t.Errorf("expected downloaded content, got %d bytes(%v)", len(downloaded), err)
+	}
+}
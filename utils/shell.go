@@ -2,27 +2,138 @@ package utils
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
 )
 
+// DefaultExecuteTimeout limits the ExecuteBinary, so the hung binary does not block the assistant forever
+const DefaultExecuteTimeout = 10 * time.Minute
+
+// ExecError is returned when the binary fails or does not finish before the context is done. ExitCode
+// is -1 when the process did not exit by itself.
+type ExecError struct {
+	Binary   string
+	Args     []string
+	ExitCode int
+	StdErr   string
+	StdOut   string
+	Err      error
+}
+
+func (e *ExecError) Error() string {
+	return fmt.Sprintf(
+		"failed to execute binary %s %v(exit code: %d) with error: %s, stdout: %s: %s",
+		e.Binary,
+		e.Args,
+		e.ExitCode,
+		e.StdErr,
+		e.StdOut,
+		e.Err.Error(),
+	)
+}
+
+func (e *ExecError) Unwrap() error {
+	return e.Err
+}
+
+// ExecuteBinary executes the binary with the DefaultExecuteTimeout. See the ExecuteBinaryContext.
 func ExecuteBinary(binaryPath string, args []string, v interface{}) ([]byte, error) {
-	command := exec.Command(binaryPath, args...)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultExecuteTimeout)
+	defer cancel()
 
-	var stdOut, stErr bytes.Buffer
-	command.Stdout = &stdOut
-	command.Stderr = &stErr
+	return ExecuteBinaryContext(ctx, nil, binaryPath, args, v)
+}
 
-	if err := command.Run(); err != nil {
-		return nil, fmt.Errorf(
-			"failed to execute binary %s %v with error: %s, stdout: %s: %w",
-			binaryPath,
-			args,
-			stErr.String(),
-			stdOut.String(),
-			err,
-		)
+// ExecuteBinaryContext executes the binary and returns its stdout, or unmarshals the stdout JSON into v
+// when v is not nil. Output lines are logged while the binary runs when logger is not nil. The binary is
+// killed when the ctx is done.
+func ExecuteBinaryContext(
+	ctx context.Context,
+	logger *zap.SugaredLogger,
+	binaryPath string,
+	args []string,
+	v interface{},
+) ([]byte, error) {
+	return executeBinary(ctx, logger, binaryPath, args, nil, v)
+}
+
+// ExecuteBinaryContextEnv executes the binary like the ExecuteBinaryContext, with the env variables
+// added to the assistant environment
+func ExecuteBinaryContextEnv(
+	ctx context.Context,
+	logger *zap.SugaredLogger,
+	binaryPath string,
+	args []string,
+	env []string,
+) ([]byte, error) {
+	return executeBinary(ctx, logger, binaryPath, args, env, nil)
+}
+
+func executeBinary(
+	ctx context.Context,
+	logger *zap.SugaredLogger,
+	binaryPath string,
+	args []string,
+	env []string,
+	v interface{},
+) ([]byte, error) {
+	command := exec.CommandContext(ctx, binaryPath, args...)
+	command.WaitDelay = processWaitDelay
+	if len(env) > 0 {
+		command.Env = append(os.Environ(), env...)
+	}
+
+	var stdOut bytes.Buffer
+	stdOutTail := &tailBuffer{size: outputTailSize}
+	stdErrTail := &tailBuffer{size: outputTailSize}
+	stdOutWriter := io.MultiWriter(&stdOut, stdOutTail)
+	stdErrWriter := io.Writer(stdErrTail)
+	outputLoggers := []*lineLogger{}
+	if logger != nil {
+		stdOutLogger := &lineLogger{logger: logger, prefix: filepath.Base(binaryPath)}
+		stdErrLogger := &lineLogger{logger: logger, prefix: filepath.Base(binaryPath)}
+		outputLoggers = append(outputLoggers, stdOutLogger, stdErrLogger)
+
+		stdOutWriter = io.MultiWriter(stdOutWriter, stdOutLogger)
+		stdErrWriter = io.MultiWriter(stdErrWriter, stdErrLogger)
+	}
+	command.Stdout = stdOutWriter
+	command.Stderr = stdErrWriter
+
+	err := command.Run()
+	for _, outputLogger := range outputLoggers {
+		outputLogger.Flush()
+	}
+	if err != nil {
+		execErr := &ExecError{
+			Binary:   binaryPath,
+			Args:     args,
+			ExitCode: -1,
+			StdErr:   stdErrTail.String(),
+			StdOut:   stdOutTail.String(),
+			Err:      err,
+		}
+
+		exitErr := &exec.ExitError{}
+		if errors.As(err, &exitErr) {
+			execErr.ExitCode = exitErr.ExitCode()
+		}
+		if ctx.Err() != nil {
+			execErr.Err = fmt.Errorf("%w: %w", ctx.Err(), err)
+		}
+
+		return nil, execErr
 	}
 
 	if v == nil {
@@ -40,3 +151,147 @@ func ExecuteBinary(binaryPath string, args []string, v interface{}) ([]byte, err
 
 	return nil, nil
 }
+
+// lineLogger logs every complete output line with the prefix
+type lineLogger struct {
+	logger *zap.SugaredLogger
+	prefix string
+	buffer []byte
+}
+
+func (w *lineLogger) Write(p []byte) (int, error) {
+	w.buffer = append(w.buffer, p...)
+	for {
+		idx := bytes.IndexByte(w.buffer, '\n')
+		if idx < 0 {
+			break
+		}
+
+		w.log(w.buffer[:idx])
+		w.buffer = w.buffer[idx+1:]
+	}
+
+	return len(p), nil
+}
+
+// Flush logs the last line without the new line character
+func (w *lineLogger) Flush() {
+	if len(w.buffer) > 0 {
+		w.log(w.buffer)
+		w.buffer = nil
+	}
+}
+
+func (w *lineLogger) log(line []byte) {
+	if line := strings.TrimRight(string(line), "\r"); strings.TrimSpace(line) != "" {
+		w.logger.Infof("[%s] %s", w.prefix, line)
+	}
+}
+
+const (
+	// outputTailSize is the number of the last output bytes kept for the error reports
+	outputTailSize = 8 * 1024
+	// processWaitDelay limits how long the output is read after the process exits, child processes
+	// may keep the output open
+	processWaitDelay = 5 * time.Second
+)
+
+// tailBuffer keeps the last size bytes written to it. It is safe for the concurrent use.
+type tailBuffer struct {
+	mu   sync.Mutex
+	size int
+	data []byte
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.data = append(b.data, p...)
+	if len(b.data) > b.size {
+		b.data = b.data[len(b.data)-b.size:]
+	}
+
+	return len(p), nil
+}
+
+func (b *tailBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return string(b.data)
+}
+
+// BackgroundProcess is the binary started with the StartBinary
+type BackgroundProcess struct {
+	command *exec.Cmd
+	stdErr  *tailBuffer
+	done    chan struct{}
+	err     error
+}
+
+// StartBinary starts the binary in the background. The tail of the stderr is kept for the error reports.
+func StartBinary(binaryPath string, args []string) (*BackgroundProcess, error) {
+	process := &BackgroundProcess{
+		command: exec.Command(binaryPath, args...),
+		stdErr:  &tailBuffer{size: outputTailSize},
+		done:    make(chan struct{}),
+	}
+	process.command.Stderr = process.stdErr
+	process.command.WaitDelay = processWaitDelay
+
+	if err := process.command.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start binary %s %v: %w", binaryPath, args, err)
+	}
+
+	go func() {
+		process.err = process.command.Wait()
+		close(process.done)
+	}()
+
+	return process, nil
+}
+
+// Done is closed when the process exits
+func (p *BackgroundProcess) Done() <-chan struct{} {
+	return p.done
+}
+
+// Err returns the process exit error. It must be called after the Done channel is closed.
+func (p *BackgroundProcess) Err() error {
+	return p.err
+}
+
+// StdErr returns the tail of the process stderr
+func (p *BackgroundProcess) StdErr() string {
+	return p.stdErr.String()
+}
+
+// Stop interrupts the process and waits until it exits. The process is killed when it does not exit
+// within the timeout.
+func (p *BackgroundProcess) Stop(timeout time.Duration) error {
+	select {
+	case <-p.done:
+		return nil
+	default:
+	}
+
+	if err := p.command.Process.Signal(os.Interrupt); err != nil {
+		if killErr := p.command.Process.Kill(); killErr != nil {
+			return fmt.Errorf("failed to kill process: %w", killErr)
+		}
+	}
+
+	select {
+	case <-p.done:
+		return nil
+	case <-time.After(timeout):
+	}
+
+	if err := p.command.Process.Kill(); err != nil {
+		return fmt.Errorf("failed to kill process after %s: %w", timeout, err)
+	}
+	<-p.done
+
+	return nil
+}
@@ -0,0 +1,601 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml"
+)
+
+// tomlScanState tracks strings and brackets, so values and comments spanning many lines are detected
+type tomlScanState struct {
+	depth     int
+	quote     byte
+	multiline bool
+}
+
+func (s tomlScanState) complete() bool {
+	return s.depth == 0 && s.quote == 0
+}
+
+// scan returns index of the comment, or -1 when there is no comment in the line
+func (s *tomlScanState) scan(line string) int {
+	for idx := 0; idx < len(line); idx++ {
+		char := line[idx]
+		if s.quote != 0 {
+			switch {
+			case s.quote == '"' && char == '\\':
+				idx++
+			case s.multiline && strings.HasPrefix(line[idx:], strings.Repeat(string(s.quote), 3)):
+				s.quote = 0
+				s.multiline = false
+				idx += 2
+			case !s.multiline && char == s.quote:
+				s.quote = 0
+			}
+			continue
+		}
+
+		switch char {
+		case '"', '\'':
+			s.quote = char
+			if strings.HasPrefix(line[idx:], strings.Repeat(string(char), 3)) {
+				s.multiline = true
+				idx += 2
+			}
+		case '[', '{':
+			s.depth++
+		case ']', '}':
+			s.depth--
+		case '#':
+			return idx
+		}
+	}
+
+	return -1
+}
+
+// normalizeTOMLKey turns `"a". b` into `a.b`, so keys and table names can be compared
+func normalizeTOMLKey(key string) string {
+	parts := []string{}
+	current := strings.Builder{}
+	var quote byte
+	for idx := 0; idx < len(key); idx++ {
+		char := key[idx]
+		switch {
+		case quote != 0 && char == quote:
+			quote = 0
+		case quote != 0:
+			current.WriteByte(char)
+		case char == '"' || char == '\'':
+			quote = char
+		case char == '.':
+			parts = append(parts, strings.TrimSpace(current.String()))
+			current.Reset()
+		case char != ' ' && char != '\t':
+			current.WriteByte(char)
+		}
+	}
+
+	return strings.Join(append(parts, strings.TrimSpace(current.String())), ".")
+}
+
+// keyValueSeparator returns index of the `=` outside of the quoted key
+func keyValueSeparator(line string) int {
+	var quote byte
+	for idx := 0; idx < len(line); idx++ {
+		char := line[idx]
+		switch {
+		case quote != 0 && char == quote:
+			quote = 0
+		case quote != 0:
+		case char == '"' || char == '\'':
+			quote = char
+		case char == '=':
+			return idx
+		}
+	}
+
+	return -1
+}
+
+type tomlEntry struct {
+	startLine int
+	endLine   int
+	// prefix is the indent, the key and the `=` with surrounding spaces
+	prefix string
+	// suffix is the space and the comment after the value
+	suffix string
+}
+
+type tomlSection struct {
+	lastLine int
+	indent   string
+}
+
+// tomlDocument keeps lines of the TOML file, so only edited lines are changed
+type tomlDocument struct {
+	lines   []string
+	entries map[string]tomlEntry
+	// sections are the standard tables. The root table has an empty name.
+	sections map[string]*tomlSection
+	// arrayTables are names of the arrays of tables
+	arrayTables map[string]bool
+	// dottedTables are the tables defined with the dotted keys, e.g. `a` for the `a.b = 1`
+	dottedTables map[string]bool
+}
+
+func parseTOMLDocument(content string) (*tomlDocument, error) {
+	doc := &tomlDocument{
+		lines:        strings.Split(content, "\n"),
+		entries:      map[string]tomlEntry{},
+		sections:     map[string]*tomlSection{"": {lastLine: -1}},
+		arrayTables:  map[string]bool{},
+		dottedTables: map[string]bool{},
+	}
+
+	currentTable := ""
+	// Keys in the array of tables are ambiguous, so they are not edited
+	inArrayTable := false
+	for lineIdx := 0; lineIdx < len(doc.lines); lineIdx++ {
+		line := strings.TrimRight(doc.lines[lineIdx], "\r")
+		trimmedLine := strings.TrimSpace(line)
+		if trimmedLine == "" || strings.HasPrefix(trimmedLine, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(trimmedLine, "[") {
+			header := trimmedLine
+			if commentIdx := (&tomlScanState{}).scan(header); commentIdx > -1 {
+				header = strings.TrimSpace(header[:commentIdx])
+			}
+
+			inArrayTable = strings.HasPrefix(header, "[[")
+			currentTable = normalizeTOMLKey(strings.Trim(header, "[]"))
+			if inArrayTable {
+				doc.arrayTables[currentTable] = true
+			} else if doc.hasArrayTableParent(currentTable) {
+				// Sub-tables of the array of tables are repeated for every item
+				inArrayTable = true
+			} else {
+				doc.sections[currentTable] = &tomlSection{lastLine: lineIdx}
+			}
+			continue
+		}
+
+		separatorIdx := keyValueSeparator(line)
+		if separatorIdx < 0 {
+			return nil, fmt.Errorf("invalid toml line %d: %s", lineIdx+1, line)
+		}
+
+		relativeKey := normalizeTOMLKey(line[:separatorIdx])
+		key := relativeKey
+		if currentTable != "" {
+			key = currentTable + "." + relativeKey
+		}
+		if !inArrayTable {
+			for separatorIdx := strings.LastIndex(key, "."); separatorIdx > len(currentTable); separatorIdx = strings.LastIndex(key[:separatorIdx], ".") {
+				doc.dottedTables[key[:separatorIdx]] = true
+			}
+		}
+
+		valueStart := separatorIdx + 1
+		for valueStart < len(line) && (line[valueStart] == ' ' || line[valueStart] == '\t') {
+			valueStart++
+		}
+
+		entry := tomlEntry{
+			startLine: lineIdx,
+			prefix:    line[:valueStart],
+		}
+
+		state := &tomlScanState{}
+		valueLine := line[valueStart:]
+		commentIdx := state.scan(valueLine)
+		for !state.complete() && lineIdx+1 < len(doc.lines) {
+			lineIdx++
+			valueLine = strings.TrimRight(doc.lines[lineIdx], "\r")
+			commentIdx = state.scan(valueLine)
+		}
+		entry.endLine = lineIdx
+
+		if commentIdx > -1 {
+			value := strings.TrimRight(valueLine[:commentIdx], " \t")
+			entry.suffix = valueLine[len(value):]
+		}
+
+		if inArrayTable {
+			continue
+		}
+
+		doc.entries[key] = entry
+		section := doc.sections[currentTable]
+		section.lastLine = entry.endLine
+		section.indent = line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+	}
+
+	return doc, nil
+}
+
+// set replaces the value of existing key, or inserts the key into the closest existing table. Keys inside
+// the inline tables and the arrays of tables are not supported, they cannot be extended with new lines.
+func (doc *tomlDocument) set(key, value string) error {
+	if entry, exists := doc.entries[key]; exists {
+		lineEnding := ""
+		if strings.HasSuffix(doc.lines[entry.endLine], "\r") {
+			lineEnding = "\r"
+		}
+		doc.lines[entry.startLine] = entry.prefix + value + entry.suffix + lineEnding
+		// Removed lines of multiline values are marked with invalid entry, so they can be dropped
+		for lineIdx := entry.startLine + 1; lineIdx <= entry.endLine; lineIdx++ {
+			doc.lines[lineIdx] = removedTOMLLine
+		}
+
+		return nil
+	}
+
+	if _, isTable := doc.sections[key]; isTable || doc.arrayTables[key] || doc.dottedTables[key] {
+		return fmt.Errorf("cannot set value of the %s table", key)
+	}
+	if doc.hasArrayTableParent(key) {
+		return fmt.Errorf("cannot set %s key in the array of tables", key)
+	}
+	for separatorIdx := strings.LastIndex(key, "."); separatorIdx > -1; separatorIdx = strings.LastIndex(key[:separatorIdx], ".") {
+		parent := key[:separatorIdx]
+		if _, isValue := doc.entries[parent]; isValue {
+			return fmt.Errorf("cannot set key in the %s value: it is not a standard table", parent)
+		}
+	}
+
+	tableName, relativeKey := "", key
+	for name := range doc.sections {
+		if name != "" && strings.HasPrefix(key, name+".") && len(name) > len(tableName) {
+			tableName, relativeKey = name, strings.TrimPrefix(key, name+".")
+		}
+	}
+
+	// New table is created for the dotted keys, when there is no matching table. The table defined with the
+	// dotted keys in the root table cannot get the header, so the dotted key is added to the root table.
+	if tableName == "" && strings.Contains(key, ".") && !doc.hasDottedParent(key) {
+		separatorIdx := strings.LastIndex(key, ".")
+		tableName, relativeKey = key[:separatorIdx], key[separatorIdx+1:]
+
+		// Table is added before the trailing new line of the file
+		headerIdx := len(doc.lines)
+		if headerIdx > 0 && doc.lines[headerIdx-1] == "" {
+			headerIdx--
+		}
+		doc.lines = append(doc.lines[:headerIdx], append([]string{"", fmt.Sprintf("[%s]", tableName)}, doc.lines[headerIdx:]...)...)
+		doc.sections[tableName] = &tomlSection{lastLine: headerIdx + 1}
+	}
+
+	section := doc.sections[tableName]
+	newLine := fmt.Sprintf("%s%s = %s", section.indent, relativeKey, value)
+	insertIdx := section.lastLine + 1
+	doc.lines = append(doc.lines[:insertIdx], append([]string{newLine}, doc.lines[insertIdx:]...)...)
+
+	for _, otherSection := range doc.sections {
+		if otherSection.lastLine >= insertIdx {
+			otherSection.lastLine++
+		}
+	}
+	for otherKey, entry := range doc.entries {
+		if entry.startLine >= insertIdx {
+			entry.startLine++
+			entry.endLine++
+			doc.entries[otherKey] = entry
+		}
+	}
+	section.lastLine = insertIdx
+	doc.entries[key] = tomlEntry{startLine: insertIdx, endLine: insertIdx, prefix: fmt.Sprintf("%s%s = ", section.indent, relativeKey)}
+	for separatorIdx := strings.LastIndex(key, "."); separatorIdx > len(tableName); separatorIdx = strings.LastIndex(key[:separatorIdx], ".") {
+		doc.dottedTables[key[:separatorIdx]] = true
+	}
+
+	return nil
+}
+
+func (doc *tomlDocument) hasDottedParent(key string) bool {
+	return hasParent(key, doc.dottedTables)
+}
+
+func (doc *tomlDocument) hasArrayTableParent(key string) bool {
+	return hasParent(key, doc.arrayTables)
+}
+
+// hasParent returns true when any of the tables containing the key is in the tables
+func hasParent(key string, tables map[string]bool) bool {
+	for separatorIdx := strings.LastIndex(key, "."); separatorIdx > -1; separatorIdx = strings.LastIndex(key[:separatorIdx], ".") {
+		if tables[key[:separatorIdx]] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// removedTOMLLine marks continuation lines of replaced multiline values
+const removedTOMLLine = "\x00"
+
+func (doc *tomlDocument) String() string {
+	lines := make([]string, 0, len(doc.lines))
+	for _, line := range doc.lines {
+		if line != removedTOMLLine {
+			lines = append(lines, line)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// encodeTOMLValue returns TOML representation of the scalars, datetimes and arrays
+func encodeTOMLValue(value interface{}) (string, error) {
+	switch typedValue := value.(type) {
+	case time.Time:
+		return typedValue.Format(time.RFC3339Nano), nil
+	case toml.LocalDate, toml.LocalTime, toml.LocalDateTime:
+		return fmt.Sprint(typedValue), nil
+	case string:
+		return encodeTOMLString(typedValue), nil
+	case bool:
+		return strconv.FormatBool(typedValue), nil
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprintf("%d", typedValue), nil
+	case float32:
+		return encodeTOMLFloat(float64(typedValue)), nil
+	case float64:
+		return encodeTOMLFloat(typedValue), nil
+	}
+
+	// Slices of any type, e.g. []string or []interface{}, are written as TOML arrays
+	reflectedValue := reflect.ValueOf(value)
+	if reflectedValue.Kind() != reflect.Slice {
+		return "", fmt.Errorf("unsupported toml value type %T", value)
+	}
+
+	encodedItems := make([]string, reflectedValue.Len())
+	for idx := 0; idx < reflectedValue.Len(); idx++ {
+		encodedItem, err := encodeTOMLValue(reflectedValue.Index(idx).Interface())
+		if err != nil {
+			return "", err
+		}
+		encodedItems[idx] = encodedItem
+	}
+
+	return fmt.Sprintf("[%s]", strings.Join(encodedItems, ", ")), nil
+}
+
+// appendToTOMLArray returns the existing array with new items. Items already in the array are skipped.
+func appendToTOMLArray(oldValue interface{}, newItems ConfigArrayAppend) ([]interface{}, error) {
+	result := []interface{}{}
+	if oldValue != nil {
+		oldItems, isArray := oldValue.([]interface{})
+		if !isArray {
+			return nil, fmt.Errorf("cannot append to %T value: existing value is not an array", oldValue)
+		}
+		result = append(result, oldItems...)
+	}
+
+	for _, newItem := range newItems {
+		exists := false
+		for _, item := range result {
+			if fmt.Sprint(item) == fmt.Sprint(newItem) {
+				exists = true
+				break
+			}
+		}
+
+		if !exists {
+			result = append(result, newItem)
+		}
+	}
+
+	return result, nil
+}
+
+func encodeTOMLFloat(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "inf"
+	case math.IsInf(value, -1):
+		return "-inf"
+	case math.IsNaN(value):
+		return "nan"
+	}
+
+	result := strconv.FormatFloat(value, 'f', -1, 64)
+	if !strings.Contains(result, ".") {
+		result += ".0"
+	}
+
+	return result
+}
+
+func encodeTOMLString(value string) string {
+	result := strings.Builder{}
+	result.WriteByte('"')
+	for _, char := range value {
+		switch char {
+		case '"':
+			result.WriteString(`\"`)
+		case '\\':
+			result.WriteString(`\\`)
+		case '\b':
+			result.WriteString(`\b`)
+		case '\t':
+			result.WriteString(`\t`)
+		case '\n':
+			result.WriteString(`\n`)
+		case '\f':
+			result.WriteString(`\f`)
+		case '\r':
+			result.WriteString(`\r`)
+		default:
+			if char < 0x20 || char == 0x7f {
+				result.WriteString(fmt.Sprintf(`\u%04X`, char))
+				continue
+			}
+			result.WriteRune(char)
+		}
+	}
+	result.WriteByte('"')
+
+	return result.String()
+}
+
+// updateTOMLConfig edits only lines of the updated keys, so comments and formatting of the file are preserved
+func updateTOMLConfig(filePath string, newValues map[string]interface{}) ([]ConfigChange, error) {
+	fileInfo, err := os.Stat(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat %s config file: %w", filePath, err)
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s config file: %w", filePath, err)
+	}
+
+	oldTree, err := toml.LoadBytes(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s config file: %w", filePath, err)
+	}
+
+	doc, err := parseTOMLDocument(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s config file: %w", filePath, err)
+	}
+
+	keys := make([]string, 0, len(newValues))
+	for key := range newValues {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	changes := []ConfigChange{}
+	for _, key := range keys {
+		newValue := newValues[key]
+		if appendedItems, isAppend := newValue.(ConfigArrayAppend); isAppend {
+			mergedItems, err := appendToTOMLArray(oldTree.Get(key), appendedItems)
+			if err != nil {
+				return nil, fmt.Errorf("failed to append to %s parameter in the %s file: %w", key, filePath, err)
+			}
+			newValue = mergedItems
+		}
+
+		encodedValue, err := encodeTOMLValue(newValue)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"failed to update value for %s parameter in the %s file: %w",
+				key,
+				filePath,
+				err,
+			)
+		}
+
+		change := ConfigChange{
+			Key:      key,
+			NewValue: newValue,
+			Created:  !oldTree.Has(key),
+		}
+		if !change.Created {
+			change.OldValue = oldTree.Get(key)
+		}
+
+		if err := doc.set(key, encodedValue); err != nil {
+			return nil, fmt.Errorf("failed to update value for %s parameter in the %s file: %w", key, filePath, err)
+		}
+		changes = append(changes, change)
+	}
+
+	updatedContent := doc.String()
+	if _, err := toml.Load(updatedContent); err != nil {
+		return nil, fmt.Errorf("updated config %s is not valid toml: %w", filePath, err)
+	}
+
+	if err := os.WriteFile(filePath, []byte(updatedContent), fileInfo.Mode().Perm()); err != nil {
+		return nil, fmt.Errorf("failed to write updated config to file %s: %w", filePath, err)
+	}
+
+	return changes, nil
+}
+
+// VerifyTOMLConfig re-reads the config file and checks keys have expected values. Values are compared by
+// their TOML representation, so e.g. the int written as a string is reported.
+func VerifyTOMLConfig(filePath string, expectedValues map[string]interface{}) error {
+	tree, err := toml.LoadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("config %s is not valid toml after update: %w", filePath, err)
+	}
+
+	keys := make([]string, 0, len(expectedValues))
+	for key := range expectedValues {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if !tree.Has(key) {
+			return fmt.Errorf("config %s verification failed: key %s is missing", filePath, key)
+		}
+
+		actualValue, err := encodeTOMLValue(tree.Get(key))
+		if err != nil {
+			return fmt.Errorf("config %s verification failed: key %s: %w", filePath, key, err)
+		}
+
+		expectedItems, isAppend := expectedValues[key].(ConfigArrayAppend)
+		if !isAppend {
+			expectedItems = ConfigArrayAppend{expectedValues[key]}
+		}
+
+		for _, expectedItem := range expectedItems {
+			expectedValue, err := encodeTOMLValue(expectedItem)
+			if err != nil {
+				return fmt.Errorf("config %s verification failed: key %s: %w", filePath, key, err)
+			}
+
+			if isAppend && arrayContainsTOMLValue(tree.Get(key), expectedValue) {
+				continue
+			}
+
+			if !isAppend && actualValue == expectedValue {
+				continue
+			}
+
+			if isSecretConfigKey(key) {
+				return fmt.Errorf("config %s verification failed: key %s has unexpected value", filePath, key)
+			}
+
+			return fmt.Errorf(
+				"config %s verification failed: key %s has value %s, expected %s",
+				filePath,
+				key,
+				actualValue,
+				expectedValue,
+			)
+		}
+	}
+
+	return nil
+}
+
+func arrayContainsTOMLValue(array interface{}, expectedValue string) bool {
+	items, isArray := array.([]interface{})
+	if !isArray {
+		return false
+	}
+
+	for _, item := range items {
+		if encodedItem, err := encodeTOMLValue(item); err == nil && encodedItem == expectedValue {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,344 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pelletier/go-toml"
+)
+
+const commentedTOMLConfig = `# Main config of the node
+moniker = "node" # the node name
+
+[p2p]
+  # Comma separated list of seed nodes
+  seeds = "a@seed0:26656,b@seed1:26656"
+  pex = true
+
+  [p2p.limits]
+    max-peers = 40 # inbound and outbound
+
+[rpc]
+  laddr = """tcp://
+0.0.0.0:26657"""
+`
+
+func writeTOMLConfig(t *testing.T, content string) string {
+	t.Helper()
+
+	filePath := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(filePath, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return filePath
+}
+
+func TestUpdateTOMLConfigPreservesOtherLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   map[string]interface{}
+		expected string
+	}{
+		{
+			name:     "root key with comment",
+			values:   map[string]interface{}{"moniker": "validator"},
+			expected: strings.Replace(commentedTOMLConfig, `moniker = "node" #`, `moniker = "validator" #`, 1),
+		},
+		{
+			name:     "table key",
+			values:   map[string]interface{}{"p2p.pex": false},
+			expected: strings.Replace(commentedTOMLConfig, "pex = true", "pex = false", 1),
+		},
+		{
+			name:     "nested table key with comment",
+			values:   map[string]interface{}{"p2p.limits.max-peers": 10},
+			expected: strings.Replace(commentedTOMLConfig, "max-peers = 40 #", "max-peers = 10 #", 1),
+		},
+		{
+			name:     "multiline value",
+			values:   map[string]interface{}{"rpc.laddr": "tcp://127.0.0.1:26657"},
+			expected: strings.Replace(commentedTOMLConfig, "laddr = \"\"\"tcp://\n0.0.0.0:26657\"\"\"", `laddr = "tcp://127.0.0.1:26657"`, 1),
+		},
+		{
+			name:     "new key in existing table",
+			values:   map[string]interface{}{"p2p.limits.max-inbound": 20},
+			expected: strings.Replace(commentedTOMLConfig, "max-peers = 40 # inbound and outbound\n", "max-peers = 40 # inbound and outbound\n    max-inbound = 20\n", 1),
+		},
+		{
+			name:     "new table",
+			values:   map[string]interface{}{"statesync.enable": true},
+			expected: commentedTOMLConfig + "\n[statesync]\nenable = true\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filePath := writeTOMLConfig(t, commentedTOMLConfig)
+			if _, err := updateTOMLConfig(filePath, tt.values); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			content, err := os.ReadFile(filePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(content) != tt.expected {
+				t.Errorf("expected config:\n%s\ngot:\n%s", tt.expected, content)
+			}
+		})
+	}
+}
+
+func TestUpdateTOMLConfigPreservesCRLF(t *testing.T) {
+	config := "# comment\r\n[p2p]\r\nseeds = \"a\" # seeds\r\npex = true\r\n"
+	filePath := writeTOMLConfig(t, config)
+	if _, err := updateTOMLConfig(filePath, map[string]interface{}{"p2p.seeds": "b"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := strings.Replace(config, `seeds = "a"`, `seeds = "b"`, 1); string(content) != expected {
+		t.Errorf("expected config %q, got %q", expected, content)
+	}
+}
+
+func TestUpdateTOMLConfigDottedKeys(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   string
+		values   map[string]interface{}
+		expected string
+		wantErr  string
+	}{
+		{
+			name:     "key added next to the dotted keys",
+			config:   "name = \"node\"\nlimits.peers = 10\n\n[p2p]\npex = true\n",
+			values:   map[string]interface{}{"limits.inbound": 5},
+			expected: "name = \"node\"\nlimits.peers = 10\nlimits.inbound = 5\n\n[p2p]\npex = true\n",
+		},
+		{
+			name:     "dotted key added in the table",
+			config:   "[p2p]\npex = true\n",
+			values:   map[string]interface{}{"p2p.limits.peers": 10},
+			expected: "[p2p]\npex = true\nlimits.peers = 10\n",
+		},
+		{
+			name:    "key in the inline table",
+			config:  "limits = { peers = 10 }\n",
+			values:  map[string]interface{}{"limits.inbound": 5},
+			wantErr: "not a standard table",
+		},
+		{
+			name:    "existing key in the inline table",
+			config:  "limits = { peers = 10 }\n",
+			values:  map[string]interface{}{"limits.peers": 5},
+			wantErr: "not a standard table",
+		},
+		{
+			name:    "key in the array of tables",
+			config:  "[[servers]]\nname = \"a\"\n\n[[servers]]\nname = \"b\"\n",
+			values:  map[string]interface{}{"servers.name": "c"},
+			wantErr: "array of tables",
+		},
+		{
+			name:    "key in the sub-table of the array of tables",
+			config:  "[[servers]]\nname = \"a\"\n\n[servers.tls]\nenabled = true\n",
+			values:  map[string]interface{}{"servers.tls.enabled": false},
+			wantErr: "array of tables",
+		},
+		{
+			name:    "table replaced with value",
+			config:  "[p2p]\npex = true\n",
+			values:  map[string]interface{}{"p2p": "value"},
+			wantErr: "cannot set value of the p2p table",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filePath := writeTOMLConfig(t, tt.config)
+			_, err := updateTOMLConfig(filePath, tt.values)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected %q error, got %v", tt.wantErr, err)
+				}
+
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			content, err := os.ReadFile(filePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(content) != tt.expected {
+				t.Errorf("expected config:\n%s\ngot:\n%s", tt.expected, content)
+			}
+		})
+	}
+}
+
+func TestUpdateTOMLConfigArrays(t *testing.T) {
+	const config = "[p2p]\n  seeds = [\"a@seed0:26656\"] # seed nodes\n  pex = true\n"
+	tests := []struct {
+		name     string
+		values   map[string]interface{}
+		expected string
+	}{
+		{
+			name:     "set array",
+			values:   map[string]interface{}{"p2p.seeds": []string{"b@seed1:26656", "c@seed2:26656"}},
+			expected: "[p2p]\n  seeds = [\"b@seed1:26656\", \"c@seed2:26656\"] # seed nodes\n  pex = true\n",
+		},
+		{
+			name:     "append to array",
+			values:   map[string]interface{}{"p2p.seeds": AppendToArray("a@seed0:26656", "b@seed1:26656")},
+			expected: "[p2p]\n  seeds = [\"a@seed0:26656\", \"b@seed1:26656\"] # seed nodes\n  pex = true\n",
+		},
+		{
+			name:     "append to missing array",
+			values:   map[string]interface{}{"p2p.peers": AppendToArray("b@peer0:26656")},
+			expected: "[p2p]\n  seeds = [\"a@seed0:26656\"] # seed nodes\n  pex = true\n  peers = [\"b@peer0:26656\"]\n",
+		},
+		{
+			name:     "set mixed array",
+			values:   map[string]interface{}{"p2p.ports": []interface{}{int64(26656), "26657"}},
+			expected: "[p2p]\n  seeds = [\"a@seed0:26656\"] # seed nodes\n  pex = true\n  ports = [26656, \"26657\"]\n",
+		},
+		{
+			name:     "scalar unchanged",
+			values:   map[string]interface{}{"p2p.pex": true},
+			expected: config,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filePath := writeTOMLConfig(t, config)
+			if _, err := updateTOMLConfig(filePath, tt.values); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			content, err := os.ReadFile(filePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(content) != tt.expected {
+				t.Errorf("expected config:\n%s\ngot:\n%s", tt.expected, content)
+			}
+
+			if err := VerifyTOMLConfig(filePath, tt.values); err != nil {
+				t.Errorf("verification failed: %s", err)
+			}
+		})
+	}
+}
+
+func TestUpdateTOMLConfigAppendToScalar(t *testing.T) {
+	filePath := writeTOMLConfig(t, "[p2p]\nseeds = \"a@seed0:26656\"\n")
+
+	_, err := updateTOMLConfig(filePath, map[string]interface{}{"p2p.seeds": AppendToArray("b@seed1:26656")})
+	if err == nil || !strings.Contains(err.Error(), "existing value is not an array") {
+		t.Errorf("expected append error, got %v", err)
+	}
+}
+
+func TestVerifyTOMLConfig(t *testing.T) {
+	const config = `name = "node"
+port = 26656
+seeds = ["a@seed0:26656", "b@seed1:26656"]
+started = 1979-05-27T07:32:00.5-07:00
+local = 1979-05-27T07:32:00
+at = 07:32:00
+password = "secret"
+`
+	started, err := time.Parse(time.RFC3339, "1979-05-27T07:32:00.5-07:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		values  map[string]interface{}
+		wantErr string
+	}{
+		{name: "scalars", values: map[string]interface{}{"name": "node", "port": 26656}},
+		{name: "array", values: map[string]interface{}{"seeds": []string{"a@seed0:26656", "b@seed1:26656"}}},
+		{name: "appended items", values: map[string]interface{}{"seeds": AppendToArray("b@seed1:26656")}},
+		{name: "offset datetime", values: map[string]interface{}{"started": started}},
+		{name: "existing local datetimes", values: map[string]interface{}{"name": "node"}},
+		{
+			name: "local datetimes",
+			values: map[string]interface{}{
+				"local": toml.LocalDateTime{Date: toml.LocalDate{Year: 1979, Month: 5, Day: 27}, Time: toml.LocalTime{Hour: 7, Minute: 32}},
+				"at":    toml.LocalTime{Hour: 7, Minute: 32},
+			},
+		},
+		{name: "datetime mismatch", values: map[string]interface{}{"started": started.Add(time.Hour)}, wantErr: "key started has value"},
+		{name: "int written as string", values: map[string]interface{}{"port": "26656"}, wantErr: `key port has value 26656, expected "26656"`},
+		{name: "missing key", values: map[string]interface{}{"moniker": "node"}, wantErr: "key moniker is missing"},
+		{name: "missing appended item", values: map[string]interface{}{"seeds": AppendToArray("c@seed2:26656")}, wantErr: "key seeds has value"},
+		{name: "secret value is not reported", values: map[string]interface{}{"password": "other"}, wantErr: "key password has unexpected value"},
+	}
+
+	filePath := writeTOMLConfig(t, config)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifyTOMLConfig(filePath, tt.values)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %s", err)
+				}
+
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected %q error, got %v", tt.wantErr, err)
+			}
+			if err != nil && strings.Contains(err.Error(), "secret") {
+				t.Errorf("error contains the secret value: %s", err)
+			}
+		})
+	}
+}
+
+func TestEncodeTOMLValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    interface{}
+		expected string
+		wantErr  bool
+	}{
+		{name: "string", value: "a \"quoted\"\n", expected: `"a \"quoted\"\n"`},
+		{name: "int", value: 40, expected: "40"},
+		{name: "float", value: 1.0, expected: "1.0"},
+		{name: "bool", value: true, expected: "true"},
+		{name: "string array", value: []string{"a", "b"}, expected: `["a", "b"]`},
+		{name: "empty array", value: []interface{}{}, expected: "[]"},
+		{name: "offset datetime", value: time.Date(1979, 5, 27, 7, 32, 0, 0, time.UTC), expected: "1979-05-27T07:32:00Z"},
+		{name: "local date", value: toml.LocalDate{Year: 1979, Month: 5, Day: 27}, expected: "1979-05-27"},
+		{name: "map", value: map[string]string{"a": "b"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := encodeTOMLValue(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error: %t, got %v", tt.wantErr, err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
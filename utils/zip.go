@@ -1,58 +0,0 @@
-package utils
-
-import (
-	"archive/zip"
-	"fmt"
-	"io"
-	"os"
-	"path/filepath"
-	"strings"
-)
-
-func Unzip(archiveFilePath, dst string) error {
-	archive, err := zip.OpenReader(archiveFilePath)
-	if err != nil {
-		return fmt.Errorf("failed to open zip archive: %w", err)
-	}
-	defer archive.Close()
-
-	for _, f := range archive.File {
-		filePath := filepath.Join(dst, f.Name)
-
-		if !strings.HasPrefix(filePath, filepath.Clean(dst)+string(os.PathSeparator)) {
-			return fmt.Errorf(
-				"cannot unarchive file that destination is outside of the output directory",
-			)
-		}
-		if f.FileInfo().IsDir() {
-			err := os.MkdirAll(filePath, os.ModePerm)
-			if err != nil {
-				return fmt.Errorf("failed to create directory %s: %w", filePath, err)
-			}
-			continue
-		}
-
-		if err := os.MkdirAll(filepath.Dir(filePath), os.ModePerm); err != nil {
-			return fmt.Errorf("failed to make parent dif for file %s: %w", filePath, err)
-		}
-
-		dstFile, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, f.Mode())
-		if err != nil {
-			return fmt.Errorf("failed to open buffer for file %s: %w", filePath, err)
-		}
-
-		fileInArchive, err := f.Open()
-		if err != nil {
-			return fmt.Errorf("failed to open file in the archive: %w", err)
-		}
-
-		if _, err := io.Copy(dstFile, fileInArchive); err != nil {
-			return fmt.Errorf("failed to copy file content from archive to output file: %w", err)
-		}
-
-		dstFile.Close()
-		fileInArchive.Close()
-	}
-
-	return nil
-}
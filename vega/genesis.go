@@ -0,0 +1,65 @@
+package vega
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+)
+
+type genesisDocument struct {
+	ChainID  string          `json:"chain_id"`
+	AppState json.RawMessage `json:"app_state"`
+}
+
+// ValidateGenesis checks if the genesis file is a valid JSON document for the given chain
+func ValidateGenesis(genesisFilePath, expectedChainID string) error {
+	content, err := os.ReadFile(genesisFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to read genesis file %s: %w", genesisFilePath, err)
+	}
+
+	genesis := genesisDocument{}
+	if err := json.Unmarshal(content, &genesis); err != nil {
+		return fmt.Errorf("genesis file %s is not a valid JSON: %w", genesisFilePath, err)
+	}
+
+	if genesis.ChainID == "" {
+		return fmt.Errorf("genesis file %s does not contain chain_id", genesisFilePath)
+	}
+
+	if len(genesis.AppState) == 0 || string(genesis.AppState) == "null" {
+		return fmt.Errorf("genesis file %s does not contain app_state", genesisFilePath)
+	}
+
+	if genesis.ChainID != expectedChainID {
+		return fmt.Errorf(
+			"genesis chain_id mismatch: expected %s, got %s in %s",
+			expectedChainID,
+			genesis.ChainID,
+			genesisFilePath,
+		)
+	}
+
+	return nil
+}
+
+var chainIDPattern = regexp.MustCompile(`^vega-[a-z0-9]+(-[a-z0-9]+)*-[0-9]+$`)
+
+// ValidateChainID checks the chain id matches the vega-<network>-<number> pattern, e.g. vega-mainnet-0011.
+// The knownChainID is accepted as it is, so networks with custom chain ids can be configured.
+func ValidateChainID(chainID, knownChainID string) error {
+	if chainID == "" {
+		return fmt.Errorf("chain id must not be empty")
+	}
+
+	if chainID == knownChainID {
+		return nil
+	}
+
+	if !chainIDPattern.MatchString(chainID) {
+		return fmt.Errorf("invalid chain id(%s): expected vega-<network>-<number>, e.g. vega-mainnet-0011", chainID)
+	}
+
+	return nil
+}
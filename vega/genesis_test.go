@@ -0,0 +1,38 @@
+package vega
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateGenesis(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		wantErr string
+	}{
+		{name: "valid genesis", file: "genesis.json"},
+		{name: "wrong chain_id", file: "genesis-other-chain.json", wantErr: "genesis chain_id mismatch"},
+		{name: "missing app_state", file: "genesis-no-app-state.json", wantErr: "does not contain app_state"},
+		{name: "invalid json", file: "genesis-invalid.json", wantErr: "is not a valid JSON"},
+		{name: "missing file", file: "genesis-missing.json", wantErr: "failed to read genesis file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateGenesis(filepath.Join("testdata", tt.file), "vega-mainnet-0011")
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %s", err)
+				}
+
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected %q error, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -302,7 +302,7 @@ func getStatistics(ctx context.Context, httpClient *http.Client, restURL string)
 }
 
 func newDefaultHTTPClient() *http.Client {
-	return http.DefaultClient
+	return utils.HTTPClient()
 }
 
 func (n *NetworkAPI) httpCall(req *http.Request, result any) error {
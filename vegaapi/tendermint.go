@@ -0,0 +1,65 @@
+package vegaapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/daniel1302/vega-assistant/types"
+	"github.com/hashicorp/go-multierror"
+)
+
+// EvidenceMaxAge returns the evidence max age duration from the chain consensus params. Tendermint
+// uses it as the unbonding window, so the statesync trust period must be shorter.
+func (n *NetworkAPI) EvidenceMaxAge(ctx context.Context, rpcServers []string) (time.Duration, error) {
+	if len(rpcServers) < 1 {
+		return 0, fmt.Errorf("failed to get consensus params: no tendermint rpc server available")
+	}
+
+	var resErr error
+	for _, rpcServer := range rpcServers {
+		maxAge, err := n.getEvidenceMaxAge(ctx, rpcServer)
+		if err != nil {
+			resErr = multierror.Append(resErr, err)
+			continue
+		}
+
+		return maxAge, nil
+	}
+
+	return 0, resErr
+}
+
+func (n *NetworkAPI) getEvidenceMaxAge(ctx context.Context, rpcServer string) (time.Duration, error) {
+	if !strings.HasPrefix(rpcServer, "http://") && !strings.HasPrefix(rpcServer, "https://") {
+		rpcServer = fmt.Sprintf("http://%s", rpcServer)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/consensus_params", rpcServer), nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create consensus params request for %s: %w", rpcServer, err)
+	}
+
+	result := types.TendermintConsensusParams{}
+	if err := n.httpCall(req, &result); err != nil {
+		return 0, fmt.Errorf("failed to get consensus params from %s: %w", rpcServer, err)
+	}
+
+	// Duration is returned as the number of nanoseconds
+	maxAge, err := strconv.ParseInt(result.Result.ConsensusParams.Evidence.MaxAgeDuration, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse evidence max age duration from %s: %w", rpcServer, err)
+	}
+
+	if maxAge <= 0 {
+		return 0, fmt.Errorf("invalid evidence max age duration from %s: %d", rpcServer, maxAge)
+	}
+
+	return time.Duration(maxAge), nil
+}
@@ -1,6 +1,15 @@
 package vegacmd
 
-import "path/filepath"
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/daniel1302/vega-assistant/utils"
+)
 
 type VegaNodeMode string
 
@@ -10,6 +19,11 @@ const (
 	VegaNodeSeed      VegaNodeMode = "seed"
 )
 
+// AvailableVegaNodeModes returns node types accepted by the vega init command
+func AvailableVegaNodeModes() []VegaNodeMode {
+	return []VegaNodeMode{VegaNodeFull, VegaNodeValidator, VegaNodeSeed}
+}
+
 var (
 	CoreConfigPath      = filepath.Join("config", "node", "config.toml")
 	DataNodeConfigPath  = filepath.Join("config", "data-node", "config.toml")
@@ -18,6 +32,30 @@ var (
 	GenesisPath         = filepath.Join("config", "genesis.json")
 )
 
+// versionTimeout limits the version command, it must respond immediately
+const versionTimeout = 30 * time.Second
+
+var versionRegex = regexp.MustCompile(`v?\d+\.\d+\.\d+[0-9A-Za-z\.\-\+]*`)
+
+// BinaryVersion returns version reported by the `version` command of the vega or the visor binary
 func BinaryVersion(binary string) (string, error) {
-	return "", nil
+	ctx, cancel := context.WithTimeout(context.Background(), versionTimeout)
+	defer cancel()
+
+	output, err := utils.ExecuteBinaryContext(ctx, nil, binary, []string{"version"}, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to execute version command: %w", err)
+	}
+
+	version := versionRegex.FindString(string(output))
+	if version == "" {
+		return "", fmt.Errorf("failed to find version in the version command output: %s", output)
+	}
+
+	return version, nil
+}
+
+// VersionsMatch compares versions ignoring the leading `v`
+func VersionsMatch(version1, version2 string) bool {
+	return strings.TrimPrefix(version1, "v") == strings.TrimPrefix(version2, "v")
 }
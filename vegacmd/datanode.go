@@ -1,13 +1,21 @@
 package vegacmd
 
 import (
+	"context"
 	"fmt"
 
+	"go.uber.org/zap"
+
 	"github.com/daniel1302/vega-assistant/utils"
 )
 
-func InitDataNode(binaryPath, vegaHome string, chainId string) error {
-	_, err := utils.ExecuteBinary(
+func InitDataNode(ctx context.Context, logger *zap.SugaredLogger, binaryPath, vegaHome string, chainId string) error {
+	ctx, cancel := context.WithTimeout(ctx, utils.DefaultExecuteTimeout)
+	defer cancel()
+
+	_, err := utils.ExecuteBinaryContext(
+		ctx,
+		logger,
 		binaryPath,
 		[]string{"datanode", "init", "--home", vegaHome, chainId},
 		nil,
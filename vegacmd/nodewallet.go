@@ -0,0 +1,148 @@
+package vegacmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/daniel1302/vega-assistant/utils"
+)
+
+type NodeWalletChain string
+
+const (
+	NodeWalletChainVega       NodeWalletChain = "vega"
+	NodeWalletChainEthereum   NodeWalletChain = "ethereum"
+	NodeWalletChainTendermint NodeWalletChain = "tendermint"
+)
+
+// TendermintPrivValidatorKeyPath is the validator key generated by the tendermint init
+var TendermintPrivValidatorKeyPath = filepath.Join("config", "priv_validator_key.json")
+
+// InitVegaValidator initializes the vega home for the validator. The node wallets registry is
+// encrypted with the passphrase from the nodeWalletPassphraseFile.
+func InitVegaValidator(ctx context.Context, binaryPath, vegaHome, nodeWalletPassphraseFile string) error {
+	err := executeNodeWalletCommand(
+		ctx,
+		binaryPath,
+		[]string{
+			"init",
+			"--output", "json",
+			"--home", vegaHome,
+			"--nodewallet-passphrase-file", nodeWalletPassphraseFile,
+			string(VegaNodeValidator),
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to init vega validator: %w", err)
+	}
+
+	return nil
+}
+
+// GenerateNodeWallet generates new vega or ethereum node wallet protected with the passphrase from
+// the walletPassphraseFile
+func GenerateNodeWallet(
+	ctx context.Context,
+	binaryPath, vegaHome, nodeWalletPassphraseFile string,
+	chain NodeWalletChain,
+	walletPassphraseFile string,
+) error {
+	err := executeNodeWalletCommand(
+		ctx,
+		binaryPath,
+		[]string{
+			"nodewallet", "generate",
+			"--output", "json",
+			"--home", vegaHome,
+			"--passphrase-file", nodeWalletPassphraseFile,
+			"--chain", string(chain),
+			"--wallet-passphrase-file", walletPassphraseFile,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to generate %s node wallet: %w", chain, err)
+	}
+
+	return nil
+}
+
+// ImportNodeWallet imports the existing vega or ethereum wallet file into the node wallets
+func ImportNodeWallet(
+	ctx context.Context,
+	binaryPath, vegaHome, nodeWalletPassphraseFile string,
+	chain NodeWalletChain,
+	walletPath, walletPassphraseFile string,
+) error {
+	err := executeNodeWalletCommand(
+		ctx,
+		binaryPath,
+		[]string{
+			"nodewallet", "import",
+			"--output", "json",
+			"--home", vegaHome,
+			"--passphrase-file", nodeWalletPassphraseFile,
+			"--chain", string(chain),
+			"--wallet-path", walletPath,
+			"--wallet-passphrase-file", walletPassphraseFile,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to import %s node wallet: %w", chain, err)
+	}
+
+	return nil
+}
+
+// ImportEthereumClefWallet registers the ethereum key kept in the clef signer
+func ImportEthereumClefWallet(ctx context.Context, binaryPath, vegaHome, nodeWalletPassphraseFile, clefAddress string) error {
+	err := executeNodeWalletCommand(
+		ctx,
+		binaryPath,
+		[]string{
+			"nodewallet", "import",
+			"--output", "json",
+			"--home", vegaHome,
+			"--passphrase-file", nodeWalletPassphraseFile,
+			"--chain", string(NodeWalletChainEthereum),
+			"--eth.clef-address", clefAddress,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to import ethereum clef wallet: %w", err)
+	}
+
+	return nil
+}
+
+// ImportTendermintNodeWallet registers the tendermint validator key from the tendermint home
+func ImportTendermintNodeWallet(ctx context.Context, binaryPath, vegaHome, nodeWalletPassphraseFile, tendermintHome string) error {
+	err := executeNodeWalletCommand(
+		ctx,
+		binaryPath,
+		[]string{
+			"nodewallet", "import",
+			"--output", "json",
+			"--home", vegaHome,
+			"--passphrase-file", nodeWalletPassphraseFile,
+			"--chain", string(NodeWalletChainTendermint),
+			"--tendermint-home", tendermintHome,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to import tendermint node wallet: %w", err)
+	}
+
+	return nil
+}
+
+// executeNodeWalletCommand runs the vega command with the DefaultExecuteTimeout. The output is not logged,
+// it may contain the wallet details.
+func executeNodeWalletCommand(ctx context.Context, binaryPath string, args []string) error {
+	ctx, cancel := context.WithTimeout(ctx, utils.DefaultExecuteTimeout)
+	defer cancel()
+
+	_, err := utils.ExecuteBinaryContext(ctx, nil, binaryPath, args, nil)
+
+	return err
+}
@@ -1,13 +1,19 @@
 package vegacmd
 
 import (
+	"context"
 	"fmt"
 
+	"go.uber.org/zap"
+
 	"github.com/daniel1302/vega-assistant/utils"
 )
 
-func InitTendermint(binaryPath, tendermintHome string) error {
-	_, err := utils.ExecuteBinary(binaryPath, []string{"tm", "init", "--home", tendermintHome}, nil)
+func InitTendermint(ctx context.Context, logger *zap.SugaredLogger, binaryPath, tendermintHome string) error {
+	ctx, cancel := context.WithTimeout(ctx, utils.DefaultExecuteTimeout)
+	defer cancel()
+
+	_, err := utils.ExecuteBinaryContext(ctx, logger, binaryPath, []string{"tm", "init", "--home", tendermintHome}, nil)
 	if err != nil {
 		return fmt.Errorf("failed to init tendermint: %w", err)
 	}
@@ -1,13 +1,21 @@
 package vegacmd
 
 import (
+	"context"
 	"fmt"
 
+	"go.uber.org/zap"
+
 	"github.com/daniel1302/vega-assistant/utils"
 )
 
-func InitVega(binaryPath, vegaHome string, nodeMode VegaNodeMode) error {
-	_, err := utils.ExecuteBinary(
+func InitVega(ctx context.Context, logger *zap.SugaredLogger, binaryPath, vegaHome string, nodeMode VegaNodeMode) error {
+	ctx, cancel := context.WithTimeout(ctx, utils.DefaultExecuteTimeout)
+	defer cancel()
+
+	_, err := utils.ExecuteBinaryContext(
+		ctx,
+		logger,
 		binaryPath,
 		[]string{"init", "--output", "json", "--home", vegaHome, string(nodeMode)},
 		nil,
@@ -2,9 +2,12 @@ package vegacmd
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"html/template"
 
+	"go.uber.org/zap"
+
 	"github.com/daniel1302/vega-assistant/utils"
 )
 
@@ -18,13 +21,19 @@ const VisorRunConfigTemplate = `name = "{{.Version}}"
     socketPath = "/tmp/vega.sock"
     httpPath = "/rpc"
 
+{{- if .DataNodeHome}}
+
 [data_node]
   [data_node.binary]
     path = "vega"
-    args = ["datanode", "start", "--home", "{{.VegaHome}}"]`
+    args = ["datanode", "start", "--home", "{{.DataNodeHome}}"]
+{{- end}}`
+
+func InitVisor(ctx context.Context, logger *zap.SugaredLogger, binaryPath, visorHome string) error {
+	ctx, cancel := context.WithTimeout(ctx, utils.DefaultExecuteTimeout)
+	defer cancel()
 
-func InitVisor(binaryPath, visorHome string) error {
-	_, err := utils.ExecuteBinary(binaryPath, []string{"init", "--home", visorHome}, nil)
+	_, err := utils.ExecuteBinaryContext(ctx, logger, binaryPath, []string{"init", "--home", visorHome}, nil)
 	if err != nil {
 		return fmt.Errorf("failed to init vegavisor: %w", err)
 	}
@@ -32,17 +41,22 @@ func InitVisor(binaryPath, visorHome string) error {
 	return nil
 }
 
-func TemplateVisorRunConfig(version, vegaHome, tendermintHome string) (string, error) {
+// TemplateVisorRunConfig returns the run-config.toml content. The data-node process is added only
+// when dataNodeHome is not empty. The run config describes only the processes, the auto upgrade is set
+// with the autoInstall section of the vegavisor config.toml.
+func TemplateVisorRunConfig(version, vegaHome, tendermintHome, dataNodeHome string) (string, error) {
 	tmpl := template.Must(template.New("run-config.toml").Parse(VisorRunConfigTemplate))
 	var buff bytes.Buffer
 	if err := tmpl.Execute(&buff, struct {
 		Version        string
 		VegaHome       string
 		TendermintHome string
+		DataNodeHome   string
 	}{
 		Version:        version,
 		VegaHome:       vegaHome,
 		TendermintHome: tendermintHome,
+		DataNodeHome:   dataNodeHome,
 	}); err != nil {
 		return "", fmt.Errorf("failed to template run-config.toml: %w", err)
 	}
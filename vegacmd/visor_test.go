@@ -0,0 +1,44 @@
+package vegacmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pelletier/go-toml"
+)
+
+func TestTemplateVisorRunConfig(t *testing.T) {
+	tests := []struct {
+		name         string
+		dataNodeHome string
+		withDataNode bool
+	}{
+		{name: "vega only", dataNodeHome: ""},
+		{name: "with data-node", dataNodeHome: "/home/vega/data-node", withDataNode: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, err := TemplateVisorRunConfig("v0.73.4", "/home/vega/vega", "/home/vega/tendermint", tt.dataNodeHome)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			tree, err := toml.Load(content)
+			if err != nil {
+				t.Fatalf("invalid run-config.toml:\n%s\nerror: %s", content, err)
+			}
+
+			if name := tree.Get("name"); name != "v0.73.4" {
+				t.Errorf("expected name v0.73.4, got %v", name)
+			}
+			vegaArgs := tree.GetArray("vega.binary.args").([]string)
+			if strings.Join(vegaArgs, " ") != "start --home /home/vega/vega --tendermint-home /home/vega/tendermint" {
+				t.Errorf("unexpected vega args: %v", vegaArgs)
+			}
+			if got := tree.Has("data_node"); got != tt.withDataNode {
+				t.Errorf("expected data_node section: %t, got %t", tt.withDataNode, got)
+			}
+		})
+	}
+}